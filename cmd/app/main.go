@@ -3,18 +3,27 @@ package main
 import (
 	_ "awesomeProject1/docs"
 	"awesomeProject1/internal/server"
+	"awesomeProject1/pkg/configLoader"
+	"context"
+	"errors"
 	"flag"
-	"gopkg.in/yaml.v3"
+	"io/fs"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 )
 
+const defaultConfigPath = "configs/server.yaml"
+
 var (
-	configPath string
+	configPath  string
+	printConfig bool
 )
 
 func init() {
-	flag.StringVar(&configPath, "config-path", "configs/server.yaml", "Path to API server config")
+	flag.StringVar(&configPath, "config-path", defaultConfigPath, "Path to API server config (.yaml, .yml or .toml)")
+	flag.BoolVar(&printConfig, "print-config", false, "Print the effective config with secrets redacted and exit")
 }
 
 // @title           Subscription API
@@ -29,27 +38,59 @@ func init() {
 // @license.url   https://opensource.org/licenses/MIT
 
 // @host      localhost:8080
-// @BasePath  /api/v1
+// @BasePath  /
+
+// @securityDefinitions.apikey  ApiKeyAuth
+// @in                          header
+// @name                        X-API-Key
+// @description                 Ключ API, выпускается через POST /admin/api-keys или subctl api-key create
+
+// @securityDefinitions.apikey  BearerAuth
+// @in                          header
+// @name                        Authorization
+// @description                 JWT в формате "Bearer <token>", подписанный HS256 секретом или ключом из JWKS
 func main() {
 	flag.Parse()
-	config := server.NewConfig()
-
-	data, err := os.ReadFile(configPath)
+	// Без файла по пути по умолчанию сервис запускается на значениях по умолчанию и переменных окружения,
+	// явно указанный файл обязателен
+	source := server.ConfigSource{Path: configPath, Optional: configPath == defaultConfigPath}
+	if _, err := os.Stat(configPath); source.Optional && errors.Is(err, fs.ErrNotExist) {
+		println("Config file " + configPath + " not found, using defaults and environment")
+	}
+	config, warnings, err := source.Load()
+	for _, warning := range warnings {
+		println("Config warning: " + warning)
+	}
 	if err != nil {
-		println("Cannot read config file, using default values")
-	} else {
-		if err := yaml.Unmarshal(data, &config); err != nil {
-			println("Cannot parse YAML config, using default values")
+		log.Fatal(err)
+	}
+
+	// Конфигурация печатается и с ошибками, чтобы было видно, какие значения получились после слияния
+	if printConfig {
+		if err := configLoader.Print(os.Stdout, config); err != nil {
+			log.Fatal(err)
 		}
 	}
+	if err := config.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	if printConfig {
+		return
+	}
 
-	println(config.Storage.DbConnString)
 	if err := config.Storage.RunMigrations(); err != nil {
 		log.Fatalf("Migration error: %v", err.Error())
 	}
 
-	api := server.New(config)
+	api := server.New(config, source)
+
+	// SIGTERM приходит при остановке контейнера, SIGINT — по Ctrl+C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	println("Server starting")
-	log.Fatal(api.Start())
+	if err := api.Run(ctx); err != nil {
+		log.Fatal(err)
+	}
+	println("Server stopped")
 }
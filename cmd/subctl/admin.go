@@ -0,0 +1,436 @@
+package main
+
+import (
+	"awesomeProject1/internal/auth"
+	"awesomeProject1/internal/dto"
+	"awesomeProject1/internal/server"
+	"awesomeProject1/internal/service"
+	"awesomeProject1/internal/store"
+	"awesomeProject1/internal/tenant"
+	"awesomeProject1/pkg/configLoader"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// adminContext — commandContext для команд, которые работают с базой напрямую в обход API.
+// Оператор с доступом к базе видит все подписки, поэтому команды выполняются от имени auth.System
+// в тенанте из --tenant, без него — в тенанте по умолчанию
+func (g *globals) adminContext() (context.Context, context.CancelFunc, error) {
+	id := tenant.Default
+	if g.tenant != "" {
+		parsed, err := uuid.Parse(g.tenant)
+		if err != nil {
+			return nil, nil, fmt.Errorf("--tenant: expected uuid, got %q", g.tenant)
+		}
+		id = parsed
+	}
+
+	ctx, cancel := commandContext()
+	return tenant.WithID(auth.WithPrincipal(ctx, auth.System), id), cancel, nil
+}
+
+// platformContext — adminContext без ограничения тенантом: для служебных записей всех тенантов и ключей платформы
+func platformContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := commandContext()
+	return tenant.WithAll(auth.WithPrincipal(ctx, auth.System)), cancel
+}
+
+var seedServices = []string{"Yandex Plus", "Kinopoisk", "Netflix", "Spotify", "Okko", "IVI", "VK Music", "Telegram Premium"}
+
+// loadConfig читает конфигурацию так же, как сервер: файл и переменные APP_*
+func (g *globals) loadConfig() (*server.Config, error) {
+	config := server.NewConfig()
+
+	warnings, err := configLoader.Load(g.configPath, config)
+	for _, warning := range warnings {
+		fmt.Fprintln(os.Stderr, "subctl: warning: "+warning)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// loadStorageConfig читает и проверяет секцию storage
+func (g *globals) loadStorageConfig() (*store.Config, error) {
+	config, err := g.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := configLoader.ValidationError(configLoader.Prefix("storage", config.Storage.Validate())); err != nil {
+		return nil, err
+	}
+
+	return config.Storage, nil
+}
+
+// openStore подключается к базе, закрывать через Stop
+func (g *globals) openStore() (*store.Store, error) {
+	config, err := g.loadStorageConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	st := store.New(config)
+	if err := st.Start(); err != nil {
+		return nil, fmt.Errorf("cannot connect to database: %w", err)
+	}
+
+	return st, nil
+}
+
+func runMigrate(g *globals, args []string) error {
+	config, err := g.loadStorageConfig()
+	if err != nil {
+		return err
+	}
+
+	action := "up"
+	if len(args) > 0 {
+		action = args[0]
+	}
+
+	switch action {
+	case "up":
+		if err := config.RunMigrations(); err != nil {
+			return err
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("migrate down: expected positive number of steps, got %q", args[1])
+			}
+		}
+		if err := config.RollbackMigrations(steps); err != nil {
+			return err
+		}
+	case "version":
+	default:
+		return fmt.Errorf("migrate: unknown action %q, expected up, down or version", action)
+	}
+
+	version, dirty, err := config.MigrationVersion()
+	if err != nil {
+		return err
+	}
+
+	return writeValue(os.Stdout, g.output, [][2]string{
+		{"version", strconv.FormatUint(uint64(version), 10)},
+		{"dirty", strconv.FormatBool(dirty)},
+	}, struct {
+		Version uint `json:"version"`
+		Dirty   bool `json:"dirty"`
+	}{version, dirty})
+}
+
+// runSeed создаёт тестовые подписки через сервис, поэтому события о них тоже попадают в outbox
+func runSeed(g *globals, args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	count := fs.Int("count", 100, "Number of subscriptions")
+	users := fs.Int("users", 10, "Number of distinct users")
+	seed := fs.Uint64("seed", 0, "Random seed, 0 — random")
+	_ = fs.Parse(args)
+
+	if *count < 1 || *users < 1 {
+		return errors.New("seed: --count and --users must be positive")
+	}
+
+	st, err := g.openStore()
+	if err != nil {
+		return err
+	}
+	defer st.Stop()
+
+	if *seed == 0 {
+		*seed = rand.Uint64()
+	}
+	rnd := rand.New(rand.NewPCG(*seed, *seed))
+
+	userIds := make([]uuid.UUID, *users)
+	for i := range userIds {
+		userIds[i] = uuid.Must(uuid.NewRandomFromReader(randReader{rnd}))
+	}
+
+	svc := service.NewSubscriptionService(st.SubscriptionRepository(), st.Transactor())
+
+	ctx, cancel, err := g.adminContext()
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	now := time.Now().UTC()
+	firstMonth := time.Date(now.Year()-2, now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < *count; i++ {
+		item := &dto.Subscription{
+			ServiceName: seedServices[rnd.IntN(len(seedServices))],
+			Price:       99 + rnd.IntN(20)*50,
+			UserID:      userIds[rnd.IntN(len(userIds))],
+			StartDate:   firstMonth.AddDate(0, rnd.IntN(24), 0),
+		}
+		if rnd.IntN(10) < 3 {
+			item.EndDate.Time = item.StartDate.AddDate(0, 1+rnd.IntN(12), 0)
+			item.EndDate.Valid = true
+		}
+
+		if _, sErr := svc.Create(ctx, item); sErr != nil {
+			return fmt.Errorf("seed: created %d of %d: %s", i, *count, sErr.Error())
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "created %d subscriptions for %d users (seed %d)\n", *count, *users, *seed)
+	return nil
+}
+
+func runPurge(g *globals, args []string) error {
+	if len(args) == 0 {
+		return errors.New("purge: expected subscriptions or housekeeping")
+	}
+
+	switch args[0] {
+	case "subscriptions":
+		return purgeSubscriptions(g, args[1:])
+	case "housekeeping":
+		return purgeHousekeeping(g, args[1:])
+	default:
+		return fmt.Errorf("purge: unknown target %q, expected subscriptions or housekeeping", args[0])
+	}
+}
+
+func purgeSubscriptions(g *globals, args []string) error {
+	fs := flag.NewFlagSet("purge subscriptions", flag.ExitOnError)
+	filter := &dto.SubscriptionFilter{}
+	fs.StringVar(&filter.UserId, "user-id", "", "Delete subscriptions of this user")
+	fs.StringVar(&filter.ServiceName, "service-name", "", "Delete subscriptions of this service")
+	endedBefore := fs.String("ended-before", "", "Delete subscriptions that ended before this month, MM-YYYY")
+	all := fs.Bool("all", false, "Allow purge without filters")
+	yes := fs.Bool("yes", false, "Confirm deletion")
+	_ = fs.Parse(args)
+
+	var endedBeforeDate *time.Time
+	if *endedBefore != "" {
+		parsed, err := dto.ParseMonthYear(*endedBefore)
+		if err != nil {
+			return fmt.Errorf("--ended-before: expected MM-YYYY, got %q", *endedBefore)
+		}
+		endedBeforeDate = &parsed
+	}
+
+	if filter.UserId == "" && filter.ServiceName == "" && endedBeforeDate == nil && !*all {
+		return errors.New("purge subscriptions: pass a filter or --all")
+	}
+	if !*yes {
+		return errors.New("purge subscriptions: deletion is irreversible, pass --yes to confirm")
+	}
+
+	st, err := g.openStore()
+	if err != nil {
+		return err
+	}
+	defer st.Stop()
+
+	ctx, cancel, err := g.adminContext()
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	svc := service.NewSubscriptionService(st.SubscriptionRepository(), st.Transactor())
+	count, sErr := svc.Purge(ctx, filter, endedBeforeDate)
+	if sErr != nil {
+		if len(sErr.Errors) > 0 {
+			return validationError(sErr.Errors)
+		}
+		return sErr
+	}
+
+	fmt.Fprintf(os.Stderr, "deleted %d subscriptions\n", count)
+	return nil
+}
+
+// purgeHousekeeping удаляет служебные записи всех тенантов: просроченные ключи идемпотентности, старые разосланные события
+// и вёдра лимитов запросов, к которым давно не обращались
+func purgeHousekeeping(g *globals, args []string) error {
+	fs := flag.NewFlagSet("purge housekeeping", flag.ExitOnError)
+	outboxAge := fs.Duration("outbox-older-than", 30*24*time.Hour, "Delete processed outbox events older than this")
+	bucketAge := fs.Duration("rate-limit-older-than", 24*time.Hour, "Delete rate limit buckets unused for this long")
+	_ = fs.Parse(args)
+
+	st, err := g.openStore()
+	if err != nil {
+		return err
+	}
+	defer st.Stop()
+
+	ctx, cancel := platformContext()
+	defer cancel()
+
+	keys, err := st.IdempotencyRepository().DeleteExpired(ctx)
+	if err != nil {
+		return fmt.Errorf("delete expired idempotency keys: %w", err)
+	}
+
+	events, err := st.OutboxRepository().DeleteProcessed(ctx, time.Now().Add(-*outboxAge))
+	if err != nil {
+		return fmt.Errorf("delete processed outbox events: %w", err)
+	}
+
+	buckets, err := st.RateLimitRepository().DeleteIdle(ctx, time.Now().Add(-*bucketAge))
+	if err != nil {
+		return fmt.Errorf("delete idle rate limit buckets: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "deleted %d expired idempotency keys, %d processed outbox events, %d idle rate limit buckets\n", keys, events, buckets)
+	return nil
+}
+
+// runAPIKey выпускает и отзывает ключи API в обход HTTP API, например чтобы выпустить первый ключ администратора.
+// Ключ выпускается для тенанта из --tenant, с --platform — ключ платформы, который управляет тенантами
+func runAPIKey(g *globals, args []string) error {
+	if len(args) == 0 {
+		return errors.New("api-key: expected create or revoke")
+	}
+
+	switch args[0] {
+	case "create":
+		return createAPIKey(g, args[1:])
+	case "revoke":
+		return revokeAPIKey(g, args[1:])
+	default:
+		return fmt.Errorf("api-key: unknown action %q, expected create or revoke", args[0])
+	}
+}
+
+func createAPIKey(g *globals, args []string) error {
+	fs := flag.NewFlagSet("api-key create", flag.ExitOnError)
+	req := &dto.CreateAPIKeyRequest{}
+	fs.StringVar(&req.Name, "name", "", "Key name, e.g. the service that uses it")
+	roles := fs.String("roles", "", "Comma-separated roles from the access policy, empty — default roles")
+	userId := fs.String("user-id", "", "User the key acts for, empty — service key")
+	expiresIn := fs.Duration("expires-in", 0, "Key lifetime, 0 — never expires")
+	platform := fs.Bool("platform", false, "Platform key not bound to a tenant, it selects the tenant with X-Tenant-ID")
+	_ = fs.Parse(args)
+
+	if *userId != "" {
+		req.UserID = userId
+	}
+	if *roles != "" {
+		req.Roles = strings.Split(*roles, ",")
+	}
+	if *expiresIn > 0 {
+		expiresAt := time.Now().Add(*expiresIn)
+		req.ExpiresAt = &expiresAt
+	}
+	if ok, errors := req.IsValid(); !ok {
+		return validationError(errors)
+	}
+
+	st, err := g.openStore()
+	if err != nil {
+		return err
+	}
+	defer st.Stop()
+
+	ctx, cancel, err := g.adminContext()
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	req.Platform = *platform
+
+	svc, err := g.apiKeyService(st)
+	if err != nil {
+		return err
+	}
+
+	created, sErr := svc.Create(ctx, req)
+	if sErr != nil {
+		return sErr
+	}
+
+	keyTenant := "platform"
+	if created.TenantID != nil {
+		keyTenant = created.TenantID.String()
+	}
+
+	fmt.Fprintln(os.Stderr, "the key is shown only once, store it now")
+	return writeValue(os.Stdout, g.output, [][2]string{
+		{"id", created.ID.String()},
+		{"name", created.Name},
+		{"tenant", keyTenant},
+		{"roles", strings.Join(created.Roles, ",")},
+		{"key", created.Key},
+	}, created)
+}
+
+// revokeAPIKey отзывает ключ любого тенанта: id ключа и так однозначен
+func revokeAPIKey(g *globals, args []string) error {
+	if len(args) != 1 {
+		return errors.New("api-key revoke: expected key id")
+	}
+
+	id, err := uuid.Parse(args[0])
+	if err != nil {
+		return fmt.Errorf("api-key revoke: expected uuid, got %q", args[0])
+	}
+
+	st, err := g.openStore()
+	if err != nil {
+		return err
+	}
+	defer st.Stop()
+
+	ctx, cancel := platformContext()
+	defer cancel()
+
+	svc, err := g.apiKeyService(st)
+	if err != nil {
+		return err
+	}
+
+	if sErr := svc.Revoke(ctx, id); sErr != nil {
+		return sErr
+	}
+
+	fmt.Fprintf(os.Stderr, "revoked api key %s\n", id)
+	return nil
+}
+
+// randReader — источник байт для uuid из генератора с заданным seed
+type randReader struct {
+	rnd *rand.Rand
+}
+
+func (r randReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = byte(r.rnd.Uint32())
+	}
+	return len(p), nil
+}
+
+// apiKeyService — сервис ключей с политикой из конфигурации, по ней проверяются роли новых ключей
+func (g *globals) apiKeyService(st *store.Store) (*service.APIKeyService, error) {
+	config, err := g.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := auth.LoadPolicy(config.Auth.PolicyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.NewAPIKeyService(st.APIKeyRepository(), policy, config.Auth.KeyTouchInterval), nil
+}
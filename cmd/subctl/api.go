@@ -0,0 +1,302 @@
+package main
+
+import (
+	"awesomeProject1/pkg/client"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const listPageSize = 100
+
+func runList(g *globals, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	filter := filterFlags(fs)
+	offset := fs.Int("offset", 0, "Skip first N subscriptions, used with --limit")
+	limit := fs.Int("limit", 0, "Max subscriptions to print, 0 — all")
+	_ = fs.Parse(args)
+
+	c, err := g.newClient()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := commandContext()
+	defer cancel()
+
+	if *limit > 0 {
+		page, err := c.Subscriptions.List(ctx, filter, *offset, *limit)
+		if err != nil {
+			return err
+		}
+		return writeSubscriptions(os.Stdout, g.output, page.Subscriptions)
+	}
+
+	return writeSubscriptionStream(os.Stdout, g.output, allSubscriptions(ctx, c, filter))
+}
+
+func runGet(g *globals, args []string) error {
+	fs := flag.NewFlagSet("get", flag.ExitOnError)
+	id, err := parseIdArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	c, err := g.newClient()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := commandContext()
+	defer cancel()
+
+	item, err := c.Subscriptions.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return writeSubscriptions(os.Stdout, g.output, []*client.Subscription{item})
+}
+
+func runCreate(g *globals, args []string) error {
+	fs := flag.NewFlagSet("create", flag.ExitOnError)
+	req := &client.CreateSubscriptionRequest{}
+	fs.StringVar(&req.ServiceName, "service-name", "", "Service name (required)")
+	fs.IntVar(&req.Price, "price", 0, "Monthly price in rubles")
+	fs.StringVar(&req.UserID, "user-id", "", "User id, UUID (required)")
+	fs.StringVar(&req.StartDate, "start", "", "Start month, MM-YYYY (required)")
+	fs.StringVar(&req.EndDate, "end", "", "End month, MM-YYYY")
+	_ = fs.Parse(args)
+
+	if ok, errors := req.IsValid(); !ok {
+		return validationError(errors)
+	}
+
+	c, err := g.newClient()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := commandContext()
+	defer cancel()
+
+	item, err := c.Subscriptions.Create(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return writeSubscriptions(os.Stdout, g.output, []*client.Subscription{item})
+}
+
+// runUpdate меняет только переданные флагами поля через JSON Merge Patch.
+// --end "" снимает дату окончания
+func runUpdate(g *globals, args []string) error {
+	fs := flag.NewFlagSet("update", flag.ExitOnError)
+	fs.String("service-name", "", "New service name")
+	fs.Int("price", 0, "New monthly price")
+	fs.String("user-id", "", "New user id")
+	fs.String("start", "", "New start month, MM-YYYY")
+	fs.String("end", "", "New end month, MM-YYYY; empty string removes it")
+	ifMatch := fs.String("if-match", "", "Apply only if subscription ETag matches")
+
+	id, err := parseIdArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	fields := map[string]string{
+		"service-name": "service_name",
+		"price":        "price",
+		"user-id":      "user_id",
+		"start":        "start_date",
+		"end":          "end_date",
+	}
+
+	patch := map[string]any{}
+	fs.Visit(func(f *flag.Flag) {
+		field, ok := fields[f.Name]
+		if !ok {
+			return
+		}
+		if f.Name == "price" {
+			price, _ := strconv.Atoi(f.Value.String())
+			patch[field] = price
+			return
+		}
+		if f.Name == "end" && f.Value.String() == "" {
+			patch[field] = nil
+			return
+		}
+		patch[field] = f.Value.String()
+	})
+
+	if len(patch) == 0 {
+		return errors.New("nothing to update, pass at least one field flag")
+	}
+
+	c, err := g.newClient()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := commandContext()
+	defer cancel()
+
+	var opts []client.RequestOption
+	if *ifMatch != "" {
+		opts = append(opts, client.WithIfMatch(*ifMatch))
+	}
+
+	item, err := c.Subscriptions.MergePatch(ctx, id, patch, opts...)
+	if err != nil {
+		return err
+	}
+
+	return writeSubscriptions(os.Stdout, g.output, []*client.Subscription{item})
+}
+
+func runDelete(g *globals, args []string) error {
+	fs := flag.NewFlagSet("delete", flag.ExitOnError)
+	ifMatch := fs.String("if-match", "", "Delete only if subscription ETag matches")
+
+	id, err := parseIdArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	c, err := g.newClient()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := commandContext()
+	defer cancel()
+
+	var opts []client.RequestOption
+	if *ifMatch != "" {
+		opts = append(opts, client.WithIfMatch(*ifMatch))
+	}
+
+	if err := c.Subscriptions.Delete(ctx, id, opts...); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(os.Stderr, "deleted "+id.String())
+	return nil
+}
+
+func runTotal(g *globals, args []string) error {
+	fs := flag.NewFlagSet("total", flag.ExitOnError)
+	start := fs.String("start", "", "Period start, MM-YYYY (required)")
+	end := fs.String("end", "", "Period end, MM-YYYY (required)")
+	filter := filterFlags(fs)
+	_ = fs.Parse(args)
+
+	startDate, err := time.Parse("01-2006", *start)
+	if err != nil {
+		return fmt.Errorf("--start: expected MM-YYYY, got %q", *start)
+	}
+	endDate, err := time.Parse("01-2006", *end)
+	if err != nil {
+		return fmt.Errorf("--end: expected MM-YYYY, got %q", *end)
+	}
+
+	c, err := g.newClient()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := commandContext()
+	defer cancel()
+
+	total, err := c.Subscriptions.Total(ctx, client.TotalQuery{
+		Start:       startDate,
+		End:         endDate,
+		UserID:      filter.UserId,
+		ServiceName: filter.ServiceName,
+	})
+	if err != nil {
+		return err
+	}
+
+	result := struct {
+		Start       string `json:"start"`
+		End         string `json:"end"`
+		UserID      string `json:"user_id,omitempty"`
+		ServiceName string `json:"service_name,omitempty"`
+		Total       int    `json:"total"`
+	}{*start, *end, filter.UserId, filter.ServiceName, total}
+
+	return writeValue(os.Stdout, g.output, [][2]string{
+		{"start", result.Start},
+		{"end", result.End},
+		{"user_id", result.UserID},
+		{"service_name", result.ServiceName},
+		{"total", strconv.Itoa(total)},
+	}, result)
+}
+
+func filterFlags(fs *flag.FlagSet) *client.SubscriptionFilter {
+	filter := &client.SubscriptionFilter{}
+	fs.StringVar(&filter.UserId, "user-id", "", "Filter by user id")
+	fs.StringVar(&filter.ServiceName, "service-name", "", "Filter by service name")
+	return filter
+}
+
+// parseIdArgs разбирает "<id> [flags]" и "[flags] <id>"
+func parseIdArgs(fs *flag.FlagSet, args []string) (uuid.UUID, error) {
+	var raw string
+	if len(args) > 0 && len(args[0]) > 0 && args[0][0] != '-' {
+		raw, args = args[0], args[1:]
+	}
+	_ = fs.Parse(args)
+
+	if raw == "" {
+		raw = fs.Arg(0)
+	}
+	if raw == "" {
+		return uuid.Nil, fmt.Errorf("%s: subscription id is required", fs.Name())
+	}
+
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%s: invalid subscription id %q", fs.Name(), raw)
+	}
+
+	return id, nil
+}
+
+// allSubscriptions обходит все подписки постранично через итератор клиента
+func allSubscriptions(ctx context.Context, c *client.Client, filter *client.SubscriptionFilter) func(yield func(*client.Subscription) error) error {
+	return func(yield func(*client.Subscription) error) error {
+		for item, err := range c.Subscriptions.All(ctx, filter, listPageSize) {
+			if err != nil {
+				return err
+			}
+			if err := yield(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// commandContext отменяется по Ctrl+C
+func commandContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt)
+}
+
+func validationError(errors []client.FieldError) error {
+	msg := "invalid arguments:"
+	for _, err := range errors {
+		msg += "\n  " + err.Field + ": " + err.Message
+	}
+	return fmt.Errorf("%s", msg)
+}
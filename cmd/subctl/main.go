@@ -0,0 +1,135 @@
+// subctl — утилита оператора: работа с подписками через HTTP API
+// и административные задачи (миграции, тестовые данные, очистка) напрямую в базе.
+package main
+
+import (
+	"awesomeProject1/pkg/client"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+const usage = `Usage: subctl [global flags] <command> [flags] [args]
+
+API commands (через HTTP):
+  list      список подписок
+  get       подписка по id
+  create    создать подписку
+  update    изменить поля подписки
+  delete    удалить подписку
+  total     суммарная стоимость за период
+  export    выгрузить подписки в JSON или CSV
+  import    загрузить подписки из JSON или CSV
+
+Admin commands (напрямую в базу, настройки storage из --config-path):
+  migrate   up | down [N] | version
+  seed      создать тестовые подписки
+  purge     subscriptions | housekeeping
+  api-key   create | revoke <id>
+
+Global flags:
+`
+
+// globals — общие флаги всех команд
+type globals struct {
+	apiURL     string
+	token      string
+	apiKey     string
+	output     string
+	configPath string
+	tenant     string
+}
+
+type command func(g *globals, args []string) error
+
+var commands = map[string]command{
+	"list":    runList,
+	"get":     runGet,
+	"create":  runCreate,
+	"update":  runUpdate,
+	"delete":  runDelete,
+	"total":   runTotal,
+	"export":  runExport,
+	"import":  runImport,
+	"migrate": runMigrate,
+	"seed":    runSeed,
+	"purge":   runPurge,
+	"api-key": runAPIKey,
+}
+
+func main() {
+	g := &globals{}
+
+	fs := flag.NewFlagSet("subctl", flag.ExitOnError)
+	fs.StringVar(&g.apiURL, "api-url", envOr("SUBCTL_API_URL", "http://localhost:8080/api/v1"), "API base url (env SUBCTL_API_URL)")
+	fs.StringVar(&g.token, "token", os.Getenv("SUBCTL_TOKEN"), "Bearer token (env SUBCTL_TOKEN)")
+	fs.StringVar(&g.apiKey, "api-key", os.Getenv("SUBCTL_API_KEY"), "API key sent in X-API-Key (env SUBCTL_API_KEY)")
+	fs.StringVar(&g.tenant, "tenant", os.Getenv("SUBCTL_TENANT"), "Tenant id sent in X-Tenant-ID and used by admin commands, empty — default tenant (env SUBCTL_TENANT)")
+	fs.StringVar(&g.output, "o", formatTable, "Output format: table, json, csv")
+	fs.StringVar(&g.configPath, "config-path", "configs/server.yaml", "Path to API server config for admin commands")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(os.Args[1:])
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	name := fs.Arg(0)
+	run, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "subctl: unknown command %q\n\n", name)
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if !isKnownFormat(g.output) {
+		fmt.Fprintf(os.Stderr, "subctl: unknown output format %q\n", g.output)
+		os.Exit(2)
+	}
+
+	if err := run(g, fs.Args()[1:]); err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+}
+
+// newClient создаёт клиент API из глобальных флагов
+func (g *globals) newClient() (*client.Client, error) {
+	opts := []client.Option{client.WithUserAgent("subctl")}
+	if g.tenant != "" {
+		opts = append(opts, client.WithTenant(g.tenant))
+	}
+	switch {
+	case g.token != "":
+		opts = append(opts, client.WithAuth(client.BearerToken(g.token)))
+	case g.apiKey != "":
+		opts = append(opts, client.WithAuth(client.APIKey("X-API-Key", g.apiKey)))
+	}
+	return client.New(g.apiURL, opts...)
+}
+
+func printError(err error) {
+	fmt.Fprintln(os.Stderr, "subctl: "+err.Error())
+
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) {
+		for _, fieldErr := range apiErr.FieldErrors() {
+			fmt.Fprintf(os.Stderr, "  %s: %s\n", fieldErr.Field, fieldErr.Message)
+		}
+		if id := apiErr.RequestID(); id != "" {
+			fmt.Fprintln(os.Stderr, "  error id: "+id)
+		}
+	}
+}
+
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"awesomeProject1/pkg/client"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+)
+
+// subscriptionColumns — колонки таблицы и CSV. Import читает CSV по этим же заголовкам
+var subscriptionColumns = []string{"id", "service_name", "price", "user_id", "start_date", "end_date", "version", "updated_at"}
+
+func isKnownFormat(format string) bool {
+	return format == formatTable || format == formatJSON || format == formatCSV
+}
+
+func subscriptionRow(s *client.Subscription) []string {
+	endDate := ""
+	if s.EndDate != nil {
+		endDate = *s.EndDate
+	}
+
+	return []string{
+		s.ID.String(),
+		s.ServiceName,
+		strconv.Itoa(s.Price),
+		s.UserID.String(),
+		s.StartDate,
+		endDate,
+		strconv.Itoa(s.Version),
+		s.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func writeSubscriptions(w io.Writer, format string, items []*client.Subscription) error {
+	return writeSubscriptionStream(w, format, func(yield func(*client.Subscription) error) error {
+		for _, item := range items {
+			if err := yield(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// writeSubscriptionStream печатает подписки по мере получения, не собирая их в память.
+// Используется для list без лимита и export
+func writeSubscriptionStream(w io.Writer, format string, items func(yield func(*client.Subscription) error) error) error {
+	switch format {
+	case formatJSON:
+		// Массив JSON пишется по элементам, чтобы большая выгрузка не держала всё в памяти
+		if _, err := io.WriteString(w, "["); err != nil {
+			return err
+		}
+		first := true
+		err := items(func(item *client.Subscription) error {
+			data, err := json.Marshal(item)
+			if err != nil {
+				return err
+			}
+			if !first {
+				if _, err := io.WriteString(w, ","); err != nil {
+					return err
+				}
+			}
+			first = false
+			_, err = fmt.Fprintf(w, "\n  %s", data)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if !first {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		_, err = io.WriteString(w, "]\n")
+		return err
+	case formatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(subscriptionColumns); err != nil {
+			return err
+		}
+		err := items(func(item *client.Subscription) error {
+			return cw.Write(subscriptionRow(item))
+		})
+		if err != nil {
+			return err
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		writeTableRow(tw, upper(subscriptionColumns))
+		if err := items(func(item *client.Subscription) error {
+			writeTableRow(tw, subscriptionRow(item))
+			return nil
+		}); err != nil {
+			return err
+		}
+		return tw.Flush()
+	}
+}
+
+// writeValue печатает одно значение: таблица и CSV — в виде пар ключ/значение
+func writeValue(w io.Writer, format string, fields [][2]string, value any) error {
+	switch format {
+	case formatJSON:
+		return writeJSON(w, value)
+	case formatCSV:
+		cw := csv.NewWriter(w)
+		header := make([]string, len(fields))
+		row := make([]string, len(fields))
+		for i, field := range fields {
+			header[i], row[i] = field[0], field[1]
+		}
+		_ = cw.Write(header)
+		_ = cw.Write(row)
+		cw.Flush()
+		return cw.Error()
+	default:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, field := range fields {
+			writeTableRow(tw, []string{field[0] + ":", field[1]})
+		}
+		return tw.Flush()
+	}
+}
+
+func writeJSON(w io.Writer, value any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+func writeTableRow(w io.Writer, row []string) {
+	for i, cell := range row {
+		if i > 0 {
+			fmt.Fprint(w, "\t")
+		}
+		fmt.Fprint(w, cell)
+	}
+	fmt.Fprintln(w)
+}
+
+func upper(columns []string) []string {
+	result := make([]string, len(columns))
+	for i, column := range columns {
+		result[i] = strings.ToUpper(column)
+	}
+	return result
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"awesomeProject1/pkg/client"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+func runExport(g *globals, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	file := fs.String("file", "-", "Output file, - for stdout")
+	format := fs.String("format", "", "json or csv, by default taken from file extension or -o")
+	filter := filterFlags(fs)
+	_ = fs.Parse(args)
+
+	f, err := transferFormat(*format, *file, g.output)
+	if err != nil {
+		return err
+	}
+
+	c, err := g.newClient()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := commandContext()
+	defer cancel()
+
+	var w io.Writer = os.Stdout
+	if *file != "-" {
+		out, err := os.Create(*file)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+		w = out
+	}
+
+	count := 0
+	items := allSubscriptions(ctx, c, filter)
+	err = writeSubscriptionStream(w, f, func(yield func(*client.Subscription) error) error {
+		return items(func(item *client.Subscription) error {
+			count++
+			return yield(item)
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "exported %d subscriptions\n", count)
+	return nil
+}
+
+// runImport создаёт подписки из файла. Формат совпадает с export: лишние поля (id, version) игнорируются.
+// Ключ идемпотентности строится из номера строки и её содержимого, поэтому повторный запуск
+// после сбоя не создаёт дубли уже загруженных строк, пока ключи не истекли на сервере
+func runImport(g *globals, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	file := fs.String("file", "-", "Input file, - for stdin")
+	format := fs.String("format", "", "json or csv, by default taken from file extension")
+	dryRun := fs.Bool("dry-run", false, "Only validate the file")
+	_ = fs.Parse(args)
+
+	f, err := transferFormat(*format, *file, formatJSON)
+	if err != nil {
+		return err
+	}
+
+	var r io.Reader = os.Stdin
+	if *file != "-" {
+		in, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		r = in
+	}
+
+	var requests []*client.CreateSubscriptionRequest
+	if f == formatCSV {
+		requests, err = readCSV(r)
+	} else {
+		err = json.NewDecoder(r).Decode(&requests)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot read %s: %w", *file, err)
+	}
+
+	// Сначала проверяем весь файл, чтобы не загрузить его наполовину
+	var problems []string
+	for i, req := range requests {
+		if ok, errors := req.IsValid(); !ok {
+			for _, fieldErr := range errors {
+				problems = append(problems, fmt.Sprintf("record %d: %s: %s", i+1, fieldErr.Field, fieldErr.Message))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return errors.New("invalid records:\n  " + strings.Join(problems, "\n  "))
+	}
+
+	if *dryRun {
+		fmt.Fprintf(os.Stderr, "%d records are valid\n", len(requests))
+		return nil
+	}
+
+	c, err := g.newClient()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := commandContext()
+	defer cancel()
+
+	for i, req := range requests {
+		if _, err := c.Subscriptions.Create(ctx, req, client.WithIdempotencyKey(importKey(i, req))); err != nil {
+			return fmt.Errorf("record %d: %w (imported %d of %d)", i+1, err, i, len(requests))
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "imported %d subscriptions\n", len(requests))
+	return nil
+}
+
+func readCSV(r io.Reader) ([]*client.CreateSubscriptionRequest, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(strings.ToLower(name))] = i
+	}
+	for _, required := range []string{"service_name", "price", "user_id", "start_date"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("missing column %q", required)
+		}
+	}
+
+	var requests []*client.CreateSubscriptionRequest
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return requests, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		get := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		price, err := strconv.Atoi(get("price"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid price %q", line, get("price"))
+		}
+
+		requests = append(requests, &client.CreateSubscriptionRequest{
+			ServiceName: get("service_name"),
+			Price:       price,
+			UserID:      get("user_id"),
+			StartDate:   get("start_date"),
+			EndDate:     get("end_date"),
+		})
+	}
+}
+
+func importKey(index int, req *client.CreateSubscriptionRequest) string {
+	data, _ := json.Marshal(req)
+	sum := sha256.Sum256(append([]byte(strconv.Itoa(index)+":"), data...))
+	return "subctl-import-" + hex.EncodeToString(sum[:16])
+}
+
+// transferFormat выбирает формат файла: явный флаг, расширение файла, затем fallback
+func transferFormat(format, file, fallback string) (string, error) {
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(file)) {
+		case ".csv":
+			format = formatCSV
+		case ".json":
+			format = formatJSON
+		default:
+			format = fallback
+		}
+	}
+
+	switch format {
+	case formatJSON, formatCSV:
+		return format, nil
+	case formatTable:
+		return formatJSON, nil
+	default:
+		return "", fmt.Errorf("unknown format %q, expected json or csv", format)
+	}
+}
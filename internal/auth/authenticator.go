@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+const (
+	APIKeyHeader        = "X-API-Key"
+	AuthorizationHeader = "Authorization"
+
+	bearerPrefix = "Bearer "
+)
+
+// Сообщения клиенту, одинаковые для HTTP и gRPC
+const (
+	ErrorUnauthenticated    = "Authentication required, pass an API key in X-API-Key or a token in Authorization: Bearer"
+	ErrorInvalidCredentials = "Provided credentials are invalid, expired or revoked"
+)
+
+var (
+	// ErrNoCredentials — в запросе нет данных для этого способа, пробуется следующий
+	ErrNoCredentials = errors.New("no credentials")
+	// ErrInvalidCredentials — данные есть, но не подошли: ключ отозван, токен просрочен и т.п.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Authenticator — способ аутентификации. Заголовки передаются отдельно от запроса,
+// чтобы тот же способ работал и для метаданных gRPC
+type Authenticator interface {
+	Authenticate(ctx context.Context, header http.Header) (*Principal, error)
+	// Challenge — значение WWW-Authenticate, которое подсказывает клиенту способ
+	Challenge() string
+}
+
+// Chain пробует способы по очереди, пока один не найдёт данные в запросе
+type Chain []Authenticator
+
+func (c Chain) Authenticate(ctx context.Context, header http.Header) (*Principal, error) {
+	for _, authenticator := range c {
+		principal, err := authenticator.Authenticate(ctx, header)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		return principal, err
+	}
+	return nil, ErrNoCredentials
+}
+
+// Challenges — значения WWW-Authenticate всех способов
+func (c Chain) Challenges() []string {
+	var challenges []string
+	for _, authenticator := range c {
+		if challenge := authenticator.Challenge(); challenge != "" {
+			challenges = append(challenges, challenge)
+		}
+	}
+	return challenges
+}
+
+type disabledAuthenticator struct{}
+
+// Disabled — способ для выключенной аутентификации: любой запрос выполняется от Anonymous
+func Disabled() Authenticator {
+	return disabledAuthenticator{}
+}
+
+func (disabledAuthenticator) Authenticate(context.Context, http.Header) (*Principal, error) {
+	return Anonymous, nil
+}
+
+func (disabledAuthenticator) Challenge() string {
+	return ""
+}
+
+// KeyVerifier проверяет ключ API и возвращает его владельца.
+// Неподходящий ключ — ошибка, обёрнутая в ErrInvalidCredentials
+type KeyVerifier func(ctx context.Context, key string) (*Principal, error)
+
+type apiKeyAuthenticator struct {
+	verify KeyVerifier
+}
+
+// NewAPIKeyAuthenticator читает ключ из X-API-Key
+func NewAPIKeyAuthenticator(verify KeyVerifier) Authenticator {
+	return &apiKeyAuthenticator{verify: verify}
+}
+
+func (a *apiKeyAuthenticator) Authenticate(ctx context.Context, header http.Header) (*Principal, error) {
+	key := strings.TrimSpace(header.Get(APIKeyHeader))
+	if key == "" {
+		return nil, ErrNoCredentials
+	}
+	return a.verify(ctx, key)
+}
+
+func (a *apiKeyAuthenticator) Challenge() string {
+	return `ApiKey header="` + APIKeyHeader + `"`
+}
+
+// bearerToken достаёт токен из Authorization: Bearer
+func bearerToken(header http.Header) (string, bool) {
+	value := header.Get(AuthorizationHeader)
+	if len(value) < len(bearerPrefix) || !strings.EqualFold(value[:len(bearerPrefix)], bearerPrefix) {
+		return "", false
+	}
+
+	token := strings.TrimSpace(value[len(bearerPrefix):])
+	return token, token != ""
+}
@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"awesomeProject1/pkg/configLoader"
+	"awesomeProject1/pkg/validator"
+	"time"
+)
+
+const minHS256SecretLength = 32
+
+type Config struct {
+	// Enabled — false пропускает все запросы как раньше, только для локального запуска
+	Enabled bool       `yaml:"enabled"`
+	JWT     *JWTConfig `yaml:"jwt"`
+	// KeyTouchInterval — как часто обновлять last_used_at ключа API
+	KeyTouchInterval time.Duration `yaml:"key_touch_interval"`
+	// PolicyFile — роли и разрешения, пустой путь — встроенная политика, как в configs/policy.yaml
+	PolicyFile string `yaml:"policy_file"`
+}
+
+// JWTConfig — проверка токенов в Authorization: Bearer. Без hs256_secret и jwks_file токены не принимаются
+type JWTConfig struct {
+	// HS256Secret — общий секрет для токенов HS256
+	HS256Secret string `yaml:"hs256_secret" secret:"true"`
+	// JWKSFile — путь к JWKS с открытыми ключами RS256 и ES256, ключ выбирается по kid
+	JWKSFile string `yaml:"jwks_file"`
+	// Issuer и Audience проверяются, только если заданы
+	Issuer   string `yaml:"issuer"`
+	Audience string `yaml:"audience"`
+	// Leeway — допустимое расхождение часов при проверке exp и nbf
+	Leeway time.Duration `yaml:"leeway"`
+	// AdminScope — значение в claim scope, дающее роль admin
+	AdminScope string `yaml:"admin_scope"`
+}
+
+func NewConfig() *Config {
+	return &Config{
+		Enabled:          true,
+		JWT:              NewJWTConfig(),
+		KeyTouchInterval: time.Minute,
+	}
+}
+
+func NewJWTConfig() *JWTConfig {
+	return &JWTConfig{
+		Leeway:     30 * time.Second,
+		AdminScope: "admin",
+	}
+}
+
+// Configured сообщает, задан ли хотя бы один источник ключей
+func (c *JWTConfig) Configured() bool {
+	return c.HS256Secret != "" || c.JWKSFile != ""
+}
+
+// Validate проверяет значения, имена полей — ключи секции auth
+func (c *Config) Validate() []validator.FieldError {
+	v := validator.New()
+	if c.KeyTouchInterval <= 0 {
+		v.AddError("key_touch_interval", validator.CodeTooSmall, "must be a positive duration")
+	}
+
+	return append(v.GetErrors(), configLoader.Prefix("jwt", c.JWT.Validate())...)
+}
+
+// Validate проверяет значения, имена полей — ключи секции auth.jwt
+func (c *JWTConfig) Validate() []validator.FieldError {
+	v := validator.New()
+	if c.HS256Secret != "" && len(c.HS256Secret) < minHS256SecretLength {
+		v.AddError("hs256_secret", validator.CodeTooShort, "must be at least 32 characters")
+	}
+	if c.Leeway < 0 {
+		v.AddError("leeway", validator.CodeTooSmall, "must not be negative")
+	}
+	if c.AdminScope == "" {
+		v.AddError("admin_scope", validator.CodeRequired, "is required")
+	}
+	return v.GetErrors()
+}
@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// claims — поля токена, которые нужны сервису. user_id задаётся, если sub не UUID пользователя,
+// без tenant_id токен выдан для платформы, а не для одного тенанта
+type claims struct {
+	jwt.RegisteredClaims
+	Scope    string   `json:"scope"`
+	UserID   string   `json:"user_id"`
+	TenantID string   `json:"tenant_id"`
+	Roles    []string `json:"roles"`
+}
+
+type jwtAuthenticator struct {
+	config *JWTConfig
+	keys   map[string]*publicKey
+	parser *jwt.Parser
+}
+
+// NewJWTAuthenticator проверяет токены из Authorization: Bearer. HS256 принимается только при заданном
+// hs256_secret, RS256 и ES256 — только при заданном jwks_file, чтобы токен нельзя было подписать
+// открытым ключом как секретом
+func NewJWTAuthenticator(config *JWTConfig) (Authenticator, error) {
+	if !config.Configured() {
+		return nil, errors.New("auth: jwt requires hs256_secret or jwks_file")
+	}
+
+	a := &jwtAuthenticator{config: config}
+
+	var methods []string
+	if config.HS256Secret != "" {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if config.JWKSFile != "" {
+		keys, err := loadJWKS(config.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		a.keys = keys
+		methods = append(methods, jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg())
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithLeeway(config.Leeway),
+		jwt.WithExpirationRequired(),
+	}
+	if config.Issuer != "" {
+		options = append(options, jwt.WithIssuer(config.Issuer))
+	}
+	if config.Audience != "" {
+		options = append(options, jwt.WithAudience(config.Audience))
+	}
+	a.parser = jwt.NewParser(options...)
+
+	return a, nil
+}
+
+func (a *jwtAuthenticator) Authenticate(_ context.Context, header http.Header) (*Principal, error) {
+	token, ok := bearerToken(header)
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+
+	c := &claims{}
+	if _, err := a.parser.ParseWithClaims(token, c, a.key); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCredentials, err)
+	}
+
+	if c.Subject == "" {
+		return nil, fmt.Errorf("%w: token has no sub claim", ErrInvalidCredentials)
+	}
+
+	principal := &Principal{
+		Subject: c.Subject,
+		Roles:   c.Roles,
+		Method:  MethodJWT,
+	}
+	// admin_scope в scope остаётся способом выдать роль admin без claim roles
+	if slices.Contains(strings.Fields(c.Scope), a.config.AdminScope) && !slices.Contains(principal.Roles, RoleAdmin) {
+		principal.Roles = append(principal.Roles, RoleAdmin)
+	}
+
+	if c.UserID != "" {
+		userId, err := uuid.Parse(c.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: user_id claim is not a uuid", ErrInvalidCredentials)
+		}
+		principal.UserID = userId
+	} else if userId, err := uuid.Parse(c.Subject); err == nil {
+		principal.UserID = userId
+	}
+
+	if c.TenantID != "" {
+		tenantId, err := uuid.Parse(c.TenantID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: tenant_id claim is not a uuid", ErrInvalidCredentials)
+		}
+		principal.TenantID = &tenantId
+	}
+
+	return principal, nil
+}
+
+func (a *jwtAuthenticator) Challenge() string {
+	return `Bearer realm="subscriptions"`
+}
+
+// key выбирает ключ проверки подписи: секрет для HS256, иначе ключ из JWKS по kid
+func (a *jwtAuthenticator) key(token *jwt.Token) (any, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		return []byte(a.config.HS256Secret), nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := a.keys[kid]
+	// Токен без kid допустим, если в JWKS единственный ключ
+	if !ok && kid == "" && len(a.keys) == 1 {
+		for _, only := range a.keys {
+			key, ok = only, true
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+
+	if key.alg != "" && key.alg != token.Method.Alg() {
+		return nil, fmt.Errorf("key %q is for %s, token is signed with %s", kid, key.alg, token.Method.Alg())
+	}
+
+	return key.key, nil
+}
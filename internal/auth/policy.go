@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"awesomeProject1/pkg/configLoader"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Разрешения на действия в виде <ресурс>:<действие>, их проверяют маршруты и сервисы
+const (
+	PermSubscriptionRead  = "subscription:read"
+	PermSubscriptionWrite = "subscription:write"
+	// PermReportsRead — суммы по подпискам всех пользователей
+	PermReportsRead = "reports:read"
+	// PermAdminSubscriptions — подписки всех пользователей, а не только своего
+	PermAdminSubscriptions = "admin:subscriptions"
+	PermAdminWebhooks      = "admin:webhooks"
+	PermAdminAPIKeys       = "admin:api-keys"
+	PermAdminLogLevel      = "admin:log-level"
+	// PermAdminTenants — заведение тенантов и выбор тенанта запроса заголовком X-Tenant-ID
+	PermAdminTenants = "admin:tenants"
+
+	// permissionWildcard в политике заменяет действие или разрешение целиком: admin:* или *
+	permissionWildcard = "*"
+)
+
+// knownPermissions — разрешения, которые проверяет сервис. Политика с другими считается ошибкой
+var knownPermissions = []string{
+	PermSubscriptionRead,
+	PermSubscriptionWrite,
+	PermReportsRead,
+	PermAdminSubscriptions,
+	PermAdminWebhooks,
+	PermAdminAPIKeys,
+	PermAdminLogLevel,
+	PermAdminTenants,
+}
+
+// Роли встроенной политики
+const (
+	RoleViewer  = "viewer"
+	RoleEditor  = "editor"
+	RoleFinance = "finance"
+	RoleAdmin   = "admin"
+)
+
+// Permissions — разрешения субъекта, могут содержать шаблоны вида admin:*
+type Permissions []string
+
+// Has сообщает, входит ли permission в набор напрямую или по шаблону
+func (p Permissions) Has(permission string) bool {
+	resource, _, _ := strings.Cut(permission, ":")
+	for _, granted := range p {
+		if granted == permissionWildcard || granted == permission || granted == resource+":"+permissionWildcard {
+			return true
+		}
+	}
+	return false
+}
+
+// Policy — роли и их разрешения. Роли субъекта задаются ключом API или claim roles токена
+type Policy struct {
+	// DefaultRoles — роли субъекта, которому ключ или токен не назначили ни одной роли
+	DefaultRoles []string `yaml:"default_roles"`
+	// Roles — разрешения каждой роли
+	Roles map[string]Permissions `yaml:"roles"`
+}
+
+// DefaultPolicy — политика без файла: роли и ключи без ролей работают как до появления ролей
+func DefaultPolicy() *Policy {
+	return &Policy{
+		DefaultRoles: []string{RoleEditor},
+		Roles: map[string]Permissions{
+			RoleViewer:  {PermSubscriptionRead},
+			RoleEditor:  {PermSubscriptionRead, PermSubscriptionWrite},
+			RoleFinance: {PermReportsRead},
+			RoleAdmin:   {"subscription:*", "reports:*", "admin:*"},
+		},
+	}
+}
+
+// LoadPolicy читает политику из файла .yaml или .toml, пустой path — DefaultPolicy
+func LoadPolicy(path string) (*Policy, error) {
+	if path == "" {
+		return DefaultPolicy(), nil
+	}
+
+	policy := &Policy{}
+	if err := configLoader.LoadFile(path, policy); err != nil {
+		return nil, err
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("policy %s: %w", path, err)
+	}
+	return policy, nil
+}
+
+// Validate проверяет, что роли по умолчанию описаны, а разрешения известны сервису
+func (p *Policy) Validate() error {
+	if len(p.Roles) == 0 {
+		return errors.New("no roles defined")
+	}
+
+	for role, permissions := range p.Roles {
+		if role == "" {
+			return errors.New("empty role name")
+		}
+		for _, permission := range permissions {
+			if !validPermission(permission) {
+				return fmt.Errorf("role %s: unknown permission %q", role, permission)
+			}
+		}
+	}
+
+	for _, role := range p.DefaultRoles {
+		if !p.HasRole(role) {
+			return fmt.Errorf("default role %q is not defined", role)
+		}
+	}
+	return nil
+}
+
+// validPermission допускает известные разрешения и шаблоны, под которые подходит хотя бы одно из них
+func validPermission(permission string) bool {
+	for _, known := range knownPermissions {
+		if (Permissions{permission}).Has(known) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Policy) HasRole(role string) bool {
+	_, ok := p.Roles[role]
+	return ok
+}
+
+// Permissions собирает разрешения ролей. Неизвестные роли пропускаются: токен может нести роли других систем
+func (p *Policy) Permissions(roles []string) Permissions {
+	var permissions Permissions
+	for _, role := range roles {
+		permissions = append(permissions, p.Roles[role]...)
+	}
+	return permissions
+}
+
+// Bind дополняет субъектов, которых находит authenticator, разрешениями их ролей.
+// Субъекту без ролей назначаются DefaultRoles
+func (p *Policy) Bind(authenticator Authenticator) Authenticator {
+	return &policyAuthenticator{Authenticator: authenticator, policy: p}
+}
+
+type policyAuthenticator struct {
+	Authenticator
+	policy *Policy
+}
+
+func (a *policyAuthenticator) Authenticate(ctx context.Context, header http.Header) (*Principal, error) {
+	principal, err := a.Authenticator.Authenticate(ctx, header)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(principal.Roles) == 0 {
+		principal.Roles = a.policy.DefaultRoles
+	}
+	principal.Permissions = a.policy.Permissions(principal.Roles)
+	return principal, nil
+}
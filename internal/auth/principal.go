@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"awesomeProject1/pkg/logger"
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Способы, которыми субъект подтвердил личность
+const (
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+	// MethodNone — аутентификация выключена в конфигурации
+	MethodNone = "none"
+	// MethodInternal — вызов изнутри сервиса или утилит оператора, а не через API
+	MethodInternal = "internal"
+)
+
+// Principal — аутентифицированный субъект запроса
+type Principal struct {
+	// Subject — кто сделал запрос: sub из токена или api_key:<id>
+	Subject string
+	// UserID — пользователь, от имени которого выполняется запрос, uuid.Nil для сервисных ключей
+	UserID uuid.UUID
+	// Roles — роли из ключа или токена, по ним политика выдаёт Permissions
+	Roles       []string
+	Permissions Permissions
+	Method      string
+	// TenantID — тенант ключа или токена, nil — субъект платформы, который работает с любым тенантом
+	TenantID *uuid.UUID
+}
+
+var (
+	// Anonymous — субъект запросов при выключенной аутентификации, ему разрешено всё, как до её появления
+	Anonymous = &Principal{Subject: "anonymous", Roles: []string{RoleAdmin}, Permissions: Permissions{permissionWildcard}, Method: MethodNone}
+	// System — субъект административных задач, которые работают с базой в обход API, например subctl
+	System = &Principal{Subject: "system", Roles: []string{RoleAdmin}, Permissions: Permissions{permissionWildcard}, Method: MethodInternal}
+)
+
+type contextKey struct{}
+
+type allOwnersKey struct{}
+
+// WithPrincipal сохраняет субъекта в ctx
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, principal)
+}
+
+// Attach сохраняет субъекта запроса в ctx и добавляет его в логгер и span запроса
+func Attach(ctx context.Context, principal *Principal) context.Context {
+	fields := logger.Fields{"subject": principal.Subject, "auth": principal.Method}
+	if principal.UserID != uuid.Nil {
+		fields["user_id"] = principal.UserID.String()
+	}
+	if len(principal.Roles) > 0 {
+		fields["roles"] = strings.Join(principal.Roles, ",")
+	}
+	ctx = logger.WithFields(ctx, fields)
+
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("enduser.id", principal.Subject))
+
+	return WithPrincipal(ctx, principal)
+}
+
+// FromContext возвращает субъекта запроса, ok = false вне аутентифицированного запроса
+func FromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(contextKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
+
+// WithAllOwners снимает ограничение подписок пользователем для внутренних вызовов без субъекта,
+// доступ которых проверен иначе, например ленты календаря по токену. Без субъекта и без этой
+// отметки репозитории не возвращают ни одной подписки
+func WithAllOwners(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allOwnersKey{}, true)
+}
+
+// AllOwners сообщает, снято ли в ctx ограничение пользователем через WithAllOwners
+func AllOwners(ctx context.Context) bool {
+	all, _ := ctx.Value(allOwnersKey{}).(bool)
+	return all
+}
+
+// Can сообщает, есть ли у субъекта разрешение permission
+func (p *Principal) Can(permission string) bool {
+	return p.Permissions.Has(permission)
+}
+
+// Owner возвращает пользователя, подписками которого ограничен субъект, nil — доступ ко всем подпискам.
+// Для субъекта без пользователя это uuid.Nil, под который не подходит ни одна запись
+func (p *Principal) Owner() *uuid.UUID {
+	if p.Can(PermAdminSubscriptions) {
+		return nil
+	}
+	owner := p.UserID
+	return &owner
+}
@@ -0,0 +1,126 @@
+package dto
+
+import (
+	"awesomeProject1/pkg/validator"
+	"database/sql"
+	"fmt"
+	"github.com/google/uuid"
+	"time"
+)
+
+// APIKey — ключ доступа к API. Сам ключ не хранится, только его хэш
+type APIKey struct {
+	ID         uuid.UUID     `db:"id"`
+	Name       string        `db:"name"`
+	Prefix     string        `db:"prefix"`
+	KeyHash    string        `db:"key_hash"`
+	UserID     uuid.NullUUID `db:"user_id"`
+	Roles      []string      `db:"roles"`
+	ExpiresAt  sql.NullTime  `db:"expires_at"`
+	RevokedAt  sql.NullTime  `db:"revoked_at"`
+	LastUsedAt sql.NullTime  `db:"last_used_at"`
+	CreatedAt  time.Time     `db:"created_at"`
+	// TenantID — тенант ключа, без него ключ платформы: тенант запроса выбирается заголовком X-Tenant-ID
+	TenantID uuid.NullUUID `db:"tenant_id"`
+}
+
+// Active сообщает, можно ли сейчас пользоваться ключом
+func (k *APIKey) Active(now time.Time) bool {
+	return !k.RevokedAt.Valid && (!k.ExpiresAt.Valid || now.Before(k.ExpiresAt.Time))
+}
+
+// APIKeyResponse — DTO для ответа API, ключ целиком возвращается только при создании
+type APIKeyResponse struct {
+	ID         uuid.UUID  `json:"id" example:"7b1e4c2a-9f3d-4a8e-b6c5-2d1f0e9a8b7c"`
+	Name       string     `json:"name" example:"billing-service"`
+	Key        string     `json:"key,omitempty" example:"sk_2f9c3a7e1b8d4c6a9e0f5b7d3a1c8e4f2f9c3a7e1b8d4c6a9e0f5b7d3a1c8e4f"`
+	Prefix     string     `json:"prefix" example:"sk_2f9c3a7e"`
+	UserID     *uuid.UUID `json:"user_id,omitempty" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	TenantID   *uuid.UUID `json:"tenant_id,omitempty" example:"00000000-0000-0000-0000-000000000001"`
+	Roles      []string   `json:"roles" example:"editor"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" example:"2026-10-28T10:00:00Z"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" example:"2025-11-01T10:00:00Z"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" example:"2025-10-30T10:00:00Z"`
+	CreatedAt  time.Time  `json:"created_at" example:"2025-10-28T10:00:00Z"`
+}
+
+func (k *APIKey) ToResponse() *APIKeyResponse {
+	response := &APIKeyResponse{
+		ID:        k.ID,
+		Name:      k.Name,
+		Prefix:    k.Prefix,
+		Roles:     k.Roles,
+		CreatedAt: k.CreatedAt,
+	}
+
+	if k.UserID.Valid {
+		response.UserID = &k.UserID.UUID
+	}
+	if k.TenantID.Valid {
+		response.TenantID = &k.TenantID.UUID
+	}
+	if k.ExpiresAt.Valid {
+		response.ExpiresAt = &k.ExpiresAt.Time
+	}
+	if k.RevokedAt.Valid {
+		response.RevokedAt = &k.RevokedAt.Time
+	}
+	if k.LastUsedAt.Valid {
+		response.LastUsedAt = &k.LastUsedAt.Time
+	}
+
+	return response
+}
+
+// APIKeyListResponse — DTO для списка ключей
+type APIKeyListResponse struct {
+	Total  int               `json:"total" example:"3"`
+	Offset int               `json:"offset" example:"0"`
+	Limit  int               `json:"limit" example:"10"`
+	Keys   []*APIKeyResponse `json:"keys"`
+}
+
+// CreateAPIKeyRequest — DTO для выпуска ключа. Без user_id ключ сервисный и не привязан к пользователю,
+// без roles ключ получает роли по умолчанию из политики
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" example:"billing-service"`
+	UserID    *string    `json:"user_id,omitempty" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	Roles     []string   `json:"roles,omitempty" example:"finance"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" example:"2026-10-28T10:00:00Z"`
+	// Platform — ключ платформы без тенанта, выпустить его может только субъект платформы с admin:tenants.
+	// Иначе ключ привязан к тенанту запроса
+	Platform bool `json:"platform,omitempty" example:"false"`
+}
+
+func (r *CreateAPIKeyRequest) IsValid() (bool, []validator.FieldError) {
+	v := validator.New()
+	v.CheckString(r.Name, "name").IsMin(1).IsMax(255)
+	if r.UserID != nil {
+		v.CheckString(*r.UserID, "user_id").IsUuid()
+	}
+	for i, role := range r.Roles {
+		v.CheckString(role, fmt.Sprintf("roles[%d]", i)).IsMin(1).IsMax(64)
+	}
+	if r.ExpiresAt != nil && !r.ExpiresAt.After(time.Now()) {
+		v.AddError("expires_at", validator.CodeInvalidRange, fmt.Sprintf("[expires_at] - Expected time in the future. Got: %s", r.ExpiresAt.Format(time.RFC3339)))
+	}
+
+	return !v.HasErrors(), v.GetErrors()
+}
+
+// ToAPIKey собирает ключ без хэша, хэш заполняет сервис. Вызывать после IsValid
+func (r *CreateAPIKeyRequest) ToAPIKey() *APIKey {
+	key := &APIKey{
+		Name:  r.Name,
+		Roles: r.Roles,
+	}
+
+	if r.UserID != nil {
+		key.UserID = uuid.NullUUID{UUID: uuid.MustParse(*r.UserID), Valid: true}
+	}
+	if r.ExpiresAt != nil {
+		key.ExpiresAt = sql.NullTime{Time: *r.ExpiresAt, Valid: true}
+	}
+
+	return key
+}
@@ -0,0 +1,7 @@
+package dto
+
+// CalendarTokenResponse — токен и ссылка на календарь, которую можно добавить в приложение календаря
+type CalendarTokenResponse struct {
+	Token string `json:"token" example:"2f9c3a7e1b8d4c6a9e0f5b7d3a1c8e4f2f9c3a7e1b8d4c6a9e0f5b7d3a1c8e4f"`
+	URL   string `json:"url" example:"http://localhost:8080/api/v1/users/60601fee-2bf1-4721-ae6f-7636e79a0cba/calendar.ics?token=2f9c3a7e"`
+}
@@ -0,0 +1,55 @@
+package dto
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"time"
+)
+
+// EventType — тип события подписки
+type EventType string
+
+const (
+	EventSubscriptionCreated EventType = "subscription.created"
+	EventSubscriptionUpdated EventType = "subscription.updated"
+	EventSubscriptionDeleted EventType = "subscription.deleted"
+)
+
+// EventTypes — все типы событий, на которые можно подписать вебхук
+var EventTypes = []EventType{EventSubscriptionCreated, EventSubscriptionUpdated, EventSubscriptionDeleted}
+
+// IsKnownEventType проверяет, что тип события поддерживается
+func IsKnownEventType(eventType string) bool {
+	for _, t := range EventTypes {
+		if string(t) == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Event — событие в том виде, в котором оно хранится в outbox и уходит на вебхуки
+type Event struct {
+	// Seq — порядковый номер события в журнале, заполняется при чтении из БД
+	Seq       int64                 `json:"-"`
+	TenantID  uuid.UUID             `json:"-"` // тенант подписки, заполняется при чтении из БД
+	ID        uuid.UUID             `json:"id" example:"9b2e6c1a-2f4b-4a57-9d0a-3c3f2b1e7a10"`
+	Type      EventType             `json:"type" example:"subscription.created"`
+	CreatedAt time.Time             `json:"created_at" example:"2025-10-28T10:00:00Z"`
+	Data      *SubscriptionResponse `json:"data"`
+}
+
+// NewSubscriptionEvent создаёт событие с текущим состоянием подписки
+func NewSubscriptionEvent(eventType EventType, s *Subscription) *Event {
+	return &Event{
+		ID:        uuid.New(),
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      s.ToResponse(),
+	}
+}
+
+// Payload — тело события в JSON
+func (e *Event) Payload() ([]byte, error) {
+	return json.Marshal(e)
+}
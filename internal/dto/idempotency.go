@@ -0,0 +1,18 @@
+package dto
+
+import "time"
+
+// IdempotencyRecord — сохранённый результат запроса с заголовком Idempotency-Key
+type IdempotencyRecord struct {
+	Key          string            `db:"key"`
+	RequestHash  string            `db:"request_hash"`
+	StatusCode   int               `db:"status_code"` // 0 — запрос ещё обрабатывается
+	Headers      map[string]string `db:"headers"`
+	ResponseBody []byte            `db:"response_body"`
+	ExpiresAt    time.Time         `db:"expires_at"`
+}
+
+// IsCompleted сообщает, сохранён ли уже ответ на запрос
+func (r *IdempotencyRecord) IsCompleted() bool {
+	return r.StatusCode != 0
+}
@@ -1,7 +1,9 @@
 package dto
 
 import (
+	"crypto/sha256"
 	"database/sql"
+	"fmt"
 	"github.com/google/uuid"
 	"time"
 )
@@ -16,6 +18,13 @@ type Subscription struct {
 	EndDate     sql.NullTime `json:"end_date,omitempty" db:"end_date"`
 	CreatedAt   time.Time    `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time    `json:"updated_at" db:"updated_at"`
+	Version     int          `json:"version" db:"version"`
+}
+
+// ETag возвращает строгий ETag текущей версии записи
+func (s *Subscription) ETag() string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s:%d:%d", s.ID, s.Version, s.UpdatedAt.UnixNano())))
+	return fmt.Sprintf("\"%x\"", hash[:8])
 }
 
 // ToResponse конвертирует Subscription в SubscriptionResponse для API
@@ -28,6 +37,8 @@ func (s *Subscription) ToResponse() *SubscriptionResponse {
 		StartDate:   FormatMonthYear(s.StartDate),
 		CreatedAt:   s.CreatedAt,
 		UpdatedAt:   s.UpdatedAt,
+		Version:     s.Version,
+		ETag:        s.ETag(),
 	}
 
 	if s.EndDate.Valid {
@@ -16,25 +16,25 @@ type CreateSubscriptionRequest struct {
 	EndDate     string `json:"end_date,omitempty" example:"12-2025"`
 }
 
-func (r *CreateSubscriptionRequest) IsValid() (bool, []string) {
+func (r *CreateSubscriptionRequest) IsValid() (bool, []validator.FieldError) {
 	v := validator.New()
-	v.CheckString(r.ServiceName, "ServiceName").IsMin(1).IsMax(255)
-	v.CheckString(r.UserID, "UserID").IsUuid()
-	v.CheckNumber(r.Price, "Price").IsMin(0)
+	v.CheckString(r.ServiceName, "service_name").IsMin(1).IsMax(255)
+	v.CheckString(r.UserID, "user_id").IsUuid()
+	v.CheckNumber(r.Price, "price").IsMin(0)
 
 	startDate, err := ParseMonthYear(r.StartDate)
 	if err != nil {
-		v.AddError(fmt.Sprintf("Invalid start_date format. Expected MM-YYYY (e.g., 01-2025). Got: %s", r.StartDate))
+		v.AddError("start_date", validator.CodeInvalidFormat, fmt.Sprintf("Invalid start_date format. Expected MM-YYYY (e.g., 01-2025). Got: %s", r.StartDate))
 	}
 
 	if r.EndDate != "" {
 		endDate, err := ParseMonthYear(r.EndDate)
 		if err != nil {
-			v.AddError(fmt.Sprintf("Invalid end_date format. Expected MM-YYYY (e.g., 12-2025). Got: %s", r.EndDate))
+			v.AddError("end_date", validator.CodeInvalidFormat, fmt.Sprintf("Invalid end_date format. Expected MM-YYYY (e.g., 12-2025). Got: %s", r.EndDate))
 		}
 
 		if err == nil && endDate.Before(startDate) {
-			v.AddError(fmt.Sprintf("end_date must be after start_date. Got: end_date=%s, start_date=%s", r.EndDate, r.StartDate))
+			v.AddError("end_date", validator.CodeInvalidRange, fmt.Sprintf("end_date must be after start_date. Got: end_date=%s, start_date=%s", r.EndDate, r.StartDate))
 		}
 	}
 
@@ -0,0 +1,19 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SubscriptionCursor — позиция в списке подписок, отсортированном по created_at и id по убыванию.
+// В отличие от offset, не сдвигается, когда подписки добавляются или удаляются во время обхода
+type SubscriptionCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// CursorOf — курсор, указывающий на подписку s
+func CursorOf(s *SubscriptionResponse) *SubscriptionCursor {
+	return &SubscriptionCursor{CreatedAt: s.CreatedAt, ID: s.ID}
+}
@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"awesomeProject1/pkg/validator"
+	"strings"
+)
+
+// SubscriptionFilter — фильтры для списка подписок, пустые поля не учитываются
+type SubscriptionFilter struct {
+	UserId      string `example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	ServiceName string `example:"Yandex Plus"`
+}
+
+// IsValid — валидация фильтров
+func (f *SubscriptionFilter) IsValid() (bool, []validator.FieldError) {
+	v := validator.New()
+
+	if f.UserId != "" {
+		v.CheckString(f.UserId, "user_id").IsUuid()
+	}
+
+	if f.ServiceName != "" {
+		v.CheckString(f.ServiceName, "service_name").IsMax(255)
+	}
+
+	return !v.HasErrors(), v.GetErrors()
+}
+
+// Matches проверяет, подходит ли подписка под фильтры
+func (f *SubscriptionFilter) Matches(s *SubscriptionResponse) bool {
+	if f.UserId != "" && !strings.EqualFold(f.UserId, s.UserID.String()) {
+		return false
+	}
+	if f.ServiceName != "" && f.ServiceName != s.ServiceName {
+		return false
+	}
+	return true
+}
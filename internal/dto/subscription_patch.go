@@ -0,0 +1,94 @@
+package dto
+
+import (
+	"awesomeProject1/pkg/validator"
+	"database/sql"
+	"github.com/google/uuid"
+)
+
+// PatchFunc — функция, применяющая патч (JSON Patch или Merge Patch) к JSON-документу подписки
+type PatchFunc func(doc []byte) ([]byte, error)
+
+// SubscriptionPatchDocument — изменяемая часть подписки, к которой применяется патч.
+// Отсутствующий или null end_date означает подписку без даты окончания
+type SubscriptionPatchDocument struct {
+	ServiceName *string `json:"service_name"`
+	Price       *int    `json:"price"`
+	UserID      *string `json:"user_id"`
+	StartDate   *string `json:"start_date"`
+	EndDate     *string `json:"end_date"`
+}
+
+// NewSubscriptionPatchDocument строит документ из текущего состояния подписки
+func NewSubscriptionPatchDocument(s *Subscription) *SubscriptionPatchDocument {
+	userID := s.UserID.String()
+	startDate := FormatMonthYear(s.StartDate)
+
+	doc := &SubscriptionPatchDocument{
+		ServiceName: &s.ServiceName,
+		Price:       &s.Price,
+		UserID:      &userID,
+		StartDate:   &startDate,
+	}
+
+	if s.EndDate.Valid {
+		endDate := FormatMonthYear(s.EndDate.Time)
+		doc.EndDate = &endDate
+	}
+
+	return doc
+}
+
+// IsValid проверяет документ после применения патча по тем же правилам, что и создание подписки
+func (d *SubscriptionPatchDocument) IsValid() (bool, []validator.FieldError) {
+	v := validator.New()
+	if d.ServiceName == nil {
+		v.AddError("service_name", validator.CodeRequired, "[service_name] - Field is required")
+	}
+	if d.Price == nil {
+		v.AddError("price", validator.CodeRequired, "[price] - Field is required")
+	}
+	if d.UserID == nil {
+		v.AddError("user_id", validator.CodeRequired, "[user_id] - Field is required")
+	}
+	if d.StartDate == nil {
+		v.AddError("start_date", validator.CodeRequired, "[start_date] - Field is required")
+	}
+	if v.HasErrors() {
+		return false, v.GetErrors()
+	}
+
+	return d.toCreateRequest().IsValid()
+}
+
+// ToUpdateData конвертирует документ в UpdateData, в котором заданы все поля
+func (d *SubscriptionPatchDocument) ToUpdateData(id uuid.UUID) (*UpdateData, error) {
+	sub, err := d.toCreateRequest().ToSubscription()
+	if err != nil {
+		return nil, err
+	}
+
+	return &UpdateData{
+		ID:          id,
+		ServiceName: &sub.ServiceName,
+		Price:       &sub.Price,
+		UserID:      &sub.UserID,
+		StartDate:   &sub.StartDate,
+		EndDate:     &sql.NullTime{Time: sub.EndDate.Time, Valid: sub.EndDate.Valid},
+	}, nil
+}
+
+func (d *SubscriptionPatchDocument) toCreateRequest() *CreateSubscriptionRequest {
+	req := &CreateSubscriptionRequest{
+		ServiceName: *d.ServiceName,
+		Price:       *d.Price,
+		UserID:      *d.UserID,
+		StartDate:   *d.StartDate,
+	}
+
+	if d.EndDate != nil {
+		req.EndDate = *d.EndDate
+	}
+
+	return req
+}
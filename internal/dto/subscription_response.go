@@ -15,6 +15,8 @@ type SubscriptionResponse struct {
 	EndDate     *string   `json:"end_date,omitempty" example:"12-2025"`
 	CreatedAt   time.Time `json:"created_at" example:"2025-10-28T10:00:00Z"`
 	UpdatedAt   time.Time `json:"updated_at" example:"2025-10-28T10:00:00Z"`
+	Version     int       `json:"version" example:"1"`
+	ETag        string    `json:"etag" example:"\"5d41402abc4b2a76\""`
 }
 
 // SubscriptionListResponse — DTO для списка подписок
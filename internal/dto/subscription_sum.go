@@ -14,15 +14,15 @@ type GetTotalSumRequest struct {
 }
 
 // IsValid — валидация параметров запроса
-func (r *GetTotalSumRequest) IsValid() (bool, []string) {
+func (r *GetTotalSumRequest) IsValid() (bool, []validator.FieldError) {
 	v := validator.New()
 
 	if r.UserId != "" {
-		v.CheckString(r.UserId, "UserId").IsUuid()
+		v.CheckString(r.UserId, "user_id").IsUuid()
 	}
 
 	if r.ServiceName != "" {
-		v.CheckString(r.ServiceName, "ServiceName").IsMin(1).IsMax(255)
+		v.CheckString(r.ServiceName, "service_name").IsMin(1).IsMax(255)
 	}
 
 	return !v.HasErrors(), v.GetErrors()
@@ -37,3 +37,16 @@ func NewGetTotalSumRequest(start, end time.Time, userId, serviceName string) *Ge
 		ServiceName: serviceName,
 	}
 }
+
+// MonthlyTotal — сумма подписок, начавшихся в указанном месяце
+type MonthlyTotal struct {
+	Month string `json:"month" example:"01-2025"` // Формат MM-YYYY
+	Total int    `json:"total" example:"400"`
+}
+
+// ServiceStats — действующие подписки сервиса и их ежемесячная выручка
+type ServiceStats struct {
+	ServiceName    string
+	Active         int
+	MonthlyRevenue int
+}
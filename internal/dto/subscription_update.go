@@ -26,23 +26,27 @@ type UpdateData struct {
 	UserID      *uuid.UUID
 	StartDate   *time.Time
 	EndDate     *sql.NullTime
+	// IfMatch — ETag из заголовка If-Match, пустой список отключает проверку
+	IfMatch []string
+	// Version — ожидаемая версия записи, подставляется сервисом в условие WHERE
+	Version *int
 }
 
 // IsValid проверяет корректность данных запроса
-func (c *UpdateSubscriptionRequest) IsValid() (bool, []string) {
+func (c *UpdateSubscriptionRequest) IsValid() (bool, []validator.FieldError) {
 	v := validator.New()
 	v.CheckString(c.ID, "id").IsUuid()
 
 	if c.ServiceName != nil {
-		v.CheckString(*c.ServiceName, "ServiceName").IsMin(1).IsMax(255)
+		v.CheckString(*c.ServiceName, "service_name").IsMin(1).IsMax(255)
 	}
 
 	if c.Price != nil {
-		v.CheckNumber(*c.Price, "Price").IsMin(0)
+		v.CheckNumber(*c.Price, "price").IsMin(0)
 	}
 
 	if c.UserID != nil {
-		v.CheckString(*c.UserID, "UserID").IsUuid()
+		v.CheckString(*c.UserID, "user_id").IsUuid()
 	}
 
 	var startDate time.Time
@@ -51,19 +55,19 @@ func (c *UpdateSubscriptionRequest) IsValid() (bool, []string) {
 	if c.StartDate != nil {
 		parsed, err := ParseMonthYear(*c.StartDate)
 		if err != nil {
-			v.AddError(fmt.Sprintf("Invalid start_date format. Expected MM-YYYY (e.g., 01-2025). Got: %s", *c.StartDate))
+			v.AddError("start_date", validator.CodeInvalidFormat, fmt.Sprintf("Invalid start_date format. Expected MM-YYYY (e.g., 01-2025). Got: %s", *c.StartDate))
 		} else {
 			startDate = parsed
 			startDateValid = true
 		}
 	}
 
-	if c.EndDate != nil {
+	if c.EndDate != nil && *c.EndDate != "" {
 		endDate, err := ParseMonthYear(*c.EndDate)
 		if err != nil {
-			v.AddError(fmt.Sprintf("Invalid end_date format. Expected MM-YYYY (e.g., 12-2025). Got: %s", *c.EndDate))
+			v.AddError("end_date", validator.CodeInvalidFormat, fmt.Sprintf("Invalid end_date format. Expected MM-YYYY (e.g., 12-2025). Got: %s", *c.EndDate))
 		} else if startDateValid && endDate.Before(startDate) {
-			v.AddError(fmt.Sprintf("end_date must be after start_date. Got: end_date=%s, start_date=%s", *c.EndDate, *c.StartDate))
+			v.AddError("end_date", validator.CodeInvalidRange, fmt.Sprintf("end_date must be after start_date. Got: end_date=%s, start_date=%s", *c.EndDate, *c.StartDate))
 		}
 	}
 
@@ -96,16 +100,20 @@ func (c *UpdateSubscriptionRequest) ToUpdateData() (*UpdateData, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse start_date: %w", err)
 		}
-		data.EndDate = &sql.NullTime{Valid: false}
 		data.StartDate = &startDate
 	}
 
-	if c.EndDate != nil && *c.EndDate != "" {
-		endDate, err := ParseMonthYear(*c.EndDate)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse end_date: %w", err)
+	// Пустая строка в end_date снимает дату окончания, отсутствие поля оставляет её без изменений
+	if c.EndDate != nil {
+		data.EndDate = &sql.NullTime{Valid: false}
+
+		if *c.EndDate != "" {
+			endDate, err := ParseMonthYear(*c.EndDate)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse end_date: %w", err)
+			}
+			data.EndDate = &sql.NullTime{Time: endDate, Valid: true}
 		}
-		data.EndDate = &sql.NullTime{Time: endDate, Valid: true}
 	}
 
 	return data, nil
@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"awesomeProject1/pkg/validator"
+	"github.com/google/uuid"
+	"time"
+)
+
+// Tenant — компания-клиент, данные которой отделены от данных других компаний
+type Tenant struct {
+	ID        uuid.UUID `db:"id"`
+	Name      string    `db:"name"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// TenantResponse — DTO для ответа API
+type TenantResponse struct {
+	ID        uuid.UUID `json:"id" example:"3f6c1d2e-8a4b-4c7d-9e1f-2a3b4c5d6e7f"`
+	Name      string    `json:"name" example:"acme"`
+	CreatedAt time.Time `json:"created_at" example:"2025-10-28T10:00:00Z"`
+}
+
+func (t *Tenant) ToResponse() *TenantResponse {
+	return &TenantResponse{
+		ID:        t.ID,
+		Name:      t.Name,
+		CreatedAt: t.CreatedAt,
+	}
+}
+
+// TenantListResponse — DTO для списка тенантов
+type TenantListResponse struct {
+	Total   int               `json:"total" example:"3"`
+	Offset  int               `json:"offset" example:"0"`
+	Limit   int               `json:"limit" example:"10"`
+	Tenants []*TenantResponse `json:"tenants"`
+}
+
+// CreateTenantRequest — DTO для заведения тенанта
+type CreateTenantRequest struct {
+	Name string `json:"name" example:"acme"`
+}
+
+func (r *CreateTenantRequest) IsValid() (bool, []validator.FieldError) {
+	v := validator.New()
+	v.CheckString(r.Name, "name").IsMin(1).IsMax(255)
+
+	return !v.HasErrors(), v.GetErrors()
+}
+
+func (r *CreateTenantRequest) ToTenant() *Tenant {
+	return &Tenant{Name: r.Name}
+}
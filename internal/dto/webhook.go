@@ -0,0 +1,209 @@
+package dto
+
+import (
+	"awesomeProject1/pkg/validator"
+	"fmt"
+	"github.com/google/uuid"
+	"net/url"
+	"time"
+)
+
+const (
+	webhookSecretMinLength = 16
+	webhookURLMaxLength    = 2048
+)
+
+// Webhook — адрес, на который отправляются события подписок
+type Webhook struct {
+	ID         uuid.UUID `db:"id"`
+	URL        string    `db:"url"`
+	Secret     string    `db:"secret"`
+	EventTypes []string  `db:"event_types"`
+	Active     bool      `db:"active"`
+	CreatedAt  time.Time `db:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at"`
+}
+
+// WebhookResponse — DTO для ответа API, секрет возвращается только при создании
+type WebhookResponse struct {
+	ID         uuid.UUID `json:"id" example:"0c7d2b9e-5d1a-4e9f-8a2b-6c4d3e2f1a0b"`
+	URL        string    `json:"url" example:"https://example.com/hooks/subscriptions"`
+	Secret     string    `json:"secret,omitempty" example:"2f9c3a7e1b8d4c6a9e0f5b7d3a1c8e4f"`
+	EventTypes []string  `json:"event_types" example:"subscription.created,subscription.deleted"`
+	Active     bool      `json:"active" example:"true"`
+	CreatedAt  time.Time `json:"created_at" example:"2025-10-28T10:00:00Z"`
+	UpdatedAt  time.Time `json:"updated_at" example:"2025-10-28T10:00:00Z"`
+}
+
+func (w *Webhook) ToResponse() *WebhookResponse {
+	return &WebhookResponse{
+		ID:         w.ID,
+		URL:        w.URL,
+		EventTypes: w.EventTypes,
+		Active:     w.Active,
+		CreatedAt:  w.CreatedAt,
+		UpdatedAt:  w.UpdatedAt,
+	}
+}
+
+// WebhookListResponse — DTO для списка вебхуков
+type WebhookListResponse struct {
+	Total    int                `json:"total" example:"3"`
+	Offset   int                `json:"offset" example:"0"`
+	Limit    int                `json:"limit" example:"10"`
+	Webhooks []*WebhookResponse `json:"webhooks"`
+}
+
+// CreateWebhookRequest — DTO для регистрации вебхука. Пустой secret генерируется сервером
+type CreateWebhookRequest struct {
+	URL        string   `json:"url" example:"https://example.com/hooks/subscriptions"`
+	Secret     string   `json:"secret,omitempty" example:"2f9c3a7e1b8d4c6a9e0f5b7d3a1c8e4f"`
+	EventTypes []string `json:"event_types" example:"subscription.created,subscription.deleted"`
+	Active     *bool    `json:"active,omitempty" example:"true"`
+}
+
+func (r *CreateWebhookRequest) IsValid() (bool, []validator.FieldError) {
+	v := validator.New()
+	validateWebhookURL(v, r.URL)
+	if r.Secret != "" {
+		v.CheckString(r.Secret, "secret").IsMin(webhookSecretMinLength).IsMax(255)
+	}
+	validateEventTypes(v, r.EventTypes)
+
+	return !v.HasErrors(), v.GetErrors()
+}
+
+func (r *CreateWebhookRequest) ToWebhook() *Webhook {
+	webhook := &Webhook{
+		URL:        r.URL,
+		Secret:     r.Secret,
+		EventTypes: r.EventTypes,
+		Active:     true,
+	}
+
+	if r.Active != nil {
+		webhook.Active = *r.Active
+	}
+
+	return webhook
+}
+
+// UpdateWebhookRequest — DTO для изменения вебхука, отсутствующие поля не меняются
+type UpdateWebhookRequest struct {
+	URL        *string  `json:"url,omitempty" example:"https://example.com/hooks/subscriptions"`
+	Secret     *string  `json:"secret,omitempty" example:"2f9c3a7e1b8d4c6a9e0f5b7d3a1c8e4f"`
+	EventTypes []string `json:"event_types,omitempty" example:"subscription.updated"`
+	Active     *bool    `json:"active,omitempty" example:"false"`
+}
+
+func (r *UpdateWebhookRequest) IsValid() (bool, []validator.FieldError) {
+	v := validator.New()
+	if r.URL != nil {
+		validateWebhookURL(v, *r.URL)
+	}
+	if r.Secret != nil {
+		v.CheckString(*r.Secret, "secret").IsMin(webhookSecretMinLength).IsMax(255)
+	}
+	if r.EventTypes != nil {
+		validateEventTypes(v, r.EventTypes)
+	}
+
+	return !v.HasErrors(), v.GetErrors()
+}
+
+func validateWebhookURL(v *validator.Validator, raw string) {
+	v.CheckString(raw, "url").IsMin(1).IsMax(webhookURLMaxLength)
+
+	parsed, err := url.Parse(raw)
+	if raw != "" && (err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "") {
+		v.AddError("url", validator.CodeInvalidFormat, fmt.Sprintf("[url] - Expected absolute http(s) url. Got: %s", raw))
+	}
+}
+
+func validateEventTypes(v *validator.Validator, eventTypes []string) {
+	if len(eventTypes) == 0 {
+		v.AddError("event_types", validator.CodeRequired, "[event_types] - At least one event type is required")
+		return
+	}
+
+	for _, eventType := range eventTypes {
+		if !IsKnownEventType(eventType) {
+			v.AddError("event_types", validator.CodeInvalidFormat, fmt.Sprintf("[event_types] - Unknown event type: %s. Expected one of: %v", eventType, EventTypes))
+		}
+	}
+}
+
+// DeliveryStatus — состояние доставки события на вебхук
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	// DeliveryDead — попытки исчерпаны, доставку можно только перезапустить вручную
+	DeliveryDead DeliveryStatus = "dead"
+)
+
+// WebhookDelivery — доставка одного события на один вебхук
+type WebhookDelivery struct {
+	ID             uuid.UUID      `db:"id"`
+	WebhookID      uuid.UUID      `db:"webhook_id"`
+	EventID        uuid.UUID      `db:"event_id"`
+	EventType      string         `db:"event_type"`
+	Payload        []byte         `db:"payload"`
+	Status         DeliveryStatus `db:"status"`
+	Attempts       int            `db:"attempts"`
+	NextAttemptAt  time.Time      `db:"next_attempt_at"`
+	LastStatusCode *int           `db:"last_status_code"`
+	LastError      *string        `db:"last_error"`
+	DeliveredAt    *time.Time     `db:"delivered_at"`
+	CreatedAt      time.Time      `db:"created_at"`
+	UpdatedAt      time.Time      `db:"updated_at"`
+
+	// URL и Secret вебхука, заполняются при выборке доставок на отправку
+	URL    string `db:"url"`
+	Secret string `db:"secret"`
+}
+
+// WebhookDeliveryResponse — запись журнала доставок
+type WebhookDeliveryResponse struct {
+	ID             uuid.UUID      `json:"id" example:"5a8f0e2c-7b3d-4c1e-9f6a-2d4b8c0e1f3a"`
+	WebhookID      uuid.UUID      `json:"webhook_id" example:"0c7d2b9e-5d1a-4e9f-8a2b-6c4d3e2f1a0b"`
+	EventID        uuid.UUID      `json:"event_id" example:"9b2e6c1a-2f4b-4a57-9d0a-3c3f2b1e7a10"`
+	EventType      string         `json:"event_type" example:"subscription.created"`
+	Status         DeliveryStatus `json:"status" example:"pending"`
+	Attempts       int            `json:"attempts" example:"2"`
+	NextAttemptAt  *time.Time     `json:"next_attempt_at,omitempty" example:"2025-10-28T10:05:00Z"`
+	LastStatusCode *int           `json:"last_status_code,omitempty" example:"503"`
+	LastError      *string        `json:"last_error,omitempty" example:"unexpected status 503"`
+	DeliveredAt    *time.Time     `json:"delivered_at,omitempty" example:"2025-10-28T10:00:01Z"`
+	CreatedAt      time.Time      `json:"created_at" example:"2025-10-28T10:00:00Z"`
+}
+
+func (d *WebhookDelivery) ToResponse() *WebhookDeliveryResponse {
+	response := &WebhookDeliveryResponse{
+		ID:             d.ID,
+		WebhookID:      d.WebhookID,
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		DeliveredAt:    d.DeliveredAt,
+		CreatedAt:      d.CreatedAt,
+	}
+
+	if d.Status == DeliveryPending {
+		response.NextAttemptAt = &d.NextAttemptAt
+	}
+
+	return response
+}
+
+// WebhookDeliveryListResponse — DTO для журнала доставок
+type WebhookDeliveryListResponse struct {
+	Total      int                        `json:"total" example:"100"`
+	Offset     int                        `json:"offset" example:"0"`
+	Limit      int                        `json:"limit" example:"10"`
+	Deliveries []*WebhookDeliveryResponse `json:"deliveries"`
+}
@@ -0,0 +1,242 @@
+package events
+
+import (
+	"awesomeProject1/internal/dto"
+	"awesomeProject1/internal/repository"
+	"awesomeProject1/internal/tenant"
+	"awesomeProject1/pkg/logger"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	subscriberBuffer = 64
+	catchUpBatchSize = 500
+	recentSeqsSize   = 1024
+	reconnectDelay   = 2 * time.Second
+)
+
+// Номер события выдаётся при вставке, а видно оно после коммита, поэтому события с меньшими номерами
+// могут появиться после уже прочитанных. При догоняющем чтении журнал перечитывается с ReorderWindow
+// номеров ниже курсора, если они записаны не раньше ReorderMaxAge назад. Окно меньше recentSeqsSize,
+// чтобы повторы отсеивались
+const (
+	ReorderWindow = 256
+	ReorderMaxAge = time.Minute
+)
+
+// Hub получает события всех экземпляров сервиса и всех тенантов через LISTEN/NOTIFY
+// и раздаёт их подписчикам этого экземпляра из тенанта события
+type Hub struct {
+	outbox   repository.IOutboxRepository
+	listener repository.IEventListener
+
+	mu          sync.Mutex
+	subscribers map[*Subscription]struct{}
+	closed      bool
+	lastSeq     int64
+	// listening — соединение LISTEN установлено и события приходят
+	listening atomic.Bool
+	// Недавно разосланные номера, чтобы не отправить событие дважды после догоняющего чтения
+	recent    map[int64]struct{}
+	recentLog []int64
+}
+
+// Subscription — подписка на поток событий. Канал закрывается, если подписчик не успевает читать:
+// клиент должен переподключиться с Last-Event-ID и дочитать пропущенное из журнала
+type Subscription struct {
+	Events <-chan *dto.Event
+
+	events chan *dto.Event
+	tenant *uuid.UUID
+	filter *dto.SubscriptionFilter
+	hub    *Hub
+	once   sync.Once
+}
+
+func NewHub(outbox repository.IOutboxRepository, listener repository.IEventListener) *Hub {
+	return &Hub{
+		outbox:      outbox,
+		listener:    listener,
+		subscribers: make(map[*Subscription]struct{}),
+		recent:      make(map[int64]struct{}),
+	}
+}
+
+// Run слушает уведомления, пока не отменён ctx, и переподключается при потере соединения
+func (h *Hub) Run(ctx context.Context) {
+	ctx = tenant.WithAll(ctx)
+
+	for ctx.Err() == nil {
+		seq, err := h.outbox.LastSeq(ctx)
+		if err == nil {
+			h.mu.Lock()
+			h.lastSeq = seq
+			h.mu.Unlock()
+			break
+		}
+		logger.FromContext(ctx).WithError(err).Error("EventHub -> LastSeq")
+		sleep(ctx, reconnectDelay)
+	}
+
+	for ctx.Err() == nil {
+		err := h.listener.Listen(ctx, func() {
+			h.listening.Store(true)
+			h.catchUp(ctx)
+		}, func(seq int64) { h.publishSeq(ctx, seq) })
+		h.listening.Store(false)
+		if ctx.Err() != nil {
+			return
+		}
+		logger.FromContext(ctx).WithError(err).Error("EventHub -> Listen")
+		sleep(ctx, reconnectDelay)
+	}
+}
+
+// Listening — получает ли хаб уведомления. Пока соединения нет, подписчики не получают новых событий
+func (h *Hub) Listening() bool {
+	return h.listening.Load()
+}
+
+// Subscribe подписывает на события тенанта tenantId, подходящие под filter. nil — события всех тенантов
+func (h *Hub) Subscribe(tenantId *uuid.UUID, filter *dto.SubscriptionFilter) *Subscription {
+	events := make(chan *dto.Event, subscriberBuffer)
+	sub := &Subscription{Events: events, events: events, tenant: tenantId, filter: filter, hub: h}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		sub.closeLocked()
+		return sub
+	}
+	h.subscribers[sub] = struct{}{}
+
+	return sub
+}
+
+// Shutdown закрывает каналы всех подписчиков при остановке сервиса, новые подписки сразу закрыты.
+// Получение событий при этом не прекращается, его останавливает отмена ctx в Run
+func (h *Hub) Shutdown() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for sub := range h.subscribers {
+		sub.closeLocked()
+	}
+}
+
+// Close отписывает от событий, повторный вызов ничего не делает
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.closeLocked()
+}
+
+func (s *Subscription) closeLocked() {
+	s.once.Do(func() {
+		delete(s.hub.subscribers, s)
+		close(s.events)
+	})
+}
+
+// catchUp дочитывает события, пропущенные пока не было соединения с LISTEN, вместе с поздно
+// закоммиченными событиями ниже курсора. Уже разосланные отсеивает publish по recent
+func (h *Hub) catchUp(ctx context.Context) {
+	h.mu.Lock()
+	cursor := h.lastSeq
+	h.mu.Unlock()
+
+	late, err := h.outbox.FindLate(ctx, cursor, ReorderWindow, ReorderMaxAge, &dto.SubscriptionFilter{})
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("EventHub -> FindLate")
+		return
+	}
+	for _, event := range late {
+		h.publish(event)
+	}
+
+	for {
+		h.mu.Lock()
+		after := h.lastSeq
+		h.mu.Unlock()
+
+		events, err := h.outbox.FindAfter(ctx, after, &dto.SubscriptionFilter{}, catchUpBatchSize)
+		if err != nil {
+			logger.FromContext(ctx).WithError(err).Error("EventHub -> FindAfter")
+			return
+		}
+
+		for _, event := range events {
+			h.publish(event)
+		}
+
+		if len(events) < catchUpBatchSize {
+			return
+		}
+	}
+}
+
+func (h *Hub) publishSeq(ctx context.Context, seq int64) {
+	h.mu.Lock()
+	_, seen := h.recent[seq]
+	h.mu.Unlock()
+	if seen {
+		return
+	}
+
+	event, ok, err := h.outbox.FindBySeq(ctx, seq)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("EventHub -> FindBySeq")
+		return
+	}
+	if ok {
+		h.publish(event)
+	}
+}
+
+func (h *Hub) publish(event *dto.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, seen := h.recent[event.Seq]; seen {
+		return
+	}
+	h.remember(event.Seq)
+
+	for sub := range h.subscribers {
+		if sub.tenant != nil && *sub.tenant != event.TenantID || !sub.filter.Matches(event.Data) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			sub.closeLocked()
+		}
+	}
+}
+
+func (h *Hub) remember(seq int64) {
+	h.recent[seq] = struct{}{}
+	h.recentLog = append(h.recentLog, seq)
+	if len(h.recentLog) > recentSeqsSize {
+		delete(h.recent, h.recentLog[0])
+		h.recentLog = h.recentLog[1:]
+	}
+
+	if seq > h.lastSeq {
+		h.lastSeq = seq
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(d):
+	}
+}
@@ -0,0 +1,67 @@
+package features
+
+import (
+	"awesomeProject1/pkg/httpHelpers"
+	"awesomeProject1/pkg/validator"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// Флаги, которыми можно выключить часть API без перезапуска
+const (
+	GraphQL      = "graphql"
+	EventsStream = "events_stream"
+	Calendar     = "calendar"
+)
+
+const ErrorDisabled = "This feature is disabled"
+
+// Config — включённые возможности по имени флага
+type Config map[string]bool
+
+func NewConfig() Config {
+	return Config{
+		GraphQL:      true,
+		EventsStream: true,
+		Calendar:     true,
+	}
+}
+
+// Enabled сообщает, включён ли флаг. Флаг, которого нет в конфигурации, считается включённым
+func (c Config) Enabled(name string) bool {
+	enabled, ok := c[name]
+	return !ok || enabled
+}
+
+// Validate отклоняет неизвестные флаги, чтобы опечатка в имени не оставляла возможность включённой
+func (c Config) Validate() []validator.FieldError {
+	known := NewConfig()
+	names := make([]string, 0, len(c))
+	for name := range c {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	v := validator.New()
+	for _, name := range names {
+		if _, ok := known[name]; !ok {
+			v.AddError(name, validator.CodeUnsupportedType, fmt.Sprintf("unknown feature flag %q", name))
+		}
+	}
+	return v.GetErrors()
+}
+
+// Middleware отвечает 404 на запросы к выключенной возможности. Флаги читаются на каждый запрос,
+// поэтому перезагрузка конфигурации действует сразу
+func Middleware(name string, current func() Config) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !current().Enabled(name) {
+				httpHelpers.RespondError(w, r, http.StatusNotFound, ErrorDisabled)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package graphqlHandlers
+
+import (
+	"awesomeProject1/internal/service"
+	"awesomeProject1/pkg/logger"
+	"awesomeProject1/pkg/validator"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Коды ошибок в extensions.code ответа GraphQL
+var errorCodes = map[service.ErrorKind]string{
+	service.KindInternal:      "INTERNAL",
+	service.KindInvalid:       "BAD_USER_INPUT",
+	service.KindNotFound:      "NOT_FOUND",
+	service.KindConflict:      "CONFLICT",
+	service.KindPrecondition:  "PRECONDITION_FAILED",
+	service.KindUnprocessable: "UNPROCESSABLE",
+	service.KindForbidden:     "FORBIDDEN",
+	service.KindUnauthorized:  "UNAUTHENTICATED",
+}
+
+// gqlError — ошибка резолвера, которую graphql-go выводит с полем extensions
+type gqlError struct {
+	id      string
+	code    string
+	message string
+	errors  []validator.FieldError
+}
+
+func (e *gqlError) Error() string {
+	return e.message
+}
+
+func (e *gqlError) Extensions() map[string]interface{} {
+	extensions := map[string]interface{}{
+		"code": e.code,
+		"id":   e.id,
+	}
+	if len(e.errors) > 0 {
+		extensions["errors"] = e.errors
+	}
+	return extensions
+}
+
+// toError конвертирует ошибку сервиса и логирует её так же, как это делают HTTP и gRPC обработчики
+func toError(ctx context.Context, resolver string, sErr *service.Error) *gqlError {
+	code, ok := errorCodes[sErr.Kind]
+	if !ok {
+		code = errorCodes[service.KindInternal]
+	}
+
+	e := &gqlError{
+		id:      uuid.NewString(),
+		code:    code,
+		message: sErr.Message,
+		errors:  sErr.Errors,
+	}
+
+	log := logger.FromContext(ctx).WithFields(logger.Fields{
+		"error_id": e.id,
+		"resolver": resolver,
+		"code":     code,
+	})
+	if sErr.Err != nil {
+		log = log.WithError(sErr.Err)
+	}
+
+	if sErr.Kind == service.KindInternal {
+		log.Error("GraphQLError -> " + sErr.Message)
+	} else {
+		log.Warn("GraphQLError -> " + sErr.Message)
+	}
+
+	return e
+}
+
+func validationError(ctx context.Context, resolver string, errors []validator.FieldError) *gqlError {
+	return toError(ctx, resolver, service.NewValidationError(errors))
+}
+
+func invalidArgument(ctx context.Context, resolver, field, code, message string) *gqlError {
+	return validationError(ctx, resolver, []validator.FieldError{{Field: field, Code: code, Message: message}})
+}
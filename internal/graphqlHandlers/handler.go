@@ -0,0 +1,105 @@
+package graphqlHandlers
+
+import (
+	"awesomeProject1/internal/pagination"
+	"awesomeProject1/internal/service"
+	"awesomeProject1/pkg/httpHelpers"
+	"awesomeProject1/pkg/logger"
+	_ "embed"
+	"encoding/json"
+	"net/http"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+const (
+	maxQueryDepth   = 10
+	maxQueryLength  = 10_000
+	maxParallelism  = 20
+	maxRequestBytes = 1 << 20
+)
+
+//go:embed schema.graphql
+var schemaString string
+
+type Handler struct {
+	schema  *graphql.Schema
+	service service.ISubscriptionService
+}
+
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+func NewHandler(service service.ISubscriptionService, pagination func() *pagination.Config) *Handler {
+	schema := graphql.MustParseSchema(schemaString, NewResolver(service, pagination),
+		graphql.UseStringDescriptions(),
+		graphql.MaxDepth(maxQueryDepth),
+		graphql.MaxQueryLength(maxQueryLength),
+		graphql.MaxParallelism(maxParallelism),
+	)
+
+	return &Handler{schema: schema, service: service}
+}
+
+// ServeHTTP
+// @Summary      GraphQL запрос
+// @Description  Выполняет GraphQL запрос. Схема: subscription, subscriptions, user, total, monthlyTotals. GET принимает query, operationName и variables (JSON) в параметрах строки
+// @Tags         graphql
+// @Accept       json
+// @Produce      json
+// @Param        request  body  object  true  "{query, operationName, variables}"
+// @Success      200  {object}  object  "{data, errors}"
+// @Failure      400  {object}  httpHelpers.ErrorMessage
+// @Failure      401  {object}  httpHelpers.ErrorMessage
+// @Failure      429  {object}  httpHelpers.ErrorMessage
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /graphql [post]
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if req.Query == "" {
+		httpHelpers.RespondError(w, r, http.StatusBadRequest, "Query is required")
+		return
+	}
+
+	// Загрузчики создаются на каждый запрос, чтобы пакеты не смешивались между клиентами
+	ctx := withLoaders(r.Context(), newLoaders(h.service))
+	response := h.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger.FromContext(r.Context()).WithError(err).Error("GraphQL -> Encode")
+	}
+}
+
+func decodeRequest(w http.ResponseWriter, r *http.Request) (*request, bool) {
+	req := &request{}
+
+	if r.Method == http.MethodGet {
+		query := r.URL.Query()
+		req.Query = query.Get("query")
+		req.OperationName = query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				httpHelpers.RespondError(w, r, http.StatusBadRequest, httpHelpers.ErrorParse)
+				return nil, false
+			}
+		}
+		return req, true
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBytes)
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		httpHelpers.RespondError(w, r, http.StatusBadRequest, httpHelpers.ErrorParse)
+		return nil, false
+	}
+
+	return req, true
+}
@@ -0,0 +1,151 @@
+package graphqlHandlers
+
+import (
+	"awesomeProject1/internal/dto"
+	"awesomeProject1/internal/service"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	loaderWait         = 2 * time.Millisecond
+	loaderMaxBatchSize = 500
+	// userLoaderMaxBatchSize — у пользователя может быть много подписок, поэтому пакет пользователей меньше,
+	// чтобы один запрос FindByUserIds не читал подписки сотен пользователей разом
+	userLoaderMaxBatchSize = 50
+)
+
+// batchLoader собирает ключи, запрошенные параллельными резолверами за короткое окно,
+// и загружает их одним вызовом fetch. Живёт в рамках одного GraphQL запроса
+type batchLoader[K comparable, V any] struct {
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+	// maxBatch — пакет отправляется сразу, как только в нём набралось столько ключей
+	maxBatch int
+
+	mu    sync.Mutex
+	batch *loaderBatch[K, V]
+}
+
+type loaderBatch[K comparable, V any] struct {
+	keys    []K
+	seen    map[K]bool
+	done    chan struct{}
+	results map[K]V
+	err     error
+}
+
+func newBatchLoader[K comparable, V any](maxBatch int, fetch func(ctx context.Context, keys []K) (map[K]V, error)) *batchLoader[K, V] {
+	return &batchLoader[K, V]{fetch: fetch, maxBatch: maxBatch}
+}
+
+// Load возвращает значение по ключу. Отсутствующий в результате ключ даёт нулевое значение
+func (l *batchLoader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	b := l.batch
+	if b == nil {
+		b = &loaderBatch[K, V]{seen: make(map[K]bool), done: make(chan struct{})}
+		l.batch = b
+		time.AfterFunc(loaderWait, func() { l.dispatch(ctx, b) })
+	}
+	if !b.seen[key] {
+		b.seen[key] = true
+		b.keys = append(b.keys, key)
+	}
+	if len(b.keys) >= l.maxBatch {
+		go l.dispatch(ctx, b)
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+
+	return b.results[key], b.err
+}
+
+func (l *batchLoader[K, V]) dispatch(ctx context.Context, b *loaderBatch[K, V]) {
+	l.mu.Lock()
+	if l.batch != b {
+		// Пакет уже отправлен по размеру
+		l.mu.Unlock()
+		return
+	}
+	l.batch = nil
+	l.mu.Unlock()
+
+	b.results, b.err = l.fetch(ctx, b.keys)
+	close(b.done)
+}
+
+type userTotalKey struct {
+	userId      uuid.UUID
+	start       time.Time
+	end         time.Time
+	serviceName string
+}
+
+// loaders — набор загрузчиков одного запроса
+type loaders struct {
+	subscriptionById    *batchLoader[uuid.UUID, *dto.SubscriptionResponse]
+	subscriptionsByUser *batchLoader[uuid.UUID, []*dto.SubscriptionResponse]
+	userTotal           *batchLoader[userTotalKey, int]
+}
+
+type loadersKey struct{}
+
+func newLoaders(s service.ISubscriptionService) *loaders {
+	return &loaders{
+		subscriptionById: newBatchLoader(loaderMaxBatchSize, func(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*dto.SubscriptionResponse, error) {
+			items, sErr := s.GetByIds(ctx, ids)
+			if sErr != nil {
+				return nil, toError(ctx, "subscriptionById", sErr)
+			}
+			return items, nil
+		}),
+		subscriptionsByUser: newBatchLoader(userLoaderMaxBatchSize, func(ctx context.Context, userIds []uuid.UUID) (map[uuid.UUID][]*dto.SubscriptionResponse, error) {
+			items, sErr := s.GetByUserIds(ctx, userIds)
+			if sErr != nil {
+				return nil, toError(ctx, "subscriptionsByUser", sErr)
+			}
+			return items, nil
+		}),
+		userTotal: newBatchLoader(loaderMaxBatchSize, func(ctx context.Context, keys []userTotalKey) (map[userTotalKey]int, error) {
+			// Один запрос на каждый уникальный период и сервис
+			groups := make(map[userTotalKey][]uuid.UUID)
+			for _, key := range keys {
+				group := key
+				group.userId = uuid.Nil
+				groups[group] = append(groups[group], key.userId)
+			}
+
+			result := make(map[userTotalKey]int, len(keys))
+			for group, userIds := range groups {
+				req := dto.NewGetTotalSumRequest(group.start, group.end, "", group.serviceName)
+				totals, sErr := s.GetTotalsByUserIds(ctx, req, userIds)
+				if sErr != nil {
+					return nil, toError(ctx, "userTotal", sErr)
+				}
+				for userId, total := range totals {
+					key := group
+					key.userId = userId
+					result[key] = total
+				}
+			}
+			return result, nil
+		}),
+	}
+}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFromContext(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
@@ -0,0 +1,270 @@
+package graphqlHandlers
+
+import (
+	"awesomeProject1/internal/dto"
+	"awesomeProject1/internal/pagination"
+	"awesomeProject1/internal/service"
+	"awesomeProject1/pkg/validator"
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/graph-gophers/graphql-go"
+)
+
+// Resolver — корневой резолвер схемы, вся работа с данными идёт через ISubscriptionService.
+// Поля Query вынесены в queryResolver: graphql-go считает метод Subscription корня
+// резолвером операций subscription и не даёт объявить так одноимённое поле
+type Resolver struct {
+	query *queryResolver
+}
+
+func NewResolver(service service.ISubscriptionService, pagination func() *pagination.Config) *Resolver {
+	return &Resolver{query: &queryResolver{service: service, pagination: pagination}}
+}
+
+func (r *Resolver) Query() *queryResolver {
+	return r.query
+}
+
+type queryResolver struct {
+	service    service.ISubscriptionService
+	pagination func() *pagination.Config
+}
+
+type subscriptionFilterInput struct {
+	UserId      *graphql.ID
+	ServiceName *string
+}
+
+func (r *queryResolver) Subscription(ctx context.Context, args struct{ ID graphql.ID }) (*subscriptionResolver, error) {
+	id, err := parseId(ctx, "subscription", "id", args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := loadersFromContext(ctx).subscriptionById.Load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if item == nil {
+		return nil, nil
+	}
+
+	return &subscriptionResolver{item: item}, nil
+}
+
+func (r *queryResolver) Subscriptions(ctx context.Context, args struct {
+	Filter *subscriptionFilterInput
+	Offset int32
+	Limit  *int32
+}) (*connectionResolver, error) {
+	filter, err := toFilter(ctx, "subscriptions", args.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if args.Offset < 0 {
+		return nil, invalidArgument(ctx, "subscriptions", "offset", validator.CodeTooSmall, "[offset] - Should be greater or equal 0")
+	}
+	config := r.pagination()
+	limit := config.DefaultLimit
+	if args.Limit != nil {
+		limit = int(*args.Limit)
+	}
+	if limit <= 0 || limit > config.MaxLimit {
+		return nil, invalidArgument(ctx, "subscriptions", "limit", validator.CodeInvalidRange, fmt.Sprintf("[limit] - Should be between 1 and %d", config.MaxLimit))
+	}
+
+	result, sErr := r.service.GetAll(ctx, filter, int(args.Offset), limit)
+	if sErr != nil {
+		return nil, toError(ctx, "subscriptions", sErr)
+	}
+
+	return &connectionResolver{result: result}, nil
+}
+
+func (r *queryResolver) User(ctx context.Context, args struct{ ID graphql.ID }) (*userResolver, error) {
+	id, err := parseId(ctx, "user", "id", args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &userResolver{id: id}, nil
+}
+
+func (r *queryResolver) Total(ctx context.Context, args struct {
+	Start  string
+	End    string
+	Filter *subscriptionFilterInput
+}) (int32, error) {
+	req, err := toTotalRequest(ctx, "total", args.Start, args.End, args.Filter)
+	if err != nil {
+		return 0, err
+	}
+
+	sum, sErr := r.service.GetTotalSum(ctx, req)
+	if sErr != nil {
+		return 0, toError(ctx, "total", sErr)
+	}
+
+	return int32(sum), nil
+}
+
+func (r *queryResolver) MonthlyTotals(ctx context.Context, args struct {
+	Start  string
+	End    string
+	Filter *subscriptionFilterInput
+}) ([]*monthlyTotalResolver, error) {
+	req, err := toTotalRequest(ctx, "monthlyTotals", args.Start, args.End, args.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	totals, sErr := r.service.GetMonthlyTotals(ctx, req)
+	if sErr != nil {
+		return nil, toError(ctx, "monthlyTotals", sErr)
+	}
+
+	result := make([]*monthlyTotalResolver, len(totals))
+	for i, item := range totals {
+		result[i] = &monthlyTotalResolver{item: item}
+	}
+
+	return result, nil
+}
+
+type connectionResolver struct {
+	result *dto.SubscriptionListResponse
+}
+
+func (r *connectionResolver) Total() int32  { return int32(r.result.Total) }
+func (r *connectionResolver) Offset() int32 { return int32(r.result.Offset) }
+func (r *connectionResolver) Limit() int32  { return int32(r.result.Limit) }
+
+func (r *connectionResolver) Items() []*subscriptionResolver {
+	return toResolvers(r.result.Subscriptions)
+}
+
+type subscriptionResolver struct {
+	item *dto.SubscriptionResponse
+}
+
+func (r *subscriptionResolver) ID() graphql.ID          { return graphql.ID(r.item.ID.String()) }
+func (r *subscriptionResolver) ServiceName() string     { return r.item.ServiceName }
+func (r *subscriptionResolver) Price() int32            { return int32(r.item.Price) }
+func (r *subscriptionResolver) UserId() graphql.ID      { return graphql.ID(r.item.UserID.String()) }
+func (r *subscriptionResolver) StartDate() string       { return r.item.StartDate }
+func (r *subscriptionResolver) EndDate() *string        { return r.item.EndDate }
+func (r *subscriptionResolver) CreatedAt() graphql.Time { return graphql.Time{Time: r.item.CreatedAt} }
+func (r *subscriptionResolver) UpdatedAt() graphql.Time { return graphql.Time{Time: r.item.UpdatedAt} }
+func (r *subscriptionResolver) Version() int32          { return int32(r.item.Version) }
+func (r *subscriptionResolver) Etag() string            { return r.item.ETag }
+func (r *subscriptionResolver) User() *userResolver     { return &userResolver{id: r.item.UserID} }
+
+// userResolver — пользователь существует только как user_id подписок, отдельной таблицы нет
+type userResolver struct {
+	id uuid.UUID
+}
+
+func (r *userResolver) ID() graphql.ID { return graphql.ID(r.id.String()) }
+
+func (r *userResolver) Subscriptions(ctx context.Context) ([]*subscriptionResolver, error) {
+	items, err := loadersFromContext(ctx).subscriptionsByUser.Load(ctx, r.id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toResolvers(items), nil
+}
+
+func (r *userResolver) Total(ctx context.Context, args struct {
+	Start       string
+	End         string
+	ServiceName *string
+}) (int32, error) {
+	req, err := toTotalRequest(ctx, "user.total", args.Start, args.End, &subscriptionFilterInput{ServiceName: args.ServiceName})
+	if err != nil {
+		return 0, err
+	}
+
+	key := userTotalKey{
+		userId:      r.id,
+		start:       req.Start,
+		end:         req.End,
+		serviceName: req.ServiceName,
+	}
+
+	total, err := loadersFromContext(ctx).userTotal.Load(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+
+	return int32(total), nil
+}
+
+type monthlyTotalResolver struct {
+	item *dto.MonthlyTotal
+}
+
+func (r *monthlyTotalResolver) Month() string { return r.item.Month }
+func (r *monthlyTotalResolver) Total() int32  { return int32(r.item.Total) }
+
+func toResolvers(items []*dto.SubscriptionResponse) []*subscriptionResolver {
+	result := make([]*subscriptionResolver, len(items))
+	for i, item := range items {
+		result[i] = &subscriptionResolver{item: item}
+	}
+	return result
+}
+
+func toFilter(ctx context.Context, resolver string, input *subscriptionFilterInput) (*dto.SubscriptionFilter, error) {
+	filter := &dto.SubscriptionFilter{}
+	if input != nil {
+		if input.UserId != nil {
+			filter.UserId = string(*input.UserId)
+		}
+		if input.ServiceName != nil {
+			filter.ServiceName = *input.ServiceName
+		}
+	}
+
+	if ok, errors := filter.IsValid(); !ok {
+		return nil, validationError(ctx, resolver, errors)
+	}
+
+	return filter, nil
+}
+
+func toTotalRequest(ctx context.Context, resolver, start, end string, input *subscriptionFilterInput) (*dto.GetTotalSumRequest, error) {
+	startDate, err := dto.ParseMonthYear(start)
+	if err != nil {
+		return nil, invalidArgument(ctx, resolver, "start", validator.CodeInvalidFormat, "Please provide start param in next format: mm-yyyy")
+	}
+
+	endDate, err := dto.ParseMonthYear(end)
+	if err != nil {
+		return nil, invalidArgument(ctx, resolver, "end", validator.CodeInvalidFormat, "Please provide end param in next format: mm-yyyy")
+	}
+
+	filter, err := toFilter(ctx, resolver, input)
+	if err != nil {
+		return nil, err
+	}
+
+	req := dto.NewGetTotalSumRequest(startDate, endDate, filter.UserId, filter.ServiceName)
+	if ok, errors := req.IsValid(); !ok {
+		return nil, validationError(ctx, resolver, errors)
+	}
+
+	return req, nil
+}
+
+func parseId(ctx context.Context, resolver, field string, id graphql.ID) (uuid.UUID, error) {
+	parsed, err := uuid.Parse(string(id))
+	if err != nil {
+		return uuid.Nil, invalidArgument(ctx, resolver, field, validator.CodeInvalidUuid, fmt.Sprintf("Cannot parse provided id. Expected correct uuid. Got: %s", id))
+	}
+	return parsed, nil
+}
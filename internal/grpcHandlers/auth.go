@@ -0,0 +1,77 @@
+package grpcHandlers
+
+import (
+	"awesomeProject1/internal/auth"
+	"awesomeProject1/internal/service"
+	"awesomeProject1/pkg/logger"
+	"context"
+	"errors"
+	"net/http"
+	"net/textproto"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// reflectionPrefix — методы reflection доступны без аутентификации, они описывают только схему API
+const reflectionPrefix = "/grpc.reflection."
+
+// AuthInterceptors проверяют x-api-key или authorization: Bearer в метаданных теми же способами, что и HTTP API
+func AuthInterceptors(chain auth.Chain) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+	unary := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticate(ctx, chain, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+
+	stream := func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), chain, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+
+	return unary, stream
+}
+
+func authenticate(ctx context.Context, chain auth.Chain, method string) (context.Context, error) {
+	if strings.HasPrefix(method, reflectionPrefix) {
+		return ctx, nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	header := make(http.Header, len(md))
+	for key, values := range md {
+		header[textproto.CanonicalMIMEHeaderKey(key)] = values
+	}
+
+	principal, err := chain.Authenticate(ctx, header)
+	switch {
+	case err == nil:
+		return auth.Attach(ctx, principal), nil
+	case errors.Is(err, auth.ErrNoCredentials):
+		return nil, status.Error(codes.Unauthenticated, auth.ErrorUnauthenticated)
+	case errors.Is(err, auth.ErrInvalidCredentials):
+		logger.FromContext(ctx).WithError(err).WithFields(logger.Fields{"method": method}).Warn("GrpcAuth")
+		return nil, status.Error(codes.Unauthenticated, auth.ErrorInvalidCredentials)
+	default:
+		logger.FromContext(ctx).WithError(err).WithFields(logger.Fields{"method": method}).Error("GrpcAuth")
+		return nil, status.Error(codes.Internal, service.ErrorInternal)
+	}
+}
+
+// authenticatedStream подменяет контекст потока на контекст с субъектом и тенантом
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
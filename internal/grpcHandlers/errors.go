@@ -0,0 +1,80 @@
+package grpcHandlers
+
+import (
+	"awesomeProject1/internal/service"
+	"awesomeProject1/pkg/logger"
+	"awesomeProject1/pkg/validator"
+	"context"
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// grpcCodes — коды gRPC для видов ошибок сервиса
+var grpcCodes = map[service.ErrorKind]codes.Code{
+	service.KindInternal:      codes.Internal,
+	service.KindInvalid:       codes.InvalidArgument,
+	service.KindNotFound:      codes.NotFound,
+	service.KindConflict:      codes.AlreadyExists,
+	service.KindPrecondition:  codes.Aborted,
+	service.KindUnprocessable: codes.FailedPrecondition,
+	service.KindForbidden:     codes.PermissionDenied,
+	service.KindUnauthorized:  codes.Unauthenticated,
+}
+
+// toStatus переводит ошибку сервиса в gRPC статус. Исходная ошибка пишется в лог под id,
+// который передаётся клиенту в ErrorInfo
+func toStatus(ctx context.Context, method string, sErr *service.Error) error {
+	code, ok := grpcCodes[sErr.Kind]
+	if !ok {
+		code = codes.Internal
+	}
+
+	id := uuid.New()
+	if sErr.Err != nil {
+		log := logger.FromContext(ctx).WithError(sErr.Err).WithFields(logger.Fields{
+			"error_id": id.String(),
+			"method":   method,
+			"code":     code.String(),
+		})
+		if code == codes.Internal {
+			log.Error("GrpcError -> " + sErr.Message)
+		} else {
+			log.Warn("GrpcError -> " + sErr.Message)
+		}
+	}
+
+	return newStatus(code, sErr.Message, id, sErr.Errors)
+}
+
+// validationStatus возвращает InvalidArgument с подробностями по полям
+func validationStatus(errors []validator.FieldError) error {
+	return newStatus(codes.InvalidArgument, service.ErrorValidation, uuid.New(), errors)
+}
+
+func invalidArgument(field, code, message string) error {
+	return validationStatus([]validator.FieldError{{Field: field, Code: code, Message: message}})
+}
+
+func newStatus(code codes.Code, message string, id uuid.UUID, errors []validator.FieldError) error {
+	details := []protoadapt.MessageV1{
+		&errdetails.ErrorInfo{Reason: code.String(), Metadata: map[string]string{"id": id.String()}},
+	}
+
+	if len(errors) > 0 {
+		violations := make([]*errdetails.BadRequest_FieldViolation, len(errors))
+		for i, fieldErr := range errors {
+			violations[i] = &errdetails.BadRequest_FieldViolation{Field: fieldErr.Field, Description: fieldErr.Message}
+		}
+		details = append(details, &errdetails.BadRequest{FieldViolations: violations})
+	}
+
+	st := status.New(code, message)
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		st = withDetails
+	}
+
+	return st.Err()
+}
@@ -0,0 +1,117 @@
+package grpcHandlers
+
+import (
+	"awesomeProject1/internal/auth"
+	"awesomeProject1/internal/ratelimit"
+	"awesomeProject1/pkg/logger"
+	"context"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// RateLimitInterceptors ограничивают частоту вызовов так же, как HTTP API. Маршрут — полное имя метода,
+// заголовки RateLimit-* уходят в метаданные ответа. Ставятся после AuthInterceptors
+func RateLimitInterceptors(limiter *ratelimit.Limiter) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+	unary := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := rateLimit(ctx, limiter, info.FullMethod, func(md metadata.MD) error { return grpc.SetHeader(ctx, md) }); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+
+	stream := func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := rateLimit(ss.Context(), limiter, info.FullMethod, ss.SetHeader); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+
+	return unary, stream
+}
+
+// IPRateLimitInterceptors — RateLimitIP для gRPC: ведро rate_limit.per_ip по адресу клиента.
+// Ставятся перед AuthInterceptors, метаданные RateLimit-* отдаются только при отказе
+func IPRateLimitInterceptors(limiter *ratelimit.Limiter) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+	unary := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := rateLimitIP(ctx, limiter, info.FullMethod, func(md metadata.MD) error { return grpc.SetHeader(ctx, md) }); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+
+	stream := func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := rateLimitIP(ss.Context(), limiter, info.FullMethod, ss.SetHeader); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+
+	return unary, stream
+}
+
+func rateLimitIP(ctx context.Context, limiter *ratelimit.Limiter, method string, setHeader func(metadata.MD) error) error {
+	if strings.HasPrefix(method, reflectionPrefix) {
+		return nil
+	}
+
+	result, err := limiter.AllowIP(ctx, peerIP(ctx))
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).WithFields(logger.Fields{"method": method}).Warn("GrpcRateLimitIP")
+		return nil
+	}
+	if result == nil || result.Allowed {
+		return nil
+	}
+
+	md := metadata.MD{}
+	for name, value := range result.Headers() {
+		md.Set(name, value)
+	}
+	_ = setHeader(md)
+	return status.Error(codes.ResourceExhausted, result.Message())
+}
+
+func rateLimit(ctx context.Context, limiter *ratelimit.Limiter, method string, setHeader func(metadata.MD) error) error {
+	if strings.HasPrefix(method, reflectionPrefix) {
+		return nil
+	}
+
+	principal, _ := auth.FromContext(ctx)
+	result, err := limiter.Allow(ctx, "", method, ratelimit.ClientKey(ctx, principal, peerIP(ctx)))
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).WithFields(logger.Fields{"method": method}).Warn("GrpcRateLimit")
+		return nil
+	}
+	if result == nil {
+		return nil
+	}
+
+	md := metadata.MD{}
+	for name, value := range result.Headers() {
+		md.Set(name, value)
+	}
+	_ = setHeader(md)
+
+	if !result.Allowed {
+		return status.Error(codes.ResourceExhausted, result.Message())
+	}
+	return nil
+}
+
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
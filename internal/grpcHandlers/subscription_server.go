@@ -0,0 +1,338 @@
+package grpcHandlers
+
+import (
+	"awesomeProject1/internal/dto"
+	"awesomeProject1/internal/pagination"
+	"awesomeProject1/internal/service"
+	subscriptionv1 "awesomeProject1/pkg/proto/subscription/v1"
+	"awesomeProject1/pkg/validator"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultBatchSize   = 100
+	maxStreamBatchSize = 1000
+)
+
+type SubscriptionServer struct {
+	subscriptionv1.UnimplementedSubscriptionServiceServer
+	service    service.ISubscriptionService
+	pagination func() *pagination.Config
+}
+
+func NewSubscriptionServer(service service.ISubscriptionService, pagination func() *pagination.Config) *SubscriptionServer {
+	return &SubscriptionServer{service: service, pagination: pagination}
+}
+
+func (s *SubscriptionServer) Create(ctx context.Context, req *subscriptionv1.CreateSubscriptionRequest) (*subscriptionv1.Subscription, error) {
+	createReq := dto.CreateSubscriptionRequest{
+		ServiceName: req.GetServiceName(),
+		Price:       int(req.GetPrice()),
+		UserID:      req.GetUserId(),
+		StartDate:   req.GetStartDate(),
+		EndDate:     req.GetEndDate(),
+	}
+
+	if ok, errors := createReq.IsValid(); !ok {
+		return nil, validationStatus(errors)
+	}
+
+	sub, err := createReq.ToSubscription()
+	if err != nil {
+		return nil, toStatus(ctx, "Create", &service.Error{Kind: service.KindInvalid, Message: err.Error(), Err: err})
+	}
+
+	created, sErr := s.service.Create(ctx, sub)
+	if sErr != nil {
+		return nil, toStatus(ctx, "Create", sErr)
+	}
+
+	return toProto(created), nil
+}
+
+func (s *SubscriptionServer) Update(ctx context.Context, req *subscriptionv1.UpdateSubscriptionRequest) (*subscriptionv1.Subscription, error) {
+	updateReq := dto.UpdateSubscriptionRequest{
+		ID:          req.GetId(),
+		ServiceName: req.ServiceName,
+		UserID:      req.UserId,
+		StartDate:   req.StartDate,
+		EndDate:     req.EndDate,
+	}
+
+	if req.Price != nil {
+		price := int(req.GetPrice())
+		updateReq.Price = &price
+	}
+
+	if ok, errors := updateReq.IsValid(); !ok {
+		return nil, validationStatus(errors)
+	}
+
+	updateData, err := updateReq.ToUpdateData()
+	if err != nil {
+		return nil, toStatus(ctx, "Update", &service.Error{Kind: service.KindInvalid, Message: err.Error(), Err: err})
+	}
+
+	if req.GetIfMatch() != "" {
+		updateData.IfMatch = []string{req.GetIfMatch()}
+	}
+
+	if sErr := s.service.Update(ctx, updateData); sErr != nil {
+		return nil, toStatus(ctx, "Update", sErr)
+	}
+
+	item, sErr := s.service.GetById(ctx, updateData.ID)
+	if sErr != nil {
+		return nil, toStatus(ctx, "Update", sErr)
+	}
+
+	return toProto(item), nil
+}
+
+func (s *SubscriptionServer) Delete(ctx context.Context, req *subscriptionv1.DeleteSubscriptionRequest) (*subscriptionv1.DeleteSubscriptionResponse, error) {
+	id, err := parseId(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	var ifMatch []string
+	if req.GetIfMatch() != "" {
+		ifMatch = []string{req.GetIfMatch()}
+	}
+
+	if sErr := s.service.Delete(ctx, id, ifMatch); sErr != nil {
+		return nil, toStatus(ctx, "Delete", sErr)
+	}
+
+	return &subscriptionv1.DeleteSubscriptionResponse{}, nil
+}
+
+func (s *SubscriptionServer) GetById(ctx context.Context, req *subscriptionv1.GetSubscriptionRequest) (*subscriptionv1.Subscription, error) {
+	id, err := parseId(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	item, sErr := s.service.GetById(ctx, id)
+	if sErr != nil {
+		return nil, toStatus(ctx, "GetById", sErr)
+	}
+
+	return toProto(item), nil
+}
+
+func (s *SubscriptionServer) GetAll(ctx context.Context, req *subscriptionv1.ListSubscriptionsRequest) (*subscriptionv1.ListSubscriptionsResponse, error) {
+	if req.GetOffset() < 0 {
+		return nil, invalidArgument("offset", validator.CodeTooSmall, fmt.Sprintf("Offset must not be negative. Got: %d", req.GetOffset()))
+	}
+	limit := s.pagination().Limit(int(req.GetLimit()))
+
+	filter, err := toFilter(req.GetFilter())
+	if err != nil {
+		return nil, err
+	}
+
+	result, sErr := s.service.GetAll(ctx, filter, int(req.GetOffset()), limit)
+	if sErr != nil {
+		return nil, toStatus(ctx, "GetAll", sErr)
+	}
+
+	response := &subscriptionv1.ListSubscriptionsResponse{
+		Total:         int32(result.Total),
+		Offset:        int32(result.Offset),
+		Limit:         int32(result.Limit),
+		Subscriptions: make([]*subscriptionv1.Subscription, len(result.Subscriptions)),
+	}
+	for i, item := range result.Subscriptions {
+		response.Subscriptions[i] = toProto(item)
+	}
+
+	return response, nil
+}
+
+func (s *SubscriptionServer) GetTotalSum(ctx context.Context, req *subscriptionv1.GetTotalSumRequest) (*subscriptionv1.GetTotalSumResponse, error) {
+	start, err := dto.ParseMonthYear(req.GetStart())
+	if err != nil {
+		return nil, invalidArgument("start", validator.CodeInvalidFormat, "Please provide start param in next format: mm-yyyy")
+	}
+
+	end, err := dto.ParseMonthYear(req.GetEnd())
+	if err != nil {
+		return nil, invalidArgument("end", validator.CodeInvalidFormat, "Please provide end param in next format: mm-yyyy")
+	}
+
+	totalReq := dto.NewGetTotalSumRequest(start, end, req.GetUserId(), req.GetServiceName())
+	if ok, errors := totalReq.IsValid(); !ok {
+		return nil, validationStatus(errors)
+	}
+
+	sum, sErr := s.service.GetTotalSum(ctx, totalReq)
+	if sErr != nil {
+		return nil, toStatus(ctx, "GetTotalSum", sErr)
+	}
+
+	return &subscriptionv1.GetTotalSumResponse{Total: int64(sum)}, nil
+}
+
+func (s *SubscriptionServer) StreamAll(req *subscriptionv1.StreamSubscriptionsRequest, stream subscriptionv1.SubscriptionService_StreamAllServer) error {
+	filter, err := toFilter(req.GetFilter())
+	if err != nil {
+		return err
+	}
+
+	return s.forEachBatch(stream.Context(), "StreamAll", filter, int(req.GetBatchSize()), func(items []*dto.SubscriptionResponse) error {
+		for _, item := range items {
+			if err := stream.Send(toProto(item)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *SubscriptionServer) Export(req *subscriptionv1.ExportSubscriptionsRequest, stream subscriptionv1.SubscriptionService_ExportServer) error {
+	filter, err := toFilter(req.GetFilter())
+	if err != nil {
+		return err
+	}
+
+	var encode func(buf *bytes.Buffer, items []*dto.SubscriptionResponse) error
+
+	switch req.GetFormat() {
+	case subscriptionv1.ExportFormat_EXPORT_FORMAT_UNSPECIFIED, subscriptionv1.ExportFormat_EXPORT_FORMAT_CSV:
+		header := &bytes.Buffer{}
+		writer := csv.NewWriter(header)
+		writer.Write(csvHeader)
+		writer.Flush()
+		if err := stream.Send(&subscriptionv1.ExportChunk{Data: header.Bytes()}); err != nil {
+			return err
+		}
+		encode = encodeCsv
+	case subscriptionv1.ExportFormat_EXPORT_FORMAT_JSONL:
+		encode = encodeJsonLines
+	default:
+		return invalidArgument("format", validator.CodeInvalidFormat, fmt.Sprintf("Unsupported export format: %s", req.GetFormat()))
+	}
+
+	return s.forEachBatch(stream.Context(), "Export", filter, defaultBatchSize, func(items []*dto.SubscriptionResponse) error {
+		buf := &bytes.Buffer{}
+		if err := encode(buf, items); err != nil {
+			return err
+		}
+		return stream.Send(&subscriptionv1.ExportChunk{Data: buf.Bytes()})
+	})
+}
+
+// forEachBatch постранично читает все подписки через сервис и передаёт каждую страницу в fn.
+// Страницы идут по курсору, поэтому подписки, созданные или удалённые во время обхода, не сдвигают следующие страницы
+// и не приводят к повторам или пропускам
+func (s *SubscriptionServer) forEachBatch(ctx context.Context, method string, filter *dto.SubscriptionFilter, batchSize int, fn func(items []*dto.SubscriptionResponse) error) error {
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	if batchSize > maxStreamBatchSize {
+		batchSize = maxStreamBatchSize
+	}
+
+	var after *dto.SubscriptionCursor
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		items, sErr := s.service.GetAfter(ctx, filter, after, batchSize)
+		if sErr != nil {
+			return toStatus(ctx, method, sErr)
+		}
+
+		if len(items) > 0 {
+			if err := fn(items); err != nil {
+				return err
+			}
+			after = dto.CursorOf(items[len(items)-1])
+		}
+
+		if len(items) < batchSize {
+			return nil
+		}
+	}
+}
+
+var csvHeader = []string{"id", "service_name", "price", "user_id", "start_date", "end_date", "created_at", "updated_at"}
+
+func encodeCsv(buf *bytes.Buffer, items []*dto.SubscriptionResponse) error {
+	writer := csv.NewWriter(buf)
+	for _, item := range items {
+		endDate := ""
+		if item.EndDate != nil {
+			endDate = *item.EndDate
+		}
+		writer.Write([]string{
+			item.ID.String(),
+			item.ServiceName,
+			strconv.Itoa(item.Price),
+			item.UserID.String(),
+			item.StartDate,
+			endDate,
+			item.CreatedAt.Format(time.RFC3339),
+			item.UpdatedAt.Format(time.RFC3339),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func encodeJsonLines(buf *bytes.Buffer, items []*dto.SubscriptionResponse) error {
+	encoder := json.NewEncoder(buf)
+	for _, item := range items {
+		if err := encoder.Encode(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func toFilter(filter *subscriptionv1.SubscriptionFilter) (*dto.SubscriptionFilter, error) {
+	result := &dto.SubscriptionFilter{
+		UserId:      filter.GetUserId(),
+		ServiceName: filter.GetServiceName(),
+	}
+
+	if ok, errors := result.IsValid(); !ok {
+		return nil, validationStatus(errors)
+	}
+
+	return result, nil
+}
+
+func parseId(id string) (uuid.UUID, error) {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, invalidArgument("id", validator.CodeInvalidUuid, fmt.Sprintf("Cannot parse provided id. Expected correct uuid. Got: %s", id))
+	}
+	return parsed, nil
+}
+
+func toProto(item *dto.SubscriptionResponse) *subscriptionv1.Subscription {
+	return &subscriptionv1.Subscription{
+		Id:          item.ID.String(),
+		ServiceName: item.ServiceName,
+		Price:       int64(item.Price),
+		UserId:      item.UserID.String(),
+		StartDate:   item.StartDate,
+		EndDate:     item.EndDate,
+		CreatedAt:   timestamppb.New(item.CreatedAt),
+		UpdatedAt:   timestamppb.New(item.UpdatedAt),
+		Version:     int32(item.Version),
+		Etag:        item.ETag,
+	}
+}
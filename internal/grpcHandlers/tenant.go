@@ -0,0 +1,51 @@
+package grpcHandlers
+
+import (
+	"awesomeProject1/internal/service"
+	"awesomeProject1/internal/tenant"
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// TenantInterceptors определяют тенанта вызова по субъекту и метаданным x-tenant-id так же, как HTTP API.
+// Ставятся после AuthInterceptors
+func TenantInterceptors(tenants service.ITenantService) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+	unary := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := resolveTenant(ctx, tenants, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+
+	stream := func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := resolveTenant(ss.Context(), tenants, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+
+	return unary, stream
+}
+
+func resolveTenant(ctx context.Context, tenants service.ITenantService, method string) (context.Context, error) {
+	if strings.HasPrefix(method, reflectionPrefix) {
+		return ctx, nil
+	}
+
+	var requested string
+	if values := metadata.ValueFromIncomingContext(ctx, strings.ToLower(tenant.Header)); len(values) > 0 {
+		requested = strings.TrimSpace(values[0])
+	}
+
+	id, sErr := tenants.Resolve(ctx, requested)
+	if sErr != nil {
+		return nil, toStatus(ctx, method, sErr)
+	}
+
+	return tenant.Attach(ctx, id), nil
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"awesomeProject1/internal/dto"
+	"awesomeProject1/internal/pagination"
+	"awesomeProject1/internal/service"
+	"awesomeProject1/pkg/httpHelpers"
+	"awesomeProject1/pkg/logger"
+	"encoding/json"
+	"net/http"
+)
+
+// APIKeyHandler управляет ключами API, доступен только администраторам
+type APIKeyHandler struct {
+	service    service.IAPIKeyService
+	pagination func() *pagination.Config
+}
+
+func NewAPIKeyHandler(service service.IAPIKeyService, pagination func() *pagination.Config) *APIKeyHandler {
+	return &APIKeyHandler{service: service, pagination: pagination}
+}
+
+// Create выпускает ключ. Ключ целиком есть только в этом ответе, в базе хранится его хэш.
+//
+// @Summary      Выпустить ключ API
+// @Description  Ключ принадлежит тенанту запроса, с platform: true — платформе, такой ключ выпускает только ключ платформы. Ключ целиком возвращается только в этом ответе
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        X-Tenant-ID  header  string  false  "Тенант запроса для ключа платформы, без него — тенант по умолчанию"
+// @Param        request body dto.CreateAPIKeyRequest true "Данные ключа"
+// @Success      201  {object}  dto.APIKeyResponse
+// @Failure      400  {object}  httpHelpers.ErrorMessage
+// @Failure      401  {object}  httpHelpers.ErrorMessage
+// @Failure      403  {object}  httpHelpers.ErrorMessage
+// @Failure      429  {object}  httpHelpers.ErrorMessage
+// @Failure      500  {object}  httpHelpers.ErrorMessage
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /admin/api-keys [post]
+func (c *APIKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	req := dto.CreateAPIKeyRequest{}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpHelpers.RespondError(w, r, http.StatusBadRequest, httpHelpers.ErrorParse)
+		return
+	}
+
+	if ok, errors := req.IsValid(); !ok {
+		httpHelpers.RespondValidationError(w, r, errors)
+		return
+	}
+
+	created, sErr := c.service.Create(r.Context(), &req)
+	if sErr != nil {
+		respondServiceError(w, r, sErr)
+		return
+	}
+
+	logger.FromContext(r.Context()).WithFields(logger.Fields{"api_key_id": created.ID.String(), "name": created.Name}).Warn("APIKey -> created")
+	httpHelpers.RespondSuccess(w, http.StatusCreated, created)
+}
+
+// GetAll возвращает ключи без самих ключей, только их начало.
+//
+// @Summary      Получить список ключей API
+// @Description  Ключи тенанта запроса, вместо ключа — его начало prefix
+// @Tags         admin
+// @Produce      json
+// @Param        X-Tenant-ID  header  string  false  "Тенант запроса для ключа платформы, без него — тенант по умолчанию"
+// @Param        offset  query  int  false  "Смещение (по умолчанию 0)"  example(0)
+// @Param        limit   query  int  false  "Лимит записей (по умолчанию 10)" example(10)
+// @Success      200  {object}  dto.APIKeyListResponse
+// @Failure      400  {object}  httpHelpers.ErrorMessage
+// @Failure      401  {object}  httpHelpers.ErrorMessage
+// @Failure      403  {object}  httpHelpers.ErrorMessage
+// @Failure      429  {object}  httpHelpers.ErrorMessage
+// @Failure      500  {object}  httpHelpers.ErrorMessage
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /admin/api-keys [get]
+func (c *APIKeyHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	offset, limit := parsePagination(r, c.pagination())
+
+	result, sErr := c.service.GetAll(r.Context(), offset, limit)
+	if sErr != nil {
+		respondServiceError(w, r, sErr)
+		return
+	}
+
+	httpHelpers.RespondSuccess(w, http.StatusOK, result)
+}
+
+// Revoke отзывает ключ.
+//
+// @Summary      Отозвать ключ API
+// @Description  Запросы с отозванным ключом перестают проходить сразу
+// @Tags         admin
+// @Produce      json
+// @Param        X-Tenant-ID  header  string  false  "Тенант запроса для ключа платформы, без него — тенант по умолчанию"
+// @Param        id path string true "ID ключа" example("7b1e4c2a-9f3d-4a8e-b6c5-2d1f0e9a8b7c")
+// @Success      200
+// @Failure      400  {object}  httpHelpers.ErrorMessage
+// @Failure      401  {object}  httpHelpers.ErrorMessage
+// @Failure      403  {object}  httpHelpers.ErrorMessage
+// @Failure      404  {object}  httpHelpers.ErrorMessage
+// @Failure      429  {object}  httpHelpers.ErrorMessage
+// @Failure      500  {object}  httpHelpers.ErrorMessage
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /admin/api-keys/{id} [delete]
+func (c *APIKeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUuidVar(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if sErr := c.service.Revoke(r.Context(), id); sErr != nil {
+		respondServiceError(w, r, sErr)
+		return
+	}
+
+	logger.FromContext(r.Context()).WithFields(logger.Fields{"api_key_id": id.String()}).Warn("APIKey -> revoked")
+	httpHelpers.RespondSuccess(w, http.StatusOK, nil)
+}
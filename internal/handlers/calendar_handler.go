@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"awesomeProject1/internal/dto"
+	"awesomeProject1/internal/service"
+	"awesomeProject1/pkg/httpHelpers"
+	"awesomeProject1/pkg/ical"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type CalendarHandler struct {
+	service service.ICalendarService
+}
+
+func NewCalendarHandler(service service.ICalendarService) *CalendarHandler {
+	return &CalendarHandler{service: service}
+}
+
+// IssueToken выпускает ссылку на календарь пользователя.
+//
+// @Summary      Выпустить ссылку на календарь
+// @Description  Создаёт новый токен для ссылки на iCalendar ленту пользователя. Предыдущая ссылка перестаёт работать
+// @Tags         calendar
+// @Produce      json
+// @Param        id path string true "ID пользователя" example("60601fee-2bf1-4721-ae6f-7636e79a0cba")
+// @Success      201  {object}  dto.CalendarTokenResponse
+// @Failure      400  {object}  httpHelpers.ErrorMessage
+// @Failure      401  {object}  httpHelpers.ErrorMessage
+// @Failure      403  {object}  httpHelpers.ErrorMessage
+// @Failure      429  {object}  httpHelpers.ErrorMessage
+// @Failure      500  {object}  httpHelpers.ErrorMessage
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/v1/users/{id}/calendar-token [post]
+func (c *CalendarHandler) IssueToken(w http.ResponseWriter, r *http.Request) {
+	userId, ok := parseUuidVar(w, r, "id")
+	if !ok {
+		return
+	}
+
+	token, sErr := c.service.IssueToken(r.Context(), userId)
+	if sErr != nil {
+		respondServiceError(w, r, sErr)
+		return
+	}
+
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+
+	feedUrl := url.URL{
+		Scheme:   scheme,
+		Host:     r.Host,
+		Path:     strings.TrimSuffix(r.URL.Path, "/calendar-token") + "/calendar.ics",
+		RawQuery: url.Values{"token": {token}}.Encode(),
+	}
+
+	httpHelpers.RespondSuccess(w, http.StatusCreated, &dto.CalendarTokenResponse{Token: token, URL: feedUrl.String()})
+}
+
+// Feed отдаёт календарь пользователя.
+//
+// @Summary      Календарь списаний и окончаний подписок
+// @Description  iCalendar (RFC 5545) лента: событие на каждое списание по действующим подпискам на 12 месяцев вперёд и на дату окончания подписки. UID событий стабильны, поэтому изменения подписки обновляют события в календаре
+// @Tags         calendar
+// @Produce      text/calendar
+// @Param        id     path   string  true  "ID пользователя" example("60601fee-2bf1-4721-ae6f-7636e79a0cba")
+// @Param        token  query  string  true  "Токен из ссылки на календарь"
+// @Success      200  {string}  string  "VCALENDAR"
+// @Failure      400  {object}  httpHelpers.ErrorMessage
+// @Failure      403  {object}  httpHelpers.ErrorMessage
+// @Failure      429  {object}  httpHelpers.ErrorMessage
+// @Failure      500  {object}  httpHelpers.ErrorMessage
+// @Router       /api/v1/users/{id}/calendar.ics [get]
+func (c *CalendarHandler) Feed(w http.ResponseWriter, r *http.Request) {
+	userId, ok := parseUuidVar(w, r, "id")
+	if !ok {
+		return
+	}
+
+	calendar, sErr := c.service.GetCalendar(r.Context(), userId, r.URL.Query().Get("token"))
+	if sErr != nil {
+		respondServiceError(w, r, sErr)
+		return
+	}
+
+	w.Header().Set("Content-Type", ical.ContentType)
+	w.Header().Set("Content-Disposition", `inline; filename="calendar.ics"`)
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	w.Write(calendar.Encode())
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"awesomeProject1/internal/service"
+	"awesomeProject1/pkg/httpHelpers"
+	"net/http"
+)
+
+// httpStatuses — HTTP статусы для видов ошибок сервиса.
+// Ненайденная подписка исторически отдаётся как 400, чтобы не ломать существующих клиентов
+var httpStatuses = map[service.ErrorKind]int{
+	service.KindInternal:      http.StatusInternalServerError,
+	service.KindInvalid:       http.StatusBadRequest,
+	service.KindNotFound:      http.StatusBadRequest,
+	service.KindConflict:      http.StatusConflict,
+	service.KindPrecondition:  http.StatusPreconditionFailed,
+	service.KindUnprocessable: http.StatusUnprocessableEntity,
+	service.KindForbidden:     http.StatusForbidden,
+	service.KindUnauthorized:  http.StatusUnauthorized,
+}
+
+// respondServiceError переводит ошибку сервиса в HTTP ответ
+func respondServiceError(w http.ResponseWriter, r *http.Request, sErr *service.Error) {
+	status, ok := httpStatuses[sErr.Kind]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+
+	httpHelpers.RespondServiceError(w, r, &httpHelpers.ServiceError{
+		Code:    status,
+		Message: sErr.Message,
+		Err:     sErr.Err,
+		Errors:  sErr.Errors,
+	})
+}
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"awesomeProject1/internal/dto"
+	"awesomeProject1/internal/service"
+	"awesomeProject1/pkg/httpHelpers"
+	"awesomeProject1/pkg/logger"
+	"awesomeProject1/pkg/validator"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	LastEventIdHeader = "Last-Event-ID"
+
+	eventsReplayBatch  = 500
+	eventsHeartbeat    = 15 * time.Second
+	eventsWriteTimeout = 10 * time.Second
+	eventsRetryMillis  = 3000
+	errorNoStreaming   = "Streaming is not supported by the server"
+	errorLastEventId   = "Last-Event-ID must be a non-negative integer"
+	lastEventIdQuery   = "last_event_id"
+	eventStreamContent = "text/event-stream"
+)
+
+type EventHandler struct {
+	service service.IEventService
+}
+
+func NewEventHandler(service service.IEventService) *EventHandler {
+	return &EventHandler{service: service}
+}
+
+// Stream отдаёт поток изменений подписок.
+//
+// @Summary      Поток событий подписок (SSE)
+// @Description  Server-Sent Events: subscription.created, subscription.updated, subscription.deleted. id события — номер в журнале: при переподключении с Last-Event-ID (или last_event_id) сначала отдаются пропущенные события. Номера выдаются при записи, а события видны после коммита, поэтому после переподключения могут повторно прийти события за последнюю минуту с номером меньше Last-Event-ID — повторы отбрасываются по id. Каждые 15 секунд приходит комментарий-пинг
+// @Tags         subscriptions
+// @Produce      text/event-stream
+// @Param        user_id        query   string  false  "ID пользователя (UUID)"  example("60601fee-2bf1-4721-ae6f-7636e79a0cba")
+// @Param        service_name   query   string  false  "Название сервиса"  example("Yandex Plus")
+// @Param        last_event_id  query   int     false  "Номер последнего полученного события, если нельзя передать заголовок"
+// @Param        Last-Event-ID  header  int     false  "Номер последнего полученного события"
+// @Success      200  {object}  dto.Event  "Поток событий, data каждого события — dto.Event"
+// @Failure      400  {object}  httpHelpers.ErrorMessage
+// @Failure      401  {object}  httpHelpers.ErrorMessage
+// @Failure      403  {object}  httpHelpers.ErrorMessage
+// @Failure      429  {object}  httpHelpers.ErrorMessage
+// @Failure      500  {object}  httpHelpers.ErrorMessage
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/v1/subscriptions/events [get]
+func (c *EventHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := &dto.SubscriptionFilter{
+		UserId:      query.Get("user_id"),
+		ServiceName: query.Get("service_name"),
+	}
+
+	if ok, errors := filter.IsValid(); !ok {
+		httpHelpers.RespondValidationError(w, r, errors)
+		return
+	}
+
+	lastEventId := r.Header.Get(LastEventIdHeader)
+	if lastEventId == "" {
+		lastEventId = query.Get(lastEventIdQuery)
+	}
+
+	var afterSeq int64
+	replay := lastEventId != ""
+	if replay {
+		seq, err := strconv.ParseInt(lastEventId, 10, 64)
+		if err != nil || seq < 0 {
+			httpHelpers.RespondValidationError(w, r, []validator.FieldError{
+				{Field: lastEventIdQuery, Code: validator.CodeInvalidFormat, Message: errorLastEventId},
+			})
+			return
+		}
+		afterSeq = seq
+	}
+
+	// Поток живёт дольше read/write таймаутов сервера: общие сроки снимаем,
+	// а запись ограничиваем eventsWriteTimeout, чтобы зависший клиент не держал соединение
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		httpHelpers.RespondError(w, r, http.StatusInternalServerError, errorNoStreaming)
+		return
+	}
+	_ = rc.SetReadDeadline(time.Time{})
+	flush := func() error {
+		if err := rc.Flush(); err != nil {
+			return err
+		}
+		return rc.SetWriteDeadline(time.Now().Add(eventsWriteTimeout))
+	}
+
+	// Подписываемся до чтения журнала, чтобы не потерять события, пришедшие во время догоняющего чтения
+	sub, sErr := c.service.Subscribe(r.Context(), filter)
+	if sErr != nil {
+		respondServiceError(w, r, sErr)
+		return
+	}
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", eventStreamContent)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	_ = rc.SetWriteDeadline(time.Now().Add(eventsWriteTimeout))
+	fmt.Fprintf(w, "retry: %d\n\n", eventsRetryMillis)
+	if err := flush(); err != nil {
+		return
+	}
+
+	// Номера событий, отправленных при догоняющем чтении: подписка может получить те же события.
+	// Сравнивать с курсором нельзя — поздно закоммиченное событие приходит с меньшим номером
+	sent := make(map[int64]struct{})
+	write := func(event *dto.Event) error {
+		sent[event.Seq] = struct{}{}
+		return writeEvent(w, event)
+	}
+
+	if replay {
+		late, sErr := c.service.Late(r.Context(), filter, afterSeq)
+		if sErr != nil {
+			logger.FromContext(r.Context()).WithError(sErr).Error("EventHandler -> Late")
+			return
+		}
+		for _, event := range late {
+			if err := write(event); err != nil {
+				return
+			}
+		}
+
+		for {
+			items, sErr := c.service.History(r.Context(), filter, afterSeq, eventsReplayBatch)
+			if sErr != nil {
+				logger.FromContext(r.Context()).WithError(sErr).Error("EventHandler -> History")
+				return
+			}
+
+			for _, event := range items {
+				if err := write(event); err != nil {
+					return
+				}
+				afterSeq = event.Seq
+			}
+			if err := flush(); err != nil {
+				return
+			}
+
+			if len(items) < eventsReplayBatch {
+				break
+			}
+		}
+	}
+
+	heartbeat := time.NewTicker(eventsHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-sub.Events:
+			if !ok {
+				// Клиент не успевал читать или сервис останавливается: клиент переподключится и дочитает журнал с Last-Event-ID
+				return
+			}
+			if _, ok := sent[event.Seq]; ok {
+				delete(sent, event.Seq)
+				continue
+			}
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+			if err := flush(); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			if err := flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event *dto.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Seq, event.Type, data)
+	return err
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"awesomeProject1/internal/health"
+	"awesomeProject1/pkg/logger"
+	"encoding/json"
+	"net/http"
+)
+
+type HealthHandler struct {
+	health *health.Health
+}
+
+func NewHealthHandler(health *health.Health) *HealthHandler {
+	return &HealthHandler{health: health}
+}
+
+// Live отвечает, что процесс жив. Зависимости не проверяются, чтобы сбой базы не приводил к перезапуску
+func (c *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, r, http.StatusOK, map[string]string{"status": health.StatusOk})
+}
+
+// Ready проверяет зависимости. 503 — экземпляр не должен получать трафик:
+// упала критичная зависимость или сервис останавливается
+func (c *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	report, ok := c.health.Ready(r.Context())
+
+	status := http.StatusOK
+	if !ok {
+		status = http.StatusServiceUnavailable
+	}
+
+	writeHealth(w, r, status, report)
+}
+
+// Пробы не оборачиваются в SuccessMessage: их читает оркестратор, а не клиенты API
+func writeHealth(w http.ResponseWriter, r *http.Request, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logger.FromContext(r.Context()).WithError(err).Error("HealthHandler -> Encode")
+	}
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"awesomeProject1/pkg/httpHelpers"
+	"awesomeProject1/pkg/logger"
+	"encoding/json"
+	"net/http"
+)
+
+// LogLevelHandler меняет уровни приёмников лога без перезапуска сервиса
+type LogLevelHandler struct{}
+
+func NewLogLevelHandler() *LogLevelHandler {
+	return &LogLevelHandler{}
+}
+
+type setLogLevelRequest struct {
+	// Sink — имя приёмника, пустое значение меняет уровень всех приёмников
+	Sink  string `json:"sink" example:"stdout"`
+	Level string `json:"level" example:"debug"`
+}
+
+// Get возвращает текущие уровни приёмников.
+//
+// @Summary      Уровни лога
+// @Description  Текущий уровень каждого приёмника лога по имени
+// @Tags         admin
+// @Produce      json
+// @Success      200  {object}  map[string]string
+// @Failure      401  {object}  httpHelpers.ErrorMessage
+// @Failure      403  {object}  httpHelpers.ErrorMessage
+// @Failure      429  {object}  httpHelpers.ErrorMessage
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /admin/log-level [get]
+func (c *LogLevelHandler) Get(w http.ResponseWriter, r *http.Request) {
+	httpHelpers.RespondSuccess(w, http.StatusOK, logger.Log.Levels())
+}
+
+// Set меняет уровень приёмника. Изменение действует до перезапуска, уровни из конфигурации не меняются.
+//
+// @Summary      Изменить уровень лога
+// @Description  Меняет уровень приёмника sink, без sink — всех приёмников. Действует до перезапуска или перезагрузки конфигурации
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        request body handlers.setLogLevelRequest true "Приёмник и уровень"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  httpHelpers.ErrorMessage
+// @Failure      401  {object}  httpHelpers.ErrorMessage
+// @Failure      403  {object}  httpHelpers.ErrorMessage
+// @Failure      429  {object}  httpHelpers.ErrorMessage
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /admin/log-level [put]
+func (c *LogLevelHandler) Set(w http.ResponseWriter, r *http.Request) {
+	var req setLogLevelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpHelpers.RespondError(w, r, http.StatusBadRequest, httpHelpers.ErrorParse)
+		return
+	}
+
+	if err := logger.Log.SetLevel(req.Sink, req.Level); err != nil {
+		httpHelpers.RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	sink := req.Sink
+	if sink == "" {
+		sink = "all"
+	}
+	logger.FromContext(r.Context()).WithFields(logger.Fields{"sink": sink, "level": req.Level}).Warn("LogLevel -> changed")
+	httpHelpers.RespondSuccess(w, http.StatusOK, logger.Log.Levels())
+}
@@ -2,24 +2,28 @@ package handlers
 
 import (
 	"awesomeProject1/internal/dto"
+	"awesomeProject1/internal/pagination"
 	"awesomeProject1/internal/service"
 	"awesomeProject1/pkg/httpHelpers"
-	"awesomeProject1/pkg/logger"
+	"awesomeProject1/pkg/jsonPatch"
+	"awesomeProject1/pkg/validator"
 	"encoding/json"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
-	"strings"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 )
 
 type SubscriptionHandler struct {
-	service service.ISubscriptionService
+	service    service.ISubscriptionService
+	pagination func() *pagination.Config
 }
 
-func NewCatalogHandler(service service.ISubscriptionService) *SubscriptionHandler {
-	return &SubscriptionHandler{service: service}
+func NewCatalogHandler(service service.ISubscriptionService, pagination func() *pagination.Config) *SubscriptionHandler {
+	return &SubscriptionHandler{service: service, pagination: pagination}
 }
 
 // GetTotal возвращает общую сумму по подпискам за указанный период.
@@ -35,21 +39,30 @@ func NewCatalogHandler(service service.ISubscriptionService) *SubscriptionHandle
 // @Param        service_name query  string  false  "Название сервиса"  example("Yandex Plus")
 // @Success      200 {object} map[string]int
 // @Failure      400  {object}  httpHelpers.ErrorMessage
+// @Failure      401  {object}  httpHelpers.ErrorMessage
+// @Failure      403  {object}  httpHelpers.ErrorMessage
+// @Failure      429  {object}  httpHelpers.ErrorMessage
 // @Failure      500  {object}  httpHelpers.ErrorMessage
-// @Router       /subscriptions/total [get]
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/v1/subscriptions/total [get]
 func (c *SubscriptionHandler) GetTotal(w http.ResponseWriter, r *http.Request) {
 	params := r.URL.Query()
 
 	start, err := dto.ParseMonthYear(params.Get("start"))
 
 	if err != nil {
-		httpHelpers.RespondError(w, http.StatusBadRequest, "Please provide start param in next format: mm-yyyy")
+		httpHelpers.RespondValidationError(w, r, []validator.FieldError{
+			{Field: "start", Code: validator.CodeInvalidFormat, Message: "Please provide start param in next format: mm-yyyy"},
+		})
 		return
 	}
 
 	end, err := dto.ParseMonthYear(params.Get("end"))
 	if err != nil {
-		httpHelpers.RespondError(w, http.StatusBadRequest, "Please provide end param in next format: mm-yyyy")
+		httpHelpers.RespondValidationError(w, r, []validator.FieldError{
+			{Field: "end", Code: validator.CodeInvalidFormat, Message: "Please provide end param in next format: mm-yyyy"},
+		})
 		return
 	}
 
@@ -59,14 +72,14 @@ func (c *SubscriptionHandler) GetTotal(w http.ResponseWriter, r *http.Request) {
 	req := dto.NewGetTotalSumRequest(start, end, userId, serviceName)
 
 	if ok, errors := req.IsValid(); !ok {
-		httpHelpers.RespondError(w, http.StatusBadRequest, strings.Join(errors, "; "))
+		httpHelpers.RespondValidationError(w, r, errors)
 		return
 	}
 
 	sum, sErr := c.service.GetTotalSum(r.Context(), req)
 
 	if sErr != nil {
-		httpHelpers.RespondError(w, sErr.Code, sErr.Message)
+		respondServiceError(w, r, sErr)
 		return
 	}
 
@@ -81,30 +94,27 @@ func (c *SubscriptionHandler) GetTotal(w http.ResponseWriter, r *http.Request) {
 // @Accept       json
 // @Produce      json
 // @Param        id path string true "ID подписки" example("123e4567-e89b-12d3-a456-426614174000")
+// @Param        If-Match header string false "ETag подписки, удаление выполнится только если запись не менялась"
 // @Success      200  {object}  httpHelpers.SuccessMessage
 // @Failure      400  {object}  httpHelpers.ErrorMessage
+// @Failure      401  {object}  httpHelpers.ErrorMessage
+// @Failure      403  {object}  httpHelpers.ErrorMessage
+// @Failure      412  {object}  httpHelpers.ErrorMessage
+// @Failure      429  {object}  httpHelpers.ErrorMessage
 // @Failure      500  {object}  httpHelpers.ErrorMessage
-// @Router       /subscription/{id} [delete]
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/v1/subscription/{id} [delete]
 func (c *SubscriptionHandler) Delete(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id, ok := vars["id"]
-
+	parsedId, ok := parseIdVar(w, r)
 	if !ok {
-		httpHelpers.RespondError(w, http.StatusBadRequest, "Subscription id not provided")
-		return
-	}
-
-	parsedId, err := uuid.Parse(id)
-
-	if err != nil {
-		httpHelpers.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Cannot parse provided id. Expected correct uuid. Got: %s", id))
 		return
 	}
 
-	sError := c.service.Delete(r.Context(), parsedId)
+	sError := c.service.Delete(r.Context(), parsedId, httpHelpers.ParseETags(r.Header.Get("If-Match")))
 
 	if sError != nil {
-		httpHelpers.RespondError(w, sError.Code, sError.Message)
+		respondServiceError(w, r, sError)
 		return
 	}
 
@@ -119,30 +129,34 @@ func (c *SubscriptionHandler) Delete(w http.ResponseWriter, r *http.Request) {
 // @Accept       json
 // @Produce      json
 // @Param        id path string true "ID подписки" example("123e4567-e89b-12d3-a456-426614174000")
+// @Param        If-None-Match header string false "ETag, при совпадении вернётся 304 Not Modified"
 // @Success      200 {object} dto.SubscriptionResponse
+// @Header       200 {string} ETag "ETag текущей версии подписки"
+// @Success      304 "Подписка не изменилась"
 // @Failure      400  {object}  httpHelpers.ErrorMessage
+// @Failure      401  {object}  httpHelpers.ErrorMessage
+// @Failure      403  {object}  httpHelpers.ErrorMessage
+// @Failure      429  {object}  httpHelpers.ErrorMessage
 // @Failure      500  {object}  httpHelpers.ErrorMessage
-// @Router       /subscription/{id} [get]
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/v1/subscription/{id} [get]
 func (c *SubscriptionHandler) GetById(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id, ok := vars["id"]
-
+	parsedId, ok := parseIdVar(w, r)
 	if !ok {
-		httpHelpers.RespondError(w, http.StatusBadRequest, "Subscription id not provided")
 		return
 	}
 
-	parsedId, err := uuid.Parse(id)
+	item, sErr := c.service.GetById(r.Context(), parsedId)
 
-	if err != nil {
-		httpHelpers.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Cannot parse provided id. Expected correct uuid. Got: %s", id))
+	if sErr != nil {
+		respondServiceError(w, r, sErr)
 		return
 	}
 
-	item, sErr := c.service.GetById(r.Context(), parsedId)
-
-	if sErr != nil {
-		httpHelpers.RespondError(w, sErr.Code, sErr.Message)
+	w.Header().Set("ETag", item.ETag)
+	if httpHelpers.MatchETag(httpHelpers.ParseETags(r.Header.Get("If-None-Match")), item.ETag, true) {
+		w.WriteHeader(http.StatusNotModified)
 		return
 	}
 
@@ -157,39 +171,114 @@ func (c *SubscriptionHandler) GetById(w http.ResponseWriter, r *http.Request) {
 // @Accept       json
 // @Produce      json
 // @Param        request body dto.UpdateSubscriptionRequest true "Данные для обновления подписки"
+// @Param        If-Match header string false "ETag подписки, обновление выполнится только если запись не менялась"
 // @Success      200  {object} 	httpHelpers.SuccessMessage
 // @Failure      400  {object}  httpHelpers.ErrorMessage
+// @Failure      401  {object}  httpHelpers.ErrorMessage
+// @Failure      403  {object}  httpHelpers.ErrorMessage
+// @Failure      412  {object}  httpHelpers.ErrorMessage
+// @Failure      422  {object}  httpHelpers.ErrorMessage
+// @Failure      429  {object}  httpHelpers.ErrorMessage
 // @Failure      500  {object}  httpHelpers.ErrorMessage
-// @Router       /subscription [put]
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/v1/subscription [patch]
 func (c *SubscriptionHandler) Update(w http.ResponseWriter, r *http.Request) {
 	req := dto.UpdateSubscriptionRequest{}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httpHelpers.RespondError(w, http.StatusBadRequest, httpHelpers.ErrorParse)
+		httpHelpers.RespondError(w, r, http.StatusBadRequest, httpHelpers.ErrorParse)
 		return
 	}
 
 	if ok, errors := req.IsValid(); !ok {
-		httpHelpers.RespondError(w, http.StatusBadRequest, strings.Join(errors, "; "))
+		httpHelpers.RespondValidationError(w, r, errors)
 		return
 	}
 
 	updateData, err := req.ToUpdateData()
 	if err != nil {
-		logger.Log.Error(fmt.Sprintf("Subscription handler -> ToUpdateData Error -> err: %s", err.Error()))
-		httpHelpers.RespondError(w, http.StatusInternalServerError, "Что-то пошло не так, попробуйте позже или проверьте данные")
+		httpHelpers.RespondServiceError(w, r, &httpHelpers.ServiceError{
+			Code:    http.StatusInternalServerError,
+			Message: "Что-то пошло не так, попробуйте позже или проверьте данные",
+			Err:     fmt.Errorf("Subscription handler -> ToUpdateData -> %w", err),
+		})
 		return
 	}
+	updateData.IfMatch = httpHelpers.ParseETags(r.Header.Get("If-Match"))
 
 	sError := c.service.Update(r.Context(), updateData)
 	if sError != nil {
-		httpHelpers.RespondError(w, sError.Code, sError.Message)
+		respondServiceError(w, r, sError)
 		return
 	}
 
 	httpHelpers.RespondSuccess(w, http.StatusOK, nil)
 }
 
+// Patch применяет JSON Merge Patch или JSON Patch к подписке.
+//
+// @Summary      Частично обновить подписку
+// @Description  Применяет RFC 7386 Merge Patch (application/merge-patch+json, null очищает поле) или RFC 6902 JSON Patch (application/json-patch+json) к текущему состоянию подписки. Результат валидируется целиком
+// @Tags         subscriptions
+// @Accept       application/merge-patch+json
+// @Accept       application/json-patch+json
+// @Produce      json
+// @Param        id      path  string  true  "ID подписки" example("123e4567-e89b-12d3-a456-426614174000")
+// @Param        request body  object  true  "Merge Patch документ или массив операций JSON Patch"
+// @Param        If-Match header string false "ETag подписки, патч применится только если запись не менялась"
+// @Success      200  {object}  dto.SubscriptionResponse
+// @Failure      400  {object}  httpHelpers.ErrorMessage
+// @Failure      401  {object}  httpHelpers.ErrorMessage
+// @Failure      403  {object}  httpHelpers.ErrorMessage
+// @Failure      412  {object}  httpHelpers.ErrorMessage
+// @Failure      422  {object}  httpHelpers.ErrorMessage
+// @Failure      415  {object}  httpHelpers.ErrorMessage
+// @Failure      429  {object}  httpHelpers.ErrorMessage
+// @Failure      500  {object}  httpHelpers.ErrorMessage
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/v1/subscription/{id} [patch]
+func (c *SubscriptionHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	parsedId, ok := parseIdVar(w, r)
+	if !ok {
+		return
+	}
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		mediaType = ""
+	}
+
+	var apply func(doc, patch []byte) ([]byte, error)
+	switch mediaType {
+	case jsonPatch.MergePatchMediaType:
+		apply = jsonPatch.MergePatch
+	case jsonPatch.JSONPatchMediaType:
+		apply = jsonPatch.Apply
+	default:
+		httpHelpers.RespondError(w, r, http.StatusUnsupportedMediaType, fmt.Sprintf("Unsupported Content-Type. Expected %s or %s", jsonPatch.MergePatchMediaType, jsonPatch.JSONPatchMediaType))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		httpHelpers.RespondError(w, r, http.StatusBadRequest, httpHelpers.ErrorParse)
+		return
+	}
+
+	item, sErr := c.service.Patch(r.Context(), parsedId, httpHelpers.ParseETags(r.Header.Get("If-Match")), func(doc []byte) ([]byte, error) {
+		return apply(doc, body)
+	})
+	if sErr != nil {
+		respondServiceError(w, r, sErr)
+		return
+	}
+
+	w.Header().Set("ETag", item.ETag)
+	httpHelpers.RespondSuccess(w, http.StatusOK, item)
+}
+
 // Create создаёт новую подписку.
 //
 // @Summary      Создать подписку
@@ -198,75 +287,114 @@ func (c *SubscriptionHandler) Update(w http.ResponseWriter, r *http.Request) {
 // @Accept       json
 // @Produce      json
 // @Param        request body dto.CreateSubscriptionRequest true "Данные для создания подписки"
+// @Param        Idempotency-Key header string false "Ключ идемпотентности, повтор с тем же ключом и телом вернёт сохранённый ответ"
 // @Success      201  {object}  dto.SubscriptionResponse
 // @Failure      400  {object}  httpHelpers.ErrorMessage
+// @Failure      401  {object}  httpHelpers.ErrorMessage
+// @Failure      403  {object}  httpHelpers.ErrorMessage
+// @Failure      409  {object}  httpHelpers.ErrorMessage
+// @Failure      422  {object}  httpHelpers.ErrorMessage
+// @Failure      429  {object}  httpHelpers.ErrorMessage
 // @Failure      500  {object}  httpHelpers.ErrorMessage
-// @Router       /subscription [post]
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/v1/subscription [post]
 func (c *SubscriptionHandler) Create(w http.ResponseWriter, r *http.Request) {
 	req := dto.CreateSubscriptionRequest{}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httpHelpers.RespondError(w, http.StatusBadRequest, httpHelpers.ErrorParse)
+		httpHelpers.RespondError(w, r, http.StatusBadRequest, httpHelpers.ErrorParse)
 		return
 	}
 
 	if ok, errors := req.IsValid(); !ok {
-		httpHelpers.RespondError(w, 400, strings.Join(errors, "; "))
+		httpHelpers.RespondValidationError(w, r, errors)
 		return
 	}
 
 	sub, err := req.ToSubscription()
 
 	if err != nil {
-		logger.Log.Error(fmt.Sprintf("Subscription handler -> ToSubscription Error -> err: %s", err.Error()))
-		httpHelpers.RespondError(w, 500, "Что-то пошло не так, попробуйте позже или проверьте данные")
+		httpHelpers.RespondServiceError(w, r, &httpHelpers.ServiceError{
+			Code:    http.StatusInternalServerError,
+			Message: "Что-то пошло не так, попробуйте позже или проверьте данные",
+			Err:     fmt.Errorf("Subscription handler -> ToSubscription -> %w", err),
+		})
 		return
 	}
 
 	created, sError := c.service.Create(r.Context(), sub)
 
 	if sError != nil {
-		httpHelpers.RespondError(w, sError.Code, sError.Message)
+		respondServiceError(w, r, sError)
 		return
 	}
 
+	w.Header().Set("ETag", created.ETag)
 	httpHelpers.RespondSuccess(w, http.StatusCreated, created)
 }
 
 // GetAll возвращает список всех подписок.
 //
 // @Summary      Получить список подписок
-// @Description  Возвращает список всех подписок с пагинацией
+// @Description  Возвращает список всех подписок с пагинацией и фильтрацией по пользователю и сервису
 // @Tags         subscriptions
 // @Accept       json
 // @Produce      json
 // @Param        offset  query  int  false  "Смещение (по умолчанию 0)"  example(0)
 // @Param        limit   query  int  false  "Лимит записей (по умолчанию 10)" example(10)
+// @Param        user_id      query  string  false  "ID пользователя (UUID)"  example("60601fee-2bf1-4721-ae6f-7636e79a0cba")
+// @Param        service_name query  string  false  "Название сервиса"  example("Yandex Plus")
 // @Success      200  {object}  dto.SubscriptionListResponse
 // @Failure      400  {object}  httpHelpers.ErrorMessage
+// @Failure      401  {object}  httpHelpers.ErrorMessage
+// @Failure      403  {object}  httpHelpers.ErrorMessage
+// @Failure      429  {object}  httpHelpers.ErrorMessage
 // @Failure      500  {object}  httpHelpers.ErrorMessage
-// @Router       /subscriptions [get]
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/v1/subscriptions [get]
 func (c *SubscriptionHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query()
+	offset, limit := parsePagination(r, c.pagination())
 
-	offsetStr := query.Get("offset")
-	limitStr := query.Get("limit")
-
-	offset := 0
-	limit := 10
-
-	if offsetStr != "" {
-		fmt.Sscanf(offsetStr, "%d", &offset)
+	filter := &dto.SubscriptionFilter{
+		UserId:      query.Get("user_id"),
+		ServiceName: query.Get("service_name"),
 	}
-	if limitStr != "" {
-		fmt.Sscanf(limitStr, "%d", &limit)
+
+	if ok, errors := filter.IsValid(); !ok {
+		httpHelpers.RespondValidationError(w, r, errors)
+		return
 	}
 
-	result, sErr := c.service.GetAll(r.Context(), offset, limit)
+	result, sErr := c.service.GetAll(r.Context(), filter, offset, limit)
 	if sErr != nil {
-		httpHelpers.RespondError(w, sErr.Code, sErr.Message)
+		respondServiceError(w, r, sErr)
 		return
 	}
 
 	httpHelpers.RespondSuccess(w, http.StatusOK, result)
 }
+
+// parseIdVar достаёт id подписки из пути запроса, при ошибке сразу отвечает клиенту
+func parseIdVar(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	vars := mux.Vars(r)
+	id, ok := vars["id"]
+
+	if !ok {
+		httpHelpers.RespondError(w, r, http.StatusBadRequest, "Subscription id not provided")
+		return uuid.Nil, false
+	}
+
+	parsedId, err := uuid.Parse(id)
+
+	if err != nil {
+		httpHelpers.RespondValidationError(w, r, []validator.FieldError{
+			{Field: "id", Code: validator.CodeInvalidUuid, Message: fmt.Sprintf("Cannot parse provided id. Expected correct uuid. Got: %s", id)},
+		})
+		return uuid.Nil, false
+	}
+
+	return parsedId, true
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"awesomeProject1/internal/dto"
+	"awesomeProject1/internal/pagination"
+	"awesomeProject1/internal/service"
+	"awesomeProject1/internal/tenant"
+	"awesomeProject1/pkg/httpHelpers"
+	"awesomeProject1/pkg/logger"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// TenantHandler заводит тенантов и определяет тенанта каждого запроса к API
+type TenantHandler struct {
+	service    service.ITenantService
+	pagination func() *pagination.Config
+}
+
+func NewTenantHandler(service service.ITenantService, pagination func() *pagination.Config) *TenantHandler {
+	return &TenantHandler{service: service, pagination: pagination}
+}
+
+// Middleware ограничивает запрос тенантом субъекта или тенантом из X-Tenant-ID, ставится после Authenticate
+func (c *TenantHandler) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, sErr := c.service.Resolve(r.Context(), strings.TrimSpace(r.Header.Get(tenant.Header)))
+		if sErr != nil {
+			respondServiceError(w, r, sErr)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(tenant.Attach(r.Context(), id)))
+	})
+}
+
+// Create заводит тенанта, его id передаётся в X-Tenant-ID или выдаётся ключам тенанта.
+//
+// @Summary      Завести тенанта
+// @Description  Доступно только ключу или токену платформы с admin:tenants. id тенанта передаётся в X-Tenant-ID, чтобы выпустить ключи тенанта
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.CreateTenantRequest true "Данные тенанта"
+// @Success      201  {object}  dto.TenantResponse
+// @Failure      400  {object}  httpHelpers.ErrorMessage
+// @Failure      401  {object}  httpHelpers.ErrorMessage
+// @Failure      403  {object}  httpHelpers.ErrorMessage
+// @Failure      409  {object}  httpHelpers.ErrorMessage
+// @Failure      429  {object}  httpHelpers.ErrorMessage
+// @Failure      500  {object}  httpHelpers.ErrorMessage
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /admin/tenants [post]
+func (c *TenantHandler) Create(w http.ResponseWriter, r *http.Request) {
+	req := dto.CreateTenantRequest{}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpHelpers.RespondError(w, r, http.StatusBadRequest, httpHelpers.ErrorParse)
+		return
+	}
+
+	if ok, errors := req.IsValid(); !ok {
+		httpHelpers.RespondValidationError(w, r, errors)
+		return
+	}
+
+	created, sErr := c.service.Create(r.Context(), &req)
+	if sErr != nil {
+		respondServiceError(w, r, sErr)
+		return
+	}
+
+	logger.FromContext(r.Context()).WithFields(logger.Fields{"tenant_id": created.ID.String(), "name": created.Name}).Warn("Tenant -> created")
+	httpHelpers.RespondSuccess(w, http.StatusCreated, created)
+}
+
+// GetAll возвращает список тенантов.
+//
+// @Summary      Получить список тенантов
+// @Description  Доступно только ключу или токену платформы с admin:tenants
+// @Tags         admin
+// @Produce      json
+// @Param        offset  query  int  false  "Смещение (по умолчанию 0)"  example(0)
+// @Param        limit   query  int  false  "Лимит записей (по умолчанию 10)" example(10)
+// @Success      200  {object}  dto.TenantListResponse
+// @Failure      401  {object}  httpHelpers.ErrorMessage
+// @Failure      403  {object}  httpHelpers.ErrorMessage
+// @Failure      429  {object}  httpHelpers.ErrorMessage
+// @Failure      500  {object}  httpHelpers.ErrorMessage
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /admin/tenants [get]
+func (c *TenantHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	offset, limit := parsePagination(r, c.pagination())
+
+	result, sErr := c.service.GetAll(r.Context(), offset, limit)
+	if sErr != nil {
+		respondServiceError(w, r, sErr)
+		return
+	}
+
+	httpHelpers.RespondSuccess(w, http.StatusOK, result)
+}
+
+// GetById возвращает тенанта по id.
+//
+// @Summary      Получить тенанта
+// @Description  Доступно только ключу или токену платформы с admin:tenants
+// @Tags         admin
+// @Produce      json
+// @Param        id path string true "ID тенанта" example("3f6c1d2e-8a4b-4c7d-9e1f-2a3b4c5d6e7f")
+// @Success      200  {object}  dto.TenantResponse
+// @Failure      400  {object}  httpHelpers.ErrorMessage
+// @Failure      401  {object}  httpHelpers.ErrorMessage
+// @Failure      403  {object}  httpHelpers.ErrorMessage
+// @Failure      404  {object}  httpHelpers.ErrorMessage
+// @Failure      429  {object}  httpHelpers.ErrorMessage
+// @Failure      500  {object}  httpHelpers.ErrorMessage
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /admin/tenants/{id} [get]
+func (c *TenantHandler) GetById(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUuidVar(w, r, "id")
+	if !ok {
+		return
+	}
+
+	result, sErr := c.service.GetById(r.Context(), id)
+	if sErr != nil {
+		respondServiceError(w, r, sErr)
+		return
+	}
+
+	httpHelpers.RespondSuccess(w, http.StatusOK, result)
+}
@@ -0,0 +1,309 @@
+package handlers
+
+import (
+	"awesomeProject1/internal/dto"
+	"awesomeProject1/internal/pagination"
+	"awesomeProject1/internal/service"
+	"awesomeProject1/pkg/httpHelpers"
+	"awesomeProject1/pkg/validator"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type WebhookHandler struct {
+	service    service.IWebhookService
+	pagination func() *pagination.Config
+}
+
+func NewWebhookHandler(service service.IWebhookService, pagination func() *pagination.Config) *WebhookHandler {
+	return &WebhookHandler{service: service, pagination: pagination}
+}
+
+// Create регистрирует вебхук.
+//
+// @Summary      Зарегистрировать вебхук
+// @Description  Регистрирует адрес для событий subscription.created, subscription.updated, subscription.deleted. Каждый запрос подписывается HMAC-SHA256 от "<X-Webhook-Timestamp>.<тело>" и передаётся в заголовке X-Webhook-Signature: sha256=<hex>. Если secret не указан, он генерируется и возвращается только в этом ответе
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.CreateWebhookRequest true "Данные вебхука"
+// @Success      201  {object}  dto.WebhookResponse
+// @Failure      400  {object}  httpHelpers.ErrorMessage
+// @Failure      401  {object}  httpHelpers.ErrorMessage
+// @Failure      403  {object}  httpHelpers.ErrorMessage
+// @Failure      429  {object}  httpHelpers.ErrorMessage
+// @Failure      500  {object}  httpHelpers.ErrorMessage
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/v1/webhooks [post]
+func (c *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	req := dto.CreateWebhookRequest{}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpHelpers.RespondError(w, r, http.StatusBadRequest, httpHelpers.ErrorParse)
+		return
+	}
+
+	if ok, errors := req.IsValid(); !ok {
+		httpHelpers.RespondValidationError(w, r, errors)
+		return
+	}
+
+	created, sErr := c.service.Create(r.Context(), &req)
+	if sErr != nil {
+		respondServiceError(w, r, sErr)
+		return
+	}
+
+	httpHelpers.RespondSuccess(w, http.StatusCreated, created)
+}
+
+// Update изменяет вебхук.
+//
+// @Summary      Изменить вебхук
+// @Description  Меняет только переданные поля вебхука
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "ID вебхука" example("0c7d2b9e-5d1a-4e9f-8a2b-6c4d3e2f1a0b")
+// @Param        request body dto.UpdateWebhookRequest true "Изменяемые поля"
+// @Success      200  {object}  dto.WebhookResponse
+// @Failure      400  {object}  httpHelpers.ErrorMessage
+// @Failure      401  {object}  httpHelpers.ErrorMessage
+// @Failure      403  {object}  httpHelpers.ErrorMessage
+// @Failure      429  {object}  httpHelpers.ErrorMessage
+// @Failure      500  {object}  httpHelpers.ErrorMessage
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/v1/webhooks/{id} [patch]
+func (c *WebhookHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUuidVar(w, r, "id")
+	if !ok {
+		return
+	}
+
+	req := dto.UpdateWebhookRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpHelpers.RespondError(w, r, http.StatusBadRequest, httpHelpers.ErrorParse)
+		return
+	}
+
+	if ok, errors := req.IsValid(); !ok {
+		httpHelpers.RespondValidationError(w, r, errors)
+		return
+	}
+
+	item, sErr := c.service.Update(r.Context(), id, &req)
+	if sErr != nil {
+		respondServiceError(w, r, sErr)
+		return
+	}
+
+	httpHelpers.RespondSuccess(w, http.StatusOK, item)
+}
+
+// Delete удаляет вебхук.
+//
+// @Summary      Удалить вебхук
+// @Description  Удаляет вебхук вместе с журналом доставок
+// @Tags         webhooks
+// @Produce      json
+// @Param        id path string true "ID вебхука" example("0c7d2b9e-5d1a-4e9f-8a2b-6c4d3e2f1a0b")
+// @Success      200  {object}  httpHelpers.SuccessMessage
+// @Failure      400  {object}  httpHelpers.ErrorMessage
+// @Failure      401  {object}  httpHelpers.ErrorMessage
+// @Failure      403  {object}  httpHelpers.ErrorMessage
+// @Failure      429  {object}  httpHelpers.ErrorMessage
+// @Failure      500  {object}  httpHelpers.ErrorMessage
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/v1/webhooks/{id} [delete]
+func (c *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUuidVar(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if sErr := c.service.Delete(r.Context(), id); sErr != nil {
+		respondServiceError(w, r, sErr)
+		return
+	}
+
+	httpHelpers.RespondSuccess(w, http.StatusOK, nil)
+}
+
+// GetById возвращает вебхук по ID.
+//
+// @Summary      Получить вебхук
+// @Tags         webhooks
+// @Produce      json
+// @Param        id path string true "ID вебхука" example("0c7d2b9e-5d1a-4e9f-8a2b-6c4d3e2f1a0b")
+// @Success      200  {object}  dto.WebhookResponse
+// @Failure      400  {object}  httpHelpers.ErrorMessage
+// @Failure      401  {object}  httpHelpers.ErrorMessage
+// @Failure      403  {object}  httpHelpers.ErrorMessage
+// @Failure      429  {object}  httpHelpers.ErrorMessage
+// @Failure      500  {object}  httpHelpers.ErrorMessage
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/v1/webhooks/{id} [get]
+func (c *WebhookHandler) GetById(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUuidVar(w, r, "id")
+	if !ok {
+		return
+	}
+
+	item, sErr := c.service.GetById(r.Context(), id)
+	if sErr != nil {
+		respondServiceError(w, r, sErr)
+		return
+	}
+
+	httpHelpers.RespondSuccess(w, http.StatusOK, item)
+}
+
+// GetAll возвращает список вебхуков.
+//
+// @Summary      Получить список вебхуков
+// @Tags         webhooks
+// @Produce      json
+// @Param        offset  query  int  false  "Смещение (по умолчанию 0)"  example(0)
+// @Param        limit   query  int  false  "Лимит записей (по умолчанию 10)" example(10)
+// @Success      200  {object}  dto.WebhookListResponse
+// @Failure      401  {object}  httpHelpers.ErrorMessage
+// @Failure      403  {object}  httpHelpers.ErrorMessage
+// @Failure      429  {object}  httpHelpers.ErrorMessage
+// @Failure      500  {object}  httpHelpers.ErrorMessage
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/v1/webhooks [get]
+func (c *WebhookHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	offset, limit := parsePagination(r, c.pagination())
+
+	result, sErr := c.service.GetAll(r.Context(), offset, limit)
+	if sErr != nil {
+		respondServiceError(w, r, sErr)
+		return
+	}
+
+	httpHelpers.RespondSuccess(w, http.StatusOK, result)
+}
+
+// GetDeliveries возвращает журнал доставок вебхука.
+//
+// @Summary      Журнал доставок вебхука
+// @Description  Доставки событий на вебхук с числом попыток, последним ответом и временем следующей попытки. Доставки со статусом dead исчерпали попытки
+// @Tags         webhooks
+// @Produce      json
+// @Param        id      path   string  true   "ID вебхука" example("0c7d2b9e-5d1a-4e9f-8a2b-6c4d3e2f1a0b")
+// @Param        status  query  string  false  "Фильтр по статусу" Enums(pending, delivered, dead)
+// @Param        offset  query  int     false  "Смещение (по умолчанию 0)"  example(0)
+// @Param        limit   query  int     false  "Лимит записей (по умолчанию 10)" example(10)
+// @Success      200  {object}  dto.WebhookDeliveryListResponse
+// @Failure      400  {object}  httpHelpers.ErrorMessage
+// @Failure      401  {object}  httpHelpers.ErrorMessage
+// @Failure      403  {object}  httpHelpers.ErrorMessage
+// @Failure      429  {object}  httpHelpers.ErrorMessage
+// @Failure      500  {object}  httpHelpers.ErrorMessage
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/v1/webhooks/{id}/deliveries [get]
+func (c *WebhookHandler) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUuidVar(w, r, "id")
+	if !ok {
+		return
+	}
+
+	status := dto.DeliveryStatus(r.URL.Query().Get("status"))
+	switch status {
+	case "", dto.DeliveryPending, dto.DeliveryDelivered, dto.DeliveryDead:
+	default:
+		httpHelpers.RespondValidationError(w, r, []validator.FieldError{
+			{Field: "status", Code: validator.CodeInvalidFormat, Message: fmt.Sprintf("[status] - Expected one of pending, delivered, dead. Got: %s", status)},
+		})
+		return
+	}
+
+	offset, limit := parsePagination(r, c.pagination())
+
+	result, sErr := c.service.GetDeliveries(r.Context(), id, status, offset, limit)
+	if sErr != nil {
+		respondServiceError(w, r, sErr)
+		return
+	}
+
+	httpHelpers.RespondSuccess(w, http.StatusOK, result)
+}
+
+// RetryDelivery перезапускает доставку из dead-letter.
+//
+// @Summary      Повторить доставку
+// @Description  Возвращает доставку со статусом dead в очередь с обнулённым счётчиком попыток
+// @Tags         webhooks
+// @Produce      json
+// @Param        id           path  string  true  "ID вебхука" example("0c7d2b9e-5d1a-4e9f-8a2b-6c4d3e2f1a0b")
+// @Param        delivery_id  path  string  true  "ID доставки" example("5a8f0e2c-7b3d-4c1e-9f6a-2d4b8c0e1f3a")
+// @Success      202  {object}  httpHelpers.SuccessMessage
+// @Failure      400  {object}  httpHelpers.ErrorMessage
+// @Failure      401  {object}  httpHelpers.ErrorMessage
+// @Failure      403  {object}  httpHelpers.ErrorMessage
+// @Failure      429  {object}  httpHelpers.ErrorMessage
+// @Failure      500  {object}  httpHelpers.ErrorMessage
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /api/v1/webhooks/{id}/deliveries/{delivery_id}/retry [post]
+func (c *WebhookHandler) RetryDelivery(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUuidVar(w, r, "id")
+	if !ok {
+		return
+	}
+
+	deliveryId, ok := parseUuidVar(w, r, "delivery_id")
+	if !ok {
+		return
+	}
+
+	if sErr := c.service.RetryDelivery(r.Context(), id, deliveryId); sErr != nil {
+		respondServiceError(w, r, sErr)
+		return
+	}
+
+	httpHelpers.RespondSuccess(w, http.StatusAccepted, nil)
+}
+
+// parseUuidVar достаёт uuid из переменной пути name, при ошибке сразу отвечает клиенту
+func parseUuidVar(w http.ResponseWriter, r *http.Request, name string) (uuid.UUID, bool) {
+	value := mux.Vars(r)[name]
+
+	parsed, err := uuid.Parse(value)
+	if err != nil {
+		httpHelpers.RespondValidationError(w, r, []validator.FieldError{
+			{Field: name, Code: validator.CodeInvalidUuid, Message: fmt.Sprintf("Cannot parse provided %s. Expected correct uuid. Got: %s", name, value)},
+		})
+		return uuid.Nil, false
+	}
+
+	return parsed, true
+}
+
+// parsePagination читает offset и limit из строки запроса. Без limit отдаётся страница размера
+// pagination.default_limit, больше pagination.max_limit не отдаётся
+func parsePagination(r *http.Request, config *pagination.Config) (int, int) {
+	query := r.URL.Query()
+
+	offset := 0
+	limit := 0
+
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		fmt.Sscanf(offsetStr, "%d", &offset)
+	}
+	if limitStr := query.Get("limit"); limitStr != "" {
+		fmt.Sscanf(limitStr, "%d", &limit)
+	}
+
+	return offset, config.Limit(limit)
+}
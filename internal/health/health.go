@@ -0,0 +1,125 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusOk       = "ok"
+	StatusFail     = "fail"
+	StatusDegraded = "degraded"
+	StatusDraining = "draining"
+
+	defaultCheckTimeout = 2 * time.Second
+)
+
+// Check — проверка одной зависимости
+type Check struct {
+	Name string
+	// Critical — без зависимости экземпляр не может обслуживать запросы, её сбой снимает готовность.
+	// Сбой некритичной зависимости отмечается как degraded
+	Critical bool
+	Fn       func(ctx context.Context) error
+}
+
+// CheckResult — результат проверки зависимости
+type CheckResult struct {
+	Status    string  `json:"status" example:"ok"`
+	Critical  bool    `json:"critical" example:"true"`
+	LatencyMs float64 `json:"latency_ms" example:"1.25"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report — ответ /readyz
+type Report struct {
+	Status string                  `json:"status" example:"ok"`
+	Checks map[string]*CheckResult `json:"checks"`
+}
+
+// Health — готовность экземпляра к приёму трафика
+type Health struct {
+	checks   []Check
+	timeout  time.Duration
+	draining atomic.Bool
+}
+
+func New(checks ...Check) *Health {
+	return &Health{checks: checks, timeout: defaultCheckTimeout}
+}
+
+// Add регистрирует проверку, вызывается до начала обслуживания запросов
+func (h *Health) Add(check Check) {
+	h.checks = append(h.checks, check)
+}
+
+// SetDraining снимает готовность при остановке сервиса, чтобы балансировщик перестал слать запросы
+func (h *Health) SetDraining() {
+	h.draining.Store(true)
+}
+
+func (h *Health) Draining() bool {
+	return h.draining.Load()
+}
+
+// Ready выполняет все проверки параллельно. ok — экземпляр готов принимать трафик
+func (h *Health) Ready(ctx context.Context) (*Report, bool) {
+	report := &Report{
+		Status: StatusOk,
+		Checks: make(map[string]*CheckResult, len(h.checks)),
+	}
+
+	results := make([]*CheckResult, len(h.checks))
+	wg := sync.WaitGroup{}
+	for i, check := range h.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = h.run(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	for i, check := range h.checks {
+		result := results[i]
+		report.Checks[check.Name] = result
+
+		if result.Status == StatusOk {
+			continue
+		}
+		if check.Critical {
+			report.Status = StatusFail
+		} else if report.Status == StatusOk {
+			report.Status = StatusDegraded
+		}
+	}
+
+	if h.Draining() {
+		report.Status = StatusDraining
+	}
+
+	return report, report.Status == StatusOk || report.Status == StatusDegraded
+}
+
+func (h *Health) run(ctx context.Context, check Check) *CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Fn(ctx)
+	latency := time.Since(start)
+
+	result := &CheckResult{
+		Status:    StatusOk,
+		Critical:  check.Critical,
+		LatencyMs: float64(latency.Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+
+	return result
+}
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"awesomeProject1/internal/dto"
+	"awesomeProject1/internal/tenant"
+	"awesomeProject1/pkg/logger"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	businessCacheTTL     = 30 * time.Second
+	businessQueryTimeout = 5 * time.Second
+	serviceLabel         = "service"
+)
+
+// businessCollector — действующие подписки и ежемесячная выручка по сервисам.
+// Запрос к базе выполняется не чаще раза в businessCacheTTL, сколько бы Prometheus ни опрашивал /metrics
+type businessCollector struct {
+	load func(ctx context.Context, at time.Time) ([]*dto.ServiceStats, error)
+
+	active  *prometheus.Desc
+	revenue *prometheus.Desc
+
+	mu       sync.Mutex
+	cached   []*dto.ServiceStats
+	loadedAt time.Time
+}
+
+func NewBusinessCollector(load func(ctx context.Context, at time.Time) ([]*dto.ServiceStats, error)) prometheus.Collector {
+	return &businessCollector{
+		load: load,
+		active: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "active"),
+			"Subscriptions active in the current month by service.", []string{serviceLabel}, nil),
+		revenue: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "monthly_recurring_revenue_rub"),
+			"Sum of monthly prices of active subscriptions by service, rubles.", []string{serviceLabel}, nil),
+	}
+}
+
+func (c *businessCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.active
+	ch <- c.revenue
+}
+
+func (c *businessCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, item := range c.stats() {
+		ch <- prometheus.MustNewConstMetric(c.active, prometheus.GaugeValue, float64(item.Active), item.ServiceName)
+		ch <- prometheus.MustNewConstMetric(c.revenue, prometheus.GaugeValue, float64(item.MonthlyRevenue), item.ServiceName)
+	}
+}
+
+// stats возвращает кэш, при ошибке базы — последние известные значения
+func (c *businessCollector) stats() []*dto.ServiceStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Since(c.loadedAt) < businessCacheTTL {
+		return c.cached
+	}
+
+	// Метрики сервиса общие: подписки считаются по всем тенантам
+	ctx, cancel := context.WithTimeout(tenant.WithAll(context.Background()), businessQueryTimeout)
+	defer cancel()
+
+	stats, err := c.load(ctx, time.Now())
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("Metrics -> business stats")
+		return c.cached
+	}
+
+	c.cached = stats
+	c.loadedAt = time.Now()
+	return stats
+}
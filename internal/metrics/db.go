@@ -0,0 +1,135 @@
+package metrics
+
+import (
+	"awesomeProject1/internal/repository"
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type dbMetrics struct {
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+}
+
+func newDbMetrics(registry *prometheus.Registry) *dbMetrics {
+	m := &dbMetrics{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "Database query latency by repository method.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"repository", "method"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "db_query_errors_total",
+			Help:      "Failed database queries by repository method.",
+		}, []string{"repository", "method"}),
+	}
+
+	registry.MustRegister(m.duration, m.errors)
+	return m
+}
+
+// QueryTracer возвращает pgx.QueryTracer, который замеряет запросы. Репозиторий и метод берутся из ctx запроса,
+// их проставляет сам метод репозитория через withQuery
+func (m *Metrics) QueryTracer() pgx.QueryTracer {
+	return &queryTracer{metrics: m.db}
+}
+
+type queryTracer struct {
+	metrics *dbMetrics
+}
+
+type queryStartKey struct{}
+
+type queryStart struct {
+	repository string
+	method     string
+	start      time.Time
+}
+
+func (t *queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryStartData) context.Context {
+	repo, method := repository.Caller(ctx)
+	return context.WithValue(ctx, queryStartKey{}, &queryStart{repository: repo, method: method, start: time.Now()})
+}
+
+func (t *queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(queryStartKey{}).(*queryStart)
+	if !ok {
+		return
+	}
+
+	t.metrics.duration.WithLabelValues(start.repository, start.method).Observe(time.Since(start.start).Seconds())
+	if data.Err != nil && data.Err != pgx.ErrNoRows {
+		t.metrics.errors.WithLabelValues(start.repository, start.method).Inc()
+	}
+}
+
+// poolCollector отдаёт статистику pgxpool в момент сбора метрик
+type poolCollector struct {
+	stat func() *pgxpool.Stat
+
+	acquired     *prometheus.Desc
+	idle         *prometheus.Desc
+	total        *prometheus.Desc
+	max          *prometheus.Desc
+	constructing *prometheus.Desc
+	acquires     *prometheus.Desc
+	acquireTime  *prometheus.Desc
+	emptyWaits   *prometheus.Desc
+	waitTime     *prometheus.Desc
+	canceled     *prometheus.Desc
+}
+
+// NewPoolCollector — метрики пула соединений: занятые, свободные, ожидание свободного соединения
+func NewPoolCollector(stat func() *pgxpool.Stat) prometheus.Collector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+	}
+
+	return &poolCollector{
+		stat:         stat,
+		acquired:     desc("acquired_conns", "Connections currently in use."),
+		idle:         desc("idle_conns", "Idle connections in the pool."),
+		total:        desc("total_conns", "All connections in the pool."),
+		max:          desc("max_conns", "Maximum pool size."),
+		constructing: desc("constructing_conns", "Connections being established."),
+		acquires:     desc("acquires_total", "Successful connection acquires."),
+		acquireTime:  desc("acquire_duration_seconds_total", "Total time spent acquiring connections."),
+		emptyWaits:   desc("empty_acquires_total", "Acquires that had to wait because the pool was empty."),
+		waitTime:     desc("empty_acquire_wait_seconds_total", "Total time spent waiting for a free connection."),
+		canceled:     desc("canceled_acquires_total", "Acquires canceled by context."),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquired
+	ch <- c.idle
+	ch <- c.total
+	ch <- c.max
+	ch <- c.constructing
+	ch <- c.acquires
+	ch <- c.acquireTime
+	ch <- c.emptyWaits
+	ch <- c.waitTime
+	ch <- c.canceled
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.stat()
+
+	ch <- prometheus.MustNewConstMetric(c.acquired, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.total, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.max, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.constructing, prometheus.GaugeValue, float64(stat.ConstructingConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquires, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireTime, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.emptyWaits, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.waitTime, prometheus.CounterValue, stat.EmptyAcquireWaitTime().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.canceled, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+}
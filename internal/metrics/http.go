@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"awesomeProject1/pkg/httpHelpers"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type httpMetrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	inFlight prometheus.Gauge
+}
+
+func newHttpMetrics(registry *prometheus.Registry) *httpMetrics {
+	m := &httpMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by route template, method and status code.",
+		}, []string{"method", "route", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by route template, method and status code.",
+			Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		}, []string{"method", "route", "status"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "http_requests_in_flight",
+			Help:      "HTTP requests being served, including open SSE streams.",
+		}),
+	}
+
+	registry.MustRegister(m.requests, m.duration, m.inFlight)
+	return m
+}
+
+// Middleware считает запросы и их длительность. Маршрут берётся из шаблона mux (/subscription/{id}),
+// чтобы id не раздували число рядов. Подключается через router.Use, поэтому видит только найденные маршруты
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, ok := httpHelpers.RouteTemplate(r)
+		if !ok {
+			route = "unknown"
+		}
+
+		m.http.inFlight.Inc()
+		defer m.http.inFlight.Dec()
+
+		recorder := httpHelpers.NewStatusRecorder(w)
+		start := time.Now()
+		next.ServeHTTP(recorder, r)
+
+		status := strconv.Itoa(recorder.Status)
+		m.http.requests.WithLabelValues(r.Method, route, status).Inc()
+		m.http.duration.WithLabelValues(r.Method, route, status).Observe(time.Since(start).Seconds())
+	})
+}
@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "subscriptions"
+
+// Metrics — реестр метрик сервиса. Свой реестр вместо глобального, чтобы в /metrics попадало только нужное
+type Metrics struct {
+	registry *prometheus.Registry
+	http     *httpMetrics
+	db       *dbMetrics
+}
+
+func New() *Metrics {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return &Metrics{
+		registry: registry,
+		http:     newHttpMetrics(registry),
+		db:       newDbMetrics(registry),
+	}
+}
+
+// Register добавляет коллектор, например статистику пула или бизнес-метрики
+func (m *Metrics) Register(collector prometheus.Collector) {
+	m.registry.MustRegister(collector)
+}
+
+// Handler отдаёт метрики в формате Prometheus
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
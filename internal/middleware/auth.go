@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"awesomeProject1/internal/auth"
+	"awesomeProject1/pkg/httpHelpers"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+const ErrorPermissionRequired = "Permission %s is required"
+
+// Authenticate пропускает только запросы, прошедшие один из способов аутентификации, и кладёт
+// субъекта в контекст запроса. Без данных и с неподходящими данными отвечает 401 с WWW-Authenticate
+func Authenticate(chain auth.Chain) func(http.Handler) http.Handler {
+	challenges := chain.Challenges()
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, err := chain.Authenticate(r.Context(), r.Header)
+			if err != nil {
+				respondAuthError(w, r, challenges, err)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.Attach(r.Context(), principal)))
+		})
+	}
+}
+
+// RequirePermission пропускает субъектов, у которых есть хотя бы одно из permissions, ставится после Authenticate.
+// Точную проверку, например чьи подписки доступны, делают сервисы
+func RequirePermission(permissions ...string) func(http.Handler) http.Handler {
+	message := fmt.Sprintf(ErrorPermissionRequired, strings.Join(permissions, " or "))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := auth.FromContext(r.Context())
+			if !ok || !slices.ContainsFunc(permissions, principal.Can) {
+				httpHelpers.RespondError(w, r, http.StatusForbidden, message)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func respondAuthError(w http.ResponseWriter, r *http.Request, challenges []string, err error) {
+	sErr := &httpHelpers.ServiceError{Code: http.StatusUnauthorized, Err: err}
+
+	switch {
+	case errors.Is(err, auth.ErrNoCredentials):
+		sErr.Message = auth.ErrorUnauthenticated
+		sErr.Err = nil
+	case errors.Is(err, auth.ErrInvalidCredentials):
+		sErr.Message = auth.ErrorInvalidCredentials
+	default:
+		// Хранилище ключей недоступно — это не ошибка клиента
+		httpHelpers.RespondServiceError(w, r, &httpHelpers.ServiceError{Code: http.StatusInternalServerError, Message: httpHelpers.Error500, Err: err})
+		return
+	}
+
+	for _, challenge := range challenges {
+		w.Header().Add("WWW-Authenticate", challenge)
+	}
+	httpHelpers.RespondServiceError(w, r, sErr)
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"awesomeProject1/internal/auth"
+	"awesomeProject1/internal/ratelimit"
+	"awesomeProject1/internal/tenant"
+	"awesomeProject1/pkg/httpHelpers"
+	"awesomeProject1/pkg/validator"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSConfig — какие сайты могут обращаться к API из браузера, перечитывается без перезапуска
+type CORSConfig struct {
+	// AllowedOrigins — точные значения Origin или "*", пустой список выключает CORS
+	AllowedOrigins []string      `yaml:"allowed_origins"`
+	AllowedMethods []string      `yaml:"allowed_methods"`
+	AllowedHeaders []string      `yaml:"allowed_headers"`
+	MaxAge         time.Duration `yaml:"max_age"`
+}
+
+func NewCORSConfig() *CORSConfig {
+	return &CORSConfig{
+		AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+		AllowedHeaders: []string{"Content-Type", auth.AuthorizationHeader, auth.APIKeyHeader, "If-Match", "If-None-Match", IdempotencyKeyHeader, httpHelpers.RequestIDHeader, "Last-Event-ID", tenant.Header},
+		MaxAge:         10 * time.Minute,
+	}
+}
+
+// Заголовки ответа, которые браузер отдаёт скрипту
+var corsExposedHeaders = strings.Join([]string{
+	"ETag", "Location", IdempotentReplayedHeader, httpHelpers.RequestIDHeader,
+	ratelimit.HeaderLimit, ratelimit.HeaderRemaining, ratelimit.HeaderReset, ratelimit.HeaderPolicy, ratelimit.HeaderRetryAfter,
+}, ", ")
+
+func (c *CORSConfig) allowed(origin string) bool {
+	return slices.Contains(c.AllowedOrigins, "*") || slices.Contains(c.AllowedOrigins, origin)
+}
+
+// Validate проверяет значения, имена полей — ключи секции cors
+func (c *CORSConfig) Validate() []validator.FieldError {
+	v := validator.New()
+	for i, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			continue
+		}
+		parsed, err := url.Parse(origin)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" || parsed.Path != "" {
+			v.AddError(fmt.Sprintf("allowed_origins[%d]", i), validator.CodeInvalidFormat, fmt.Sprintf("must be scheme://host[:port] or *, got %q", origin))
+		}
+	}
+	if c.MaxAge < 0 {
+		v.AddError("max_age", validator.CodeTooSmall, "must not be negative")
+	}
+	return v.GetErrors()
+}
+
+// CORS отвечает на preflight запросы и добавляет заголовки CORS для разрешённых Origin.
+// Оборачивает весь роутер: preflight OPTIONS не совпадает ни с одним маршрутом и до middleware роутера не доходит
+func CORS(current func() *CORSConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			config := current()
+			w.Header().Add("Vary", "Origin")
+			if !config.allowed(origin) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Expose-Headers", corsExposedHeaders)
+
+			if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("Access-Control-Allow-Methods", strings.Join(config.AllowedMethods, ", "))
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(config.AllowedHeaders, ", "))
+			if config.MaxAge > 0 {
+				w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(config.MaxAge.Seconds())))
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"awesomeProject1/internal/repository"
+	"awesomeProject1/pkg/httpHelpers"
+	"awesomeProject1/pkg/logger"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	errorIdempotencyMismatch  = "Idempotency-Key was already used with a different request body"
+	errorIdempotencyInProcess = "Request with this Idempotency-Key is still being processed, retry later"
+
+	// idempotencyLease — на сколько ключ занимается, пока запрос обрабатывается. Больше http.write_timeout,
+	// чтобы идущий запрос не выполнился повторно, но если процесс упадёт, ключ освободится через lease, а не через ttl
+	idempotencyLease = time.Minute
+)
+
+// Заголовки ответа, которые сохраняются вместе с телом и отдаются при повторе
+var replayedHeaders = []string{"Content-Type", "ETag", "Location"}
+
+// Idempotency сохраняет ответ на запрос с заголовком Idempotency-Key на время ttl.
+// Ключи у каждого субъекта свои, поэтому ставится после Authenticate.
+// Повтор с тем же телом получает сохранённый ответ, с другим телом — 422
+func Idempotency(repo repository.IIdempotencyRepository, ttl time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if len(key) > maxIdempotencyKeyLength {
+				httpHelpers.RespondError(w, r, http.StatusBadRequest, "Idempotency-Key is too long")
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				httpHelpers.RespondError(w, r, http.StatusBadRequest, httpHelpers.ErrorParse)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			hash := requestHash(r, body)
+
+			reserved, err := repo.Reserve(r.Context(), key, hash, idempotencyLease)
+			if err != nil {
+				logger.FromContext(r.Context()).WithError(err).Error("IdempotencyMiddleware -> Reserve")
+				httpHelpers.RespondError(w, r, http.StatusInternalServerError, httpHelpers.Error500)
+				return
+			}
+
+			if !reserved {
+				replay(w, r, repo, key, hash)
+				return
+			}
+
+			recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+
+			// Запрос не завершился на сервере — освобождаем ключ, чтобы клиент мог повторить
+			ctx := context.WithoutCancel(r.Context())
+			if recorder.status >= http.StatusInternalServerError {
+				if err := repo.Delete(ctx, key); err != nil {
+					logger.FromContext(r.Context()).WithError(err).Error("IdempotencyMiddleware -> Delete")
+				}
+				return
+			}
+
+			headers := make(map[string]string)
+			for _, name := range replayedHeaders {
+				if value := recorder.Header().Get(name); value != "" {
+					headers[name] = value
+				}
+			}
+
+			if err := repo.SaveResponse(ctx, key, recorder.status, headers, recorder.body.Bytes(), ttl); err != nil {
+				logger.FromContext(r.Context()).WithError(err).Error("IdempotencyMiddleware -> SaveResponse")
+			}
+		})
+	}
+}
+
+func replay(w http.ResponseWriter, r *http.Request, repo repository.IIdempotencyRepository, key, hash string) {
+	record, ok, err := repo.FindByKey(r.Context(), key)
+	if err != nil {
+		logger.FromContext(r.Context()).WithError(err).Error("IdempotencyMiddleware -> FindByKey")
+		httpHelpers.RespondError(w, r, http.StatusInternalServerError, httpHelpers.Error500)
+		return
+	}
+
+	// Ключ могли освободить между Reserve и FindByKey
+	if !ok {
+		httpHelpers.RespondError(w, r, http.StatusConflict, errorIdempotencyInProcess)
+		return
+	}
+
+	if record.RequestHash != hash {
+		httpHelpers.RespondError(w, r, http.StatusUnprocessableEntity, errorIdempotencyMismatch)
+		return
+	}
+
+	if !record.IsCompleted() {
+		httpHelpers.RespondError(w, r, http.StatusConflict, errorIdempotencyInProcess)
+		return
+	}
+
+	for name, value := range record.Headers {
+		w.Header().Set(name, value)
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(record.StatusCode)
+	w.Write(record.ResponseBody)
+}
+
+func requestHash(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder пишет ответ клиенту и одновременно запоминает статус и тело
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"awesomeProject1/internal/auth"
+	"awesomeProject1/internal/ratelimit"
+	"awesomeProject1/pkg/httpHelpers"
+	"awesomeProject1/pkg/logger"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// RateLimit ограничивает частоту запросов клиента: ключа API, пользователя или IP для анонимных запросов.
+// Ставится после Authenticate. Лимит выбирается по шаблону маршрута, поэтому /subscription/{id} — один маршрут.
+// Если хранилище вёдер недоступно, запрос пропускается: ограничение не должно ронять API
+func RateLimit(limiter *ratelimit.Limiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := r.URL.Path
+			if current := mux.CurrentRoute(r); current != nil {
+				if template, err := current.GetPathTemplate(); err == nil {
+					route = template
+				}
+			}
+
+			principal, _ := auth.FromContext(r.Context())
+			client := ratelimit.ClientKey(r.Context(), principal, clientIP(r, limiter.Config().TrustForwardedFor))
+
+			result, err := limiter.Allow(r.Context(), r.Method, route, client)
+			if err != nil {
+				logger.FromContext(r.Context()).WithError(err).Warn("RateLimitMiddleware -> Allow")
+				next.ServeHTTP(w, r)
+				return
+			}
+			if result == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			for name, value := range result.Headers() {
+				w.Header().Set(name, value)
+			}
+			if !result.Allowed {
+				httpHelpers.RespondError(w, r, http.StatusTooManyRequests, result.Message())
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RateLimitIP ограничивает частоту запросов с одного IP ведром rate_limit.per_ip. Ставится перед Authenticate,
+// чтобы перебор ключей и запросы с неверными учётными данными не доходили до базы. Заголовки RateLimit-*
+// отдаются только при отказе, иначе их выставляет RateLimit по лимиту клиента
+func RateLimitIP(limiter *ratelimit.Limiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			result, err := limiter.AllowIP(r.Context(), clientIP(r, limiter.Config().TrustForwardedFor))
+			if err != nil {
+				logger.FromContext(r.Context()).WithError(err).Warn("RateLimitIPMiddleware -> AllowIP")
+				next.ServeHTTP(w, r)
+				return
+			}
+			if result == nil || result.Allowed {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			for name, value := range result.Headers() {
+				w.Header().Set(name, value)
+			}
+			httpHelpers.RespondError(w, r, http.StatusTooManyRequests, result.Message())
+		})
+	}
+}
+
+// clientIP — адрес клиента. За прокси это последний адрес X-Forwarded-For: его дописал сам прокси,
+// более ранние адреса клиент может подставить любые
+func clientIP(r *http.Request, trustForwardedFor bool) string {
+	if trustForwardedFor {
+		if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+			addrs := strings.Split(forwarded[len(forwarded)-1], ",")
+			if ip := strings.TrimSpace(addrs[len(addrs)-1]); ip != "" {
+				return ip
+			}
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"awesomeProject1/pkg/httpHelpers"
+	"awesomeProject1/pkg/logger"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+)
+
+// RequestID принимает id запроса из X-Request-ID или выдаёт новый и возвращает его в ответе.
+// Тот же id попадает в поле id ответа с ошибкой и в логгер запроса, поэтому по id из ошибки
+// клиента находятся все записи лога этого запроса. Принимаются только UUID, иначе id выдаётся заново
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := uuid.Parse(r.Header.Get(httpHelpers.RequestIDHeader))
+		if err != nil || id == uuid.Nil {
+			id = uuid.New()
+		}
+		w.Header().Set(httpHelpers.RequestIDHeader, id.String())
+
+		route, ok := httpHelpers.RouteTemplate(r)
+		if !ok {
+			route = r.URL.Path
+		}
+
+		fields := logger.Fields{
+			"request_id": id.String(),
+			"method":     r.Method,
+			"route":      route,
+		}
+
+		span := trace.SpanFromContext(r.Context())
+		if span.SpanContext().IsValid() {
+			fields["trace_id"] = span.SpanContext().TraceID().String()
+			span.SetAttributes(attribute.String("request.id", id.String()))
+		}
+
+		ctx := httpHelpers.WithRequestID(r.Context(), id)
+		ctx = logger.WithContext(ctx, logger.Log.WithFields(fields))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
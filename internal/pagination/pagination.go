@@ -0,0 +1,39 @@
+package pagination
+
+import "awesomeProject1/pkg/validator"
+
+// Config — размер страницы списков в REST, gRPC и GraphQL, перечитывается без перезапуска
+type Config struct {
+	// DefaultLimit — размер страницы, если клиент не передал limit
+	DefaultLimit int `yaml:"default_limit"`
+	// MaxLimit — больше этого за один запрос не отдаётся
+	MaxLimit int `yaml:"max_limit"`
+}
+
+func NewConfig() *Config {
+	return &Config{
+		DefaultLimit: 10,
+		MaxLimit:     100,
+	}
+}
+
+// Limit возвращает размер страницы для запрошенного limit: DefaultLimit, если он не задан,
+// и не больше MaxLimit
+func (c *Config) Limit(requested int) int {
+	if requested <= 0 {
+		return c.DefaultLimit
+	}
+	return min(requested, c.MaxLimit)
+}
+
+// Validate проверяет значения, имена полей — ключи секции pagination
+func (c *Config) Validate() []validator.FieldError {
+	v := validator.New()
+	if c.DefaultLimit <= 0 {
+		v.AddError("default_limit", validator.CodeTooSmall, "must be greater than 0")
+	}
+	if c.MaxLimit < c.DefaultLimit {
+		v.AddError("max_limit", validator.CodeInvalidRange, "must not be less than default_limit")
+	}
+	return v.GetErrors()
+}
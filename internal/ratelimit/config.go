@@ -0,0 +1,119 @@
+package ratelimit
+
+import (
+	"awesomeProject1/pkg/validator"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Где хранятся вёдра токенов
+const (
+	// StoreMemory — в памяти процесса, у каждого экземпляра свои лимиты
+	StoreMemory = "memory"
+	// StorePostgres — в таблице rate_limit_buckets, лимит общий для всех экземпляров
+	StorePostgres = "postgres"
+)
+
+// Config — ограничение частоты запросов. Перечитывается без перезапуска, кроме store
+type Config struct {
+	Enabled bool   `yaml:"enabled"`
+	Store   string `yaml:"store"`
+	// TrustForwardedFor — брать IP клиента из последнего адреса X-Forwarded-For. Включать только за своим прокси
+	TrustForwardedFor bool `yaml:"trust_forwarded_for"`
+	// PerIP — лимит на IP, который проверяется до аутентификации: ограничивает перебор ключей
+	// и нагрузку от запросов с неверными учётными данными. Общий для всех маршрутов
+	PerIP Limit `yaml:"per_ip"`
+	// Default — общий лимит клиента на маршруты без отдельной настройки
+	Default Limit `yaml:"default"`
+	// Routes — отдельные лимиты по шаблону маршрута ("/api/v1/subscriptions/total"), методу и шаблону
+	// ("GET /api/v1/subscription/{id}") или полному имени метода gRPC. У каждого маршрута своё ведро
+	Routes map[string]Limit `yaml:"routes"`
+}
+
+// Limit — ведро на Burst запросов, которое пополняется на Requests запросов за Period.
+// Requests = 0 снимает ограничение
+type Limit struct {
+	Requests int           `yaml:"requests"`
+	Period   time.Duration `yaml:"period"`
+	// Burst — сколько запросов можно сделать подряд, 0 — столько же, сколько Requests
+	Burst int `yaml:"burst"`
+}
+
+func NewConfig() *Config {
+	return &Config{
+		Enabled: true,
+		Store:   StoreMemory,
+		PerIP:   Limit{Requests: 1200, Period: time.Minute, Burst: 200},
+		Default: Limit{Requests: 600, Period: time.Minute, Burst: 100},
+		Routes: map[string]Limit{
+			"/api/v1/subscriptions/total":                      {Requests: 30, Period: time.Minute, Burst: 10},
+			"/api/v1/graphql":                                  {Requests: 120, Period: time.Minute, Burst: 20},
+			"/subscription.v1.SubscriptionService/GetTotalSum": {Requests: 30, Period: time.Minute, Burst: 10},
+			"/subscription.v1.SubscriptionService/Export":      {Requests: 5, Period: time.Minute, Burst: 2},
+		},
+	}
+}
+
+// Unlimited сообщает, что лимит не ограничивает запросы
+func (l Limit) Unlimited() bool {
+	return l.Requests == 0
+}
+
+// Capacity — ёмкость ведра
+func (l Limit) Capacity() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Requests
+}
+
+// Rate — сколько токенов добавляется в секунду
+func (l Limit) Rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// limit находит лимит запроса и имя ведра: шаблон из Routes или "default"
+func (c *Config) limit(method, route string) (Limit, string) {
+	if method != "" {
+		if limit, ok := c.Routes[method+" "+route]; ok {
+			return limit, method + " " + route
+		}
+	}
+	if limit, ok := c.Routes[route]; ok {
+		return limit, route
+	}
+	return c.Default, "default"
+}
+
+// Validate проверяет значения, имена полей — ключи секции rate_limit
+func (c *Config) Validate() []validator.FieldError {
+	v := validator.New()
+	if c.Store != StoreMemory && c.Store != StorePostgres {
+		v.AddError("store", validator.CodeUnsupportedType, fmt.Sprintf("must be %s or %s, got %q", StoreMemory, StorePostgres, c.Store))
+	}
+	c.PerIP.validate(v, "per_ip")
+	c.Default.validate(v, "default")
+
+	routes := make([]string, 0, len(c.Routes))
+	for route := range c.Routes {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+	for _, route := range routes {
+		c.Routes[route].validate(v, "routes."+route)
+	}
+	return v.GetErrors()
+}
+
+func (l Limit) validate(v *validator.Validator, field string) {
+	if l.Requests < 0 {
+		v.AddError(field+".requests", validator.CodeTooSmall, "must not be negative")
+	}
+	if l.Burst < 0 {
+		v.AddError(field+".burst", validator.CodeTooSmall, "must not be negative")
+	}
+	if l.Requests > 0 && l.Period <= 0 {
+		v.AddError(field+".period", validator.CodeTooSmall, "must be a positive duration")
+	}
+}
@@ -0,0 +1,148 @@
+package ratelimit
+
+import (
+	"awesomeProject1/internal/auth"
+	"awesomeProject1/internal/tenant"
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const ErrorRateLimited = "Rate limit exceeded, retry in %s seconds"
+
+// Store хранит вёдра токенов
+type Store interface {
+	// Take забирает токен из ведра key ёмкостью capacity, которое пополняется на rate токенов в секунду.
+	// Возвращает, хватило ли токена, и сколько токенов осталось
+	Take(ctx context.Context, key string, capacity int, rate float64) (bool, float64, error)
+}
+
+// Result — решение по запросу и данные для заголовков RateLimit-*
+type Result struct {
+	Limit     Limit
+	Allowed   bool
+	Remaining int
+	// RetryAfter — через сколько появится токен для отклонённого запроса
+	RetryAfter time.Duration
+	// Reset — через сколько ведро наполнится целиком
+	Reset time.Duration
+}
+
+type Limiter struct {
+	store   Store
+	current func() *Config
+}
+
+// NewLimiter создаёт ограничитель, current возвращает действующую конфигурацию и читается на каждый запрос
+func NewLimiter(store Store, current func() *Config) *Limiter {
+	return &Limiter{store: store, current: current}
+}
+
+// Config — действующая конфигурация
+func (l *Limiter) Config() *Config {
+	return l.current()
+}
+
+// Allow забирает токен клиента client для маршрута route. nil без ошибки — запрос не ограничивается
+func (l *Limiter) Allow(ctx context.Context, method, route, client string) (*Result, error) {
+	config := l.current()
+	if !config.Enabled {
+		return nil, nil
+	}
+
+	limit, bucket := config.limit(method, route)
+	return l.allow(ctx, limit, bucket+"|"+client)
+}
+
+// AllowIP забирает токен адреса ip из ведра per_ip. Вызывается до аутентификации
+func (l *Limiter) AllowIP(ctx context.Context, ip string) (*Result, error) {
+	config := l.current()
+	if !config.Enabled {
+		return nil, nil
+	}
+
+	return l.allow(ctx, config.PerIP, "per_ip|ip:"+ip)
+}
+
+func (l *Limiter) allow(ctx context.Context, limit Limit, key string) (*Result, error) {
+	if limit.Unlimited() {
+		return nil, nil
+	}
+
+	capacity, rate := limit.Capacity(), limit.Rate()
+	allowed, tokens, err := l.store.Take(ctx, key, capacity, rate)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		Limit:     limit,
+		Allowed:   allowed,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     seconds((float64(capacity) - tokens) / rate),
+	}
+	if !allowed {
+		result.RetryAfter = seconds((1 - tokens) / rate)
+	}
+	return result, nil
+}
+
+// ClientKey выбирает, чей лимит расходует запрос: ключа API, пользователя, субъекта токена или IP.
+// Ключ начинается с тенанта запроса из ctx: субъекты и пользователи разных тенантов не делят ведро
+func ClientKey(ctx context.Context, principal *auth.Principal, ip string) string {
+	prefix := ""
+	if id, ok := tenant.FromContext(ctx); ok {
+		prefix = "tenant:" + id.String() + "|"
+	}
+
+	switch {
+	case principal == nil || principal.Method == auth.MethodNone:
+		return prefix + "ip:" + ip
+	case principal.Method == auth.MethodAPIKey:
+		return prefix + principal.Subject
+	case principal.UserID != uuid.Nil:
+		return prefix + "user:" + principal.UserID.String()
+	default:
+		return prefix + "sub:" + principal.Subject
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Max(s, 0) * float64(time.Second))
+}
+
+// Заголовки ответа по draft-ietf-httpapi-ratelimit-headers, значения времени — целые секунды с округлением вверх
+const (
+	HeaderLimit      = "RateLimit-Limit"
+	HeaderRemaining  = "RateLimit-Remaining"
+	HeaderReset      = "RateLimit-Reset"
+	HeaderPolicy     = "RateLimit-Policy"
+	HeaderRetryAfter = "Retry-After"
+)
+
+// Headers — заголовки RateLimit-* и, если запрос отклонён, Retry-After
+func (r *Result) Headers() map[string]string {
+	headers := map[string]string{
+		HeaderLimit:     strconv.Itoa(r.Limit.Capacity()),
+		HeaderRemaining: strconv.Itoa(r.Remaining),
+		HeaderReset:     ceilSeconds(r.Reset),
+		HeaderPolicy:    fmt.Sprintf("%d;w=%s", r.Limit.Requests, ceilSeconds(r.Limit.Period)),
+	}
+	if !r.Allowed {
+		headers[HeaderRetryAfter] = ceilSeconds(r.RetryAfter)
+	}
+	return headers
+}
+
+// Message — текст ошибки для отклонённого запроса
+func (r *Result) Message() string {
+	return fmt.Sprintf(ErrorRateLimited, ceilSeconds(r.RetryAfter))
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,180 @@
+package ratelimit
+
+import (
+	"awesomeProject1/internal/auth"
+	"awesomeProject1/internal/tenant"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func newTestLimiter(config *Config) (*Limiter, *testClock) {
+	store, clock := newTestStore()
+	return NewLimiter(store, func() *Config { return config }), clock
+}
+
+func testConfig() *Config {
+	return &Config{
+		Enabled: true,
+		PerIP:   Limit{Requests: 60, Period: time.Minute, Burst: 3},
+		Default: Limit{Requests: 60, Period: time.Minute, Burst: 2},
+		Routes: map[string]Limit{
+			"/api/v1/subscriptions/total":      {Requests: 60, Period: time.Minute, Burst: 1},
+			"DELETE /api/v1/subscription/{id}": {Requests: 0},
+		},
+	}
+}
+
+func TestLimiterAllow(t *testing.T) {
+	ctx := context.Background()
+	limiter, clock := newTestLimiter(testConfig())
+
+	for i := 0; i < 2; i++ {
+		result, err := limiter.Allow(ctx, "GET", "/api/v1/subscriptions", "ip:1.2.3.4")
+		if err != nil {
+			t.Fatalf("Allow() error = %v", err)
+		}
+		if !result.Allowed {
+			t.Fatalf("request %d rejected within burst", i)
+		}
+	}
+
+	result, _ := limiter.Allow(ctx, "GET", "/api/v1/subscriptions", "ip:1.2.3.4")
+	if result.Allowed {
+		t.Fatal("request over burst allowed")
+	}
+	// Лимит 60 в минуту — токен в секунду
+	if result.RetryAfter != time.Second || result.Reset != 2*time.Second || result.Remaining != 0 {
+		t.Errorf("RetryAfter, Reset, Remaining = %v, %v, %d, want 1s, 2s, 0", result.RetryAfter, result.Reset, result.Remaining)
+	}
+
+	clock.Advance(time.Second)
+	if result, _ := limiter.Allow(ctx, "GET", "/api/v1/subscriptions", "ip:1.2.3.4"); !result.Allowed {
+		t.Error("request rejected after refill")
+	}
+}
+
+func TestLimiterRouteBuckets(t *testing.T) {
+	ctx := context.Background()
+	limiter, _ := newTestLimiter(testConfig())
+	client := "user:1"
+
+	if result, _ := limiter.Allow(ctx, "GET", "/api/v1/subscriptions/total", client); !result.Allowed || result.Limit.Capacity() != 1 {
+		t.Fatalf("route limit not applied: %+v", result)
+	}
+	if result, _ := limiter.Allow(ctx, "GET", "/api/v1/subscriptions/total", client); result.Allowed {
+		t.Fatal("route bucket not exhausted")
+	}
+
+	// Маршрут со своим лимитом не расходует общее ведро клиента
+	if result, _ := limiter.Allow(ctx, "GET", "/api/v1/subscriptions", client); !result.Allowed || result.Remaining != 1 {
+		t.Fatalf("default bucket affected by route bucket: %+v", result)
+	}
+
+	// Лимит по методу и шаблону важнее общего, Requests = 0 снимает ограничение
+	if result, err := limiter.Allow(ctx, "DELETE", "/api/v1/subscription/{id}", client); result != nil || err != nil {
+		t.Errorf("unlimited route: Allow() = %+v, %v, want nil, nil", result, err)
+	}
+}
+
+func TestLimiterDisabled(t *testing.T) {
+	config := testConfig()
+	config.Enabled = false
+	limiter, _ := newTestLimiter(config)
+
+	if result, err := limiter.Allow(context.Background(), "GET", "/api/v1/subscriptions", "ip:1.2.3.4"); result != nil || err != nil {
+		t.Errorf("Allow() = %+v, %v, want nil, nil", result, err)
+	}
+	if result, err := limiter.AllowIP(context.Background(), "1.2.3.4"); result != nil || err != nil {
+		t.Errorf("AllowIP() = %+v, %v, want nil, nil", result, err)
+	}
+}
+
+func TestLimiterAllowIP(t *testing.T) {
+	ctx := context.Background()
+	limiter, _ := newTestLimiter(testConfig())
+
+	for i := 0; i < 3; i++ {
+		if result, _ := limiter.AllowIP(ctx, "1.2.3.4"); !result.Allowed {
+			t.Fatalf("request %d rejected within per_ip burst", i)
+		}
+	}
+	if result, _ := limiter.AllowIP(ctx, "1.2.3.4"); result.Allowed {
+		t.Fatal("request over per_ip burst allowed")
+	}
+
+	// Ведро per_ip не общее с ведром анонимного клиента того же адреса и с другими адресами
+	if result, _ := limiter.Allow(ctx, "GET", "/api/v1/subscriptions", "ip:1.2.3.4"); !result.Allowed {
+		t.Error("client bucket shares tokens with per_ip bucket")
+	}
+	if result, _ := limiter.AllowIP(ctx, "5.6.7.8"); !result.Allowed {
+		t.Error("per_ip bucket shared between addresses")
+	}
+}
+
+func TestClientKey(t *testing.T) {
+	userID := uuid.MustParse("60601fee-2bf1-4721-ae6f-7636e79a0cba")
+	tenantID := uuid.MustParse("0b5a4f44-5a6a-4d16-9c62-4c1a4d6f3b01")
+
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		tenant    bool
+		want      string
+	}{
+		{name: "no principal", want: "ip:1.2.3.4"},
+		{name: "anonymous", principal: &auth.Principal{Method: auth.MethodNone}, want: "ip:1.2.3.4"},
+		{name: "api key", principal: &auth.Principal{Method: auth.MethodAPIKey, Subject: "api_key:1", UserID: userID}, want: "api_key:1"},
+		{name: "user token", principal: &auth.Principal{Method: auth.MethodJWT, Subject: "alice", UserID: userID}, want: "user:" + userID.String()},
+		{name: "service token", principal: &auth.Principal{Method: auth.MethodJWT, Subject: "billing"}, want: "sub:billing"},
+		{
+			name:      "tenant prefix",
+			principal: &auth.Principal{Method: auth.MethodJWT, Subject: "billing"},
+			tenant:    true,
+			want:      "tenant:" + tenantID.String() + "|sub:billing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.tenant {
+				ctx = tenant.WithID(ctx, tenantID)
+			}
+			if got := ClientKey(ctx, tt.principal, "1.2.3.4"); got != tt.want {
+				t.Errorf("ClientKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResultHeaders(t *testing.T) {
+	result := &Result{
+		Limit:      Limit{Requests: 60, Period: time.Minute, Burst: 10},
+		Allowed:    false,
+		Remaining:  0,
+		RetryAfter: 1500 * time.Millisecond,
+		Reset:      9500 * time.Millisecond,
+	}
+
+	want := map[string]string{
+		HeaderLimit:      "10",
+		HeaderRemaining:  "0",
+		HeaderReset:      "10",
+		HeaderPolicy:     "60;w=60",
+		HeaderRetryAfter: "2",
+	}
+	got := result.Headers()
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("%s = %q, want %q", key, got[key], value)
+		}
+	}
+
+	result.Allowed = true
+	if _, ok := result.Headers()[HeaderRetryAfter]; ok {
+		t.Error("Retry-After set for allowed request")
+	}
+}
@@ -0,0 +1,103 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// testClock — часы, которые двигает тест
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestStore() (*MemoryStore, *testClock) {
+	clock := &testClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	store := NewMemoryStore()
+	store.now = clock.Now
+	return store, clock
+}
+
+func TestMemoryStoreTake(t *testing.T) {
+	ctx := context.Background()
+	store, clock := newTestStore()
+
+	// Ведро на 3 токена, один токен в секунду
+	for i, wantTokens := range []float64{2, 1, 0} {
+		allowed, tokens, err := store.Take(ctx, "key", 3, 1)
+		if err != nil {
+			t.Fatalf("Take() error = %v", err)
+		}
+		if !allowed || tokens != wantTokens {
+			t.Fatalf("request %d: Take() = %v, %v, want true, %v", i, allowed, tokens, wantTokens)
+		}
+	}
+
+	if allowed, _, _ := store.Take(ctx, "key", 3, 1); allowed {
+		t.Fatal("Take() on empty bucket allowed")
+	}
+
+	clock.Advance(500 * time.Millisecond)
+	if allowed, tokens, _ := store.Take(ctx, "key", 3, 1); allowed || tokens != 0.5 {
+		t.Fatalf("Take() after half a token = %v, %v, want false, 0.5", allowed, tokens)
+	}
+
+	clock.Advance(500 * time.Millisecond)
+	if allowed, tokens, _ := store.Take(ctx, "key", 3, 1); !allowed || tokens != 0 {
+		t.Fatalf("Take() after a full token = %v, %v, want true, 0", allowed, tokens)
+	}
+}
+
+func TestMemoryStoreRefillIsCapped(t *testing.T) {
+	ctx := context.Background()
+	store, clock := newTestStore()
+
+	store.Take(ctx, "key", 3, 1)
+	clock.Advance(time.Hour)
+
+	if allowed, tokens, _ := store.Take(ctx, "key", 3, 1); !allowed || tokens != 2 {
+		t.Fatalf("Take() after long idle = %v, %v, want true, 2", allowed, tokens)
+	}
+}
+
+func TestMemoryStoreKeysAreIndependent(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newTestStore()
+
+	if allowed, _, _ := store.Take(ctx, "a", 1, 1); !allowed {
+		t.Fatal("Take(a) rejected")
+	}
+	if allowed, _, _ := store.Take(ctx, "a", 1, 1); allowed {
+		t.Fatal("second Take(a) allowed")
+	}
+	if allowed, _, _ := store.Take(ctx, "b", 1, 1); !allowed {
+		t.Fatal("Take(b) rejected after bucket a is empty")
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	ctx := context.Background()
+	store, clock := newTestStore()
+
+	// Ведру "slow" нужно 10 секунд на токен, "fast" наполняется за секунду
+	store.Take(ctx, "slow", 2, 0.1)
+	store.Take(ctx, "fast", 2, 1)
+
+	clock.Advance(time.Second)
+	store.sweep()
+
+	if _, ok := store.buckets["fast"]; ok {
+		t.Error("full bucket was not swept")
+	}
+	if _, ok := store.buckets["slow"]; !ok {
+		t.Error("bucket that is not full was swept")
+	}
+}
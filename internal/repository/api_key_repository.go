@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"awesomeProject1/internal/dto"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+type IAPIKeyRepository interface {
+	Create(ctx context.Context, key *dto.APIKey) (*dto.APIKey, error)
+	FindByHash(ctx context.Context, keyHash string) (*dto.APIKey, bool, error)
+	FindAll(ctx context.Context, offset, limit int) ([]*dto.APIKey, int, error)
+	Revoke(ctx context.Context, id uuid.UUID) (bool, error)
+	Touch(ctx context.Context, id uuid.UUID, interval time.Duration) error
+}
+
+// APIKeyRepository — запросы к ключам API. Ключи платформы без тенанта видны только
+// без ограничения тенантом в ctx, как и по политикам RLS
+type APIKeyRepository struct {
+	db DBTX
+}
+
+func NewAPIKeyRepository(db DBTX) *APIKeyRepository {
+	return &APIKeyRepository{
+		db: db,
+	}
+}
+
+const apiKeyColumns = "id, name, prefix, key_hash, user_id, roles, expires_at, revoked_at, last_used_at, created_at, tenant_id"
+
+func (c *APIKeyRepository) Create(ctx context.Context, k *dto.APIKey) (*dto.APIKey, error) {
+	ctx = withQuery(ctx, "APIKeyRepository", "Create")
+	query := `
+		INSERT INTO api_keys (name, prefix, key_hash, user_id, roles, expires_at, tenant_id)
+		VALUES ($1, $2, $3, $4, COALESCE($5::text[], '{}'), $6, $7)
+		RETURNING id, roles, created_at
+	`
+	err := c.db.QueryRow(ctx, query, k.Name, k.Prefix, k.KeyHash, k.UserID, k.Roles, k.ExpiresAt, k.TenantID).Scan(&k.ID, &k.Roles, &k.CreatedAt)
+	return k, err
+}
+
+func (c *APIKeyRepository) FindByHash(ctx context.Context, keyHash string) (*dto.APIKey, bool, error) {
+	ctx = withQuery(ctx, "APIKeyRepository", "FindByHash")
+	query := "SELECT " + apiKeyColumns + " FROM api_keys WHERE key_hash = $1 AND ($2::uuid IS NULL OR tenant_id = $2)"
+
+	item, err := scanAPIKey(c.db.QueryRow(ctx, query, keyHash, tenantFilter(ctx)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	return item, true, nil
+}
+
+func (c *APIKeyRepository) FindAll(ctx context.Context, offset, limit int) ([]*dto.APIKey, int, error) {
+	ctx = withQuery(ctx, "APIKeyRepository", "FindAll")
+	query := "SELECT " + apiKeyColumns + " FROM api_keys WHERE ($3::uuid IS NULL OR tenant_id = $3) ORDER BY created_at DESC OFFSET $1 LIMIT $2"
+
+	tenantId := tenantFilter(ctx)
+
+	rows, err := c.db.Query(ctx, query, offset, limit, tenantId)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var keys []*dto.APIKey
+	for rows.Next() {
+		item, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		keys = append(keys, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	if err := c.db.QueryRow(ctx, "SELECT COUNT(*) FROM api_keys WHERE ($1::uuid IS NULL OR tenant_id = $1)", tenantId).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	return keys, total, nil
+}
+
+// Revoke отзывает ключ. Запись остаётся, чтобы по списку было видно, когда ключ перестал действовать
+func (c *APIKeyRepository) Revoke(ctx context.Context, id uuid.UUID) (bool, error) {
+	ctx = withQuery(ctx, "APIKeyRepository", "Revoke")
+	query := "UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL AND ($2::uuid IS NULL OR tenant_id = $2)"
+	tag, err := c.db.Exec(ctx, query, id, tenantFilter(ctx))
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() != 0, nil
+}
+
+// Touch обновляет время последнего использования не чаще раза в interval,
+// чтобы каждый запрос не превращался в запись в базу
+func (c *APIKeyRepository) Touch(ctx context.Context, id uuid.UUID, interval time.Duration) error {
+	ctx = withQuery(ctx, "APIKeyRepository", "Touch")
+	query := `
+		UPDATE api_keys
+		SET last_used_at = NOW()
+		WHERE id = $1
+		  AND (last_used_at IS NULL OR last_used_at < NOW() - make_interval(secs => $2))
+		  AND ($3::uuid IS NULL OR tenant_id = $3)
+	`
+	_, err := c.db.Exec(ctx, query, id, interval.Seconds(), tenantFilter(ctx))
+	return err
+}
+
+func scanAPIKey(row pgx.Row) (*dto.APIKey, error) {
+	item := &dto.APIKey{}
+	err := row.Scan(
+		&item.ID,
+		&item.Name,
+		&item.Prefix,
+		&item.KeyHash,
+		&item.UserID,
+		&item.Roles,
+		&item.ExpiresAt,
+		&item.RevokedAt,
+		&item.LastUsedAt,
+		&item.CreatedAt,
+		&item.TenantID,
+	)
+	return item, err
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type ICalendarTokenRepository interface {
+	Save(ctx context.Context, userId uuid.UUID, tokenHash string) error
+	FindTenant(ctx context.Context, userId uuid.UUID, tokenHash string) (uuid.UUID, bool, error)
+}
+
+type CalendarTokenRepository struct {
+	db DBTX
+}
+
+func NewCalendarTokenRepository(db DBTX) *CalendarTokenRepository {
+	return &CalendarTokenRepository{
+		db: db,
+	}
+}
+
+// Save сохраняет хэш нового токена пользователя, предыдущий токен перестаёт действовать
+func (c *CalendarTokenRepository) Save(ctx context.Context, userId uuid.UUID, tokenHash string) error {
+	ctx = withQuery(ctx, "CalendarTokenRepository", "Save")
+	query := `
+		INSERT INTO calendar_tokens (user_id, token_hash, tenant_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (tenant_id, user_id) DO UPDATE
+			SET token_hash = EXCLUDED.token_hash,
+			    created_at = NOW()
+	`
+	_, err := c.db.Exec(ctx, query, userId, tokenHash, tenantFilter(ctx))
+	return err
+}
+
+// FindTenant ищет тенанта, в котором пользователю выдан токен с хэшем tokenHash.
+// Ссылка на календарь не несёт тенанта, поэтому её тенант находится по самому токену
+func (c *CalendarTokenRepository) FindTenant(ctx context.Context, userId uuid.UUID, tokenHash string) (uuid.UUID, bool, error) {
+	ctx = withQuery(ctx, "CalendarTokenRepository", "FindTenant")
+	var tenantId uuid.UUID
+	query := "SELECT tenant_id FROM calendar_tokens WHERE user_id = $1 AND token_hash = $2 AND ($3::uuid IS NULL OR tenant_id = $3)"
+	err := c.db.QueryRow(ctx, query, userId, tokenHash, tenantFilter(ctx)).Scan(&tenantId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, false, nil
+		}
+		return uuid.Nil, false, err
+	}
+
+	return tenantId, true, nil
+}
@@ -0,0 +1,26 @@
+package repository
+
+import "context"
+
+type queryKey struct{}
+
+// queryName — репозиторий и метод, которые выполняют запрос
+type queryName struct {
+	repository string
+	method     string
+}
+
+// withQuery помечает ctx именем метода репозитория. Каждый метод вызывает её первой строкой,
+// и наблюдатели запросов pgx (метрики, трассировка) получают имя из ctx запроса
+func withQuery(ctx context.Context, repository, method string) context.Context {
+	return context.WithValue(ctx, queryKey{}, queryName{repository: repository, method: method})
+}
+
+// Caller возвращает метод репозитория, выполняющий запрос с ctx: "SubscriptionRepository", "FindAll".
+// Для запросов вне репозиториев (миграции, транзакции) возвращает "other", "other"
+func Caller(ctx context.Context) (string, string) {
+	if name, ok := ctx.Value(queryKey{}).(queryName); ok {
+		return name.repository, name.method
+	}
+	return "other", "other"
+}
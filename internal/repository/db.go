@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DBTX — общие методы pgxpool.Pool и pgx.Tx, чтобы один репозиторий работал и в транзакции, и без неё
+type DBTX interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// TxRepositories — репозитории, привязанные к одной транзакции
+type TxRepositories struct {
+	Subscriptions ISubscriptionRepository
+	Outbox        IOutboxRepository
+}
+
+type ITransactor interface {
+	// InTx выполняет fn в транзакции, ошибка fn откатывает её
+	InTx(ctx context.Context, fn func(repos *TxRepositories) error) error
+}
+
+type Transactor struct {
+	db *pgxpool.Pool
+}
+
+func NewTransactor(db *pgxpool.Pool) *Transactor {
+	return &Transactor{db: db}
+}
+
+func (t *Transactor) InTx(ctx context.Context, fn func(repos *TxRepositories) error) error {
+	return pgx.BeginFunc(ctx, t.db, func(tx pgx.Tx) error {
+		return fn(&TxRepositories{
+			Subscriptions: NewSubscriptionRepository(tx),
+			Outbox:        NewOutboxRepository(tx),
+		})
+	})
+}
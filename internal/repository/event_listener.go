@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Канал LISTEN/NOTIFY, в который триггер outbox_events пишет номера новых событий
+const eventsChannel = "subscription_events"
+
+type IEventListener interface {
+	// Listen держит отдельное соединение и вызывает fn для каждого нового события в порядке коммитов.
+	// onListen вызывается после подписки на канал — до него уведомления не приходят.
+	// Возвращает ошибку при потере соединения или отмене ctx
+	Listen(ctx context.Context, onListen func(), fn func(seq int64)) error
+}
+
+type EventListener struct {
+	db *pgxpool.Pool
+}
+
+func NewEventListener(db *pgxpool.Pool) *EventListener {
+	return &EventListener{db: db}
+}
+
+func (c *EventListener) Listen(ctx context.Context, onListen func(), fn func(seq int64)) error {
+	ctx = withQuery(ctx, "EventListener", "Listen")
+	poolConn, err := c.db.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// Соединение после LISTEN нельзя возвращать в пул, поэтому забираем его себе и закрываем сами
+	conn := poolConn.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+eventsChannel); err != nil {
+		return err
+	}
+	onListen()
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		seq, err := strconv.ParseInt(notification.Payload, 10, 64)
+		if err != nil {
+			continue
+		}
+		fn(seq)
+	}
+}
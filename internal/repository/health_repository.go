@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type IHealthRepository interface {
+	Ping(ctx context.Context) error
+	SchemaVersion(ctx context.Context) (uint, bool, error)
+}
+
+type HealthRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewHealthRepository(db *pgxpool.Pool) *HealthRepository {
+	return &HealthRepository{db: db}
+}
+
+// Ping проверяет, что пул может выдать соединение и база отвечает
+func (c *HealthRepository) Ping(ctx context.Context) error {
+	ctx = withQuery(ctx, "HealthRepository", "Ping")
+	return c.db.Ping(ctx)
+}
+
+// SchemaVersion читает версию схемы из таблицы golang-migrate. dirty — миграция упала на середине
+func (c *HealthRepository) SchemaVersion(ctx context.Context) (uint, bool, error) {
+	ctx = withQuery(ctx, "HealthRepository", "SchemaVersion")
+	var version int64
+	var dirty bool
+	err := c.db.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	return uint(version), dirty, err
+}
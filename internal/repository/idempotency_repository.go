@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"awesomeProject1/internal/dto"
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+type IIdempotencyRepository interface {
+	Reserve(ctx context.Context, key, requestHash string, lease time.Duration) (bool, error)
+	FindByKey(ctx context.Context, key string) (*dto.IdempotencyRecord, bool, error)
+	SaveResponse(ctx context.Context, key string, statusCode int, headers map[string]string, body []byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+type IdempotencyRepository struct {
+	db DBTX
+}
+
+func NewIdempotencyRepository(db DBTX) *IdempotencyRepository {
+	return &IdempotencyRepository{
+		db: db,
+	}
+}
+
+// Reserve занимает ключ субъекта запроса под новый запрос на время lease. Просроченная запись с тем же ключом
+// заменяется, в том числе запрос, который не успел сохранить ответ за lease, например из-за падения процесса.
+// Возвращает false, если ключ уже занят действующей записью
+func (c *IdempotencyRepository) Reserve(ctx context.Context, key, requestHash string, lease time.Duration) (bool, error) {
+	ctx = withQuery(ctx, "IdempotencyRepository", "Reserve")
+	query := `
+		INSERT INTO idempotency_keys (key, request_hash, expires_at, tenant_id, subject)
+		VALUES ($1, $2, NOW() + make_interval(secs => $3), $4, $5)
+		ON CONFLICT (tenant_id, subject, key) DO UPDATE
+			SET request_hash = EXCLUDED.request_hash,
+			    status_code = NULL,
+			    headers = NULL,
+			    response_body = NULL,
+			    created_at = NOW(),
+			    expires_at = EXCLUDED.expires_at
+			WHERE idempotency_keys.expires_at < NOW()
+	`
+
+	tag, err := c.db.Exec(ctx, query, key, requestHash, lease.Seconds(), tenantFilter(ctx), subjectFilter(ctx))
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() != 0, nil
+}
+
+func (c *IdempotencyRepository) FindByKey(ctx context.Context, key string) (*dto.IdempotencyRecord, bool, error) {
+	ctx = withQuery(ctx, "IdempotencyRepository", "FindByKey")
+	query := `
+		SELECT key, request_hash, COALESCE(status_code, 0), headers, response_body, expires_at
+		FROM idempotency_keys
+		WHERE key = $1
+		  AND ($2::uuid IS NULL OR tenant_id = $2)
+		  AND subject = $3
+	`
+
+	item := &dto.IdempotencyRecord{}
+
+	err := c.db.QueryRow(ctx, query, key, tenantFilter(ctx), subjectFilter(ctx)).Scan(
+		&item.Key,
+		&item.RequestHash,
+		&item.StatusCode,
+		&item.Headers,
+		&item.ResponseBody,
+		&item.ExpiresAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	return item, true, nil
+}
+
+// SaveResponse сохраняет ответ и продлевает запись с аренды на ttl
+func (c *IdempotencyRepository) SaveResponse(ctx context.Context, key string, statusCode int, headers map[string]string, body []byte, ttl time.Duration) error {
+	ctx = withQuery(ctx, "IdempotencyRepository", "SaveResponse")
+	query := `
+		update idempotency_keys
+		set status_code = $2, headers = $3, response_body = $4, expires_at = NOW() + make_interval(secs => $5)
+		where key = $1 and ($6::uuid IS NULL OR tenant_id = $6) and subject = $7
+	`
+	_, err := c.db.Exec(ctx, query, key, statusCode, headers, body, ttl.Seconds(), tenantFilter(ctx), subjectFilter(ctx))
+	return err
+}
+
+func (c *IdempotencyRepository) Delete(ctx context.Context, key string) error {
+	ctx = withQuery(ctx, "IdempotencyRepository", "Delete")
+	query := "delete from idempotency_keys where key = $1 and ($2::uuid IS NULL OR tenant_id = $2) and subject = $3"
+	_, err := c.db.Exec(ctx, query, key, tenantFilter(ctx), subjectFilter(ctx))
+	return err
+}
+
+// DeleteExpired удаляет просроченные ключи, возвращает число удалённых
+func (c *IdempotencyRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	ctx = withQuery(ctx, "IdempotencyRepository", "DeleteExpired")
+	query := "delete from idempotency_keys where expires_at < NOW() and ($1::uuid IS NULL OR tenant_id = $1)"
+	tag, err := c.db.Exec(ctx, query, tenantFilter(ctx))
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
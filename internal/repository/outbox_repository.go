@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"awesomeProject1/internal/dto"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+type IOutboxRepository interface {
+	Add(ctx context.Context, event *dto.Event) error
+	FanOut(ctx context.Context, limit int) (int, error)
+	FindBySeq(ctx context.Context, seq int64) (*dto.Event, bool, error)
+	FindAfter(ctx context.Context, afterSeq int64, filter *dto.SubscriptionFilter, limit int) ([]*dto.Event, error)
+	FindLate(ctx context.Context, afterSeq, window int64, maxAge time.Duration, filter *dto.SubscriptionFilter) ([]*dto.Event, error)
+	LastSeq(ctx context.Context) (int64, error)
+	DeleteProcessed(ctx context.Context, before time.Time) (int64, error)
+}
+
+type OutboxRepository struct {
+	db DBTX
+}
+
+func NewOutboxRepository(db DBTX) *OutboxRepository {
+	return &OutboxRepository{
+		db: db,
+	}
+}
+
+// Add сохраняет событие. Вызывается в той же транзакции, что и изменение подписки
+func (c *OutboxRepository) Add(ctx context.Context, event *dto.Event) error {
+	ctx = withQuery(ctx, "OutboxRepository", "Add")
+	payload, err := event.Payload()
+	if err != nil {
+		return err
+	}
+
+	query := "INSERT INTO outbox_events (event_id, event_type, payload, created_at, tenant_id) VALUES ($1, $2, $3, $4, $5)"
+	_, err = c.db.Exec(ctx, query, event.ID, event.Type, payload, event.CreatedAt, tenantFilter(ctx))
+	return err
+}
+
+// FanOut создаёт доставки для необработанных событий на все активные вебхуки тенанта события, подписанные на их тип,
+// и отмечает события обработанными. Всё выполняется одним запросом, поэтому событие не теряется и не дублируется.
+// Возвращает число обработанных событий
+func (c *OutboxRepository) FanOut(ctx context.Context, limit int) (int, error) {
+	ctx = withQuery(ctx, "OutboxRepository", "FanOut")
+	query := `
+		WITH events AS (
+			SELECT id, event_id, event_type, payload, tenant_id
+			FROM outbox_events
+			WHERE processed_at IS NULL
+			  AND ($2::uuid IS NULL OR tenant_id = $2)
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		), deliveries AS (
+			INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload, tenant_id)
+			SELECT w.id, e.event_id, e.event_type, e.payload, e.tenant_id
+			FROM events e
+			JOIN webhooks w ON w.tenant_id = e.tenant_id AND w.active AND e.event_type = ANY(w.event_types)
+			ON CONFLICT (webhook_id, event_id) DO NOTHING
+		)
+		UPDATE outbox_events o
+		SET processed_at = NOW()
+		FROM events e
+		WHERE o.id = e.id
+	`
+
+	tag, err := c.db.Exec(ctx, query, limit, tenantFilter(ctx))
+	if err != nil {
+		return 0, err
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+func (c *OutboxRepository) FindBySeq(ctx context.Context, seq int64) (*dto.Event, bool, error) {
+	ctx = withQuery(ctx, "OutboxRepository", "FindBySeq")
+	var payload []byte
+	event := &dto.Event{Seq: seq}
+	query := "SELECT payload, tenant_id FROM outbox_events WHERE id = $1 AND ($2::uuid IS NULL OR tenant_id = $2)"
+	err := c.db.QueryRow(ctx, query, seq, tenantFilter(ctx)).Scan(&payload, &event.TenantID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	if err := json.Unmarshal(payload, event); err != nil {
+		return nil, false, err
+	}
+
+	return event, true, nil
+}
+
+// FindAfter возвращает события журнала с номером больше afterSeq, подходящие под фильтр, в порядке номеров
+func (c *OutboxRepository) FindAfter(ctx context.Context, afterSeq int64, filter *dto.SubscriptionFilter, limit int) ([]*dto.Event, error) {
+	ctx = withQuery(ctx, "OutboxRepository", "FindAfter")
+	query := `
+		SELECT id, payload, tenant_id
+		FROM outbox_events
+		WHERE id > $1
+		  AND ($2::text IS NULL OR payload->'data'->>'user_id' = lower($2))
+		  AND ($3::text IS NULL OR payload->'data'->>'service_name' = $3)
+		  AND ($5::uuid IS NULL OR tenant_id = $5)
+		ORDER BY id
+		LIMIT $4
+	`
+
+	rows, err := c.db.Query(ctx, query, afterSeq, nullString(filter.UserId), nullString(filter.ServiceName), limit, tenantFilter(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	return scanEvents(rows)
+}
+
+// FindLate возвращает события с номерами из (afterSeq-window, afterSeq], созданные не раньше maxAge назад.
+// Номер выдаётся при вставке, а видно событие только после коммита, поэтому событие с меньшим номером
+// может появиться в журнале позже события afterSeq. Вызывающий сам отбрасывает уже отправленные
+func (c *OutboxRepository) FindLate(ctx context.Context, afterSeq, window int64, maxAge time.Duration, filter *dto.SubscriptionFilter) ([]*dto.Event, error) {
+	ctx = withQuery(ctx, "OutboxRepository", "FindLate")
+	query := `
+		SELECT id, payload, tenant_id
+		FROM outbox_events
+		WHERE id > $1 - $2 AND id <= $1
+		  AND created_at >= NOW() - make_interval(secs => $3)
+		  AND ($4::text IS NULL OR payload->'data'->>'user_id' = lower($4))
+		  AND ($5::text IS NULL OR payload->'data'->>'service_name' = $5)
+		  AND ($6::uuid IS NULL OR tenant_id = $6)
+		ORDER BY id
+	`
+
+	rows, err := c.db.Query(ctx, query, afterSeq, window, maxAge.Seconds(),
+		nullString(filter.UserId), nullString(filter.ServiceName), tenantFilter(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	return scanEvents(rows)
+}
+
+func scanEvents(rows pgx.Rows) ([]*dto.Event, error) {
+	defer rows.Close()
+
+	var events []*dto.Event
+	for rows.Next() {
+		var payload []byte
+		event := &dto.Event{}
+		if err := rows.Scan(&event.Seq, &payload, &event.TenantID); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(payload, event); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// LastSeq возвращает номер последнего события журнала, 0 если журнал пуст
+func (c *OutboxRepository) LastSeq(ctx context.Context) (int64, error) {
+	ctx = withQuery(ctx, "OutboxRepository", "LastSeq")
+	var seq int64
+	err := c.db.QueryRow(ctx, "SELECT COALESCE(MAX(id), 0) FROM outbox_events WHERE ($1::uuid IS NULL OR tenant_id = $1)", tenantFilter(ctx)).Scan(&seq)
+	return seq, err
+}
+
+// DeleteProcessed удаляет разосланные события старше before. Вместе с ними из истории SSE
+// пропадают и старые события, поэтому переподключение с таким Last-Event-ID их уже не получит
+func (c *OutboxRepository) DeleteProcessed(ctx context.Context, before time.Time) (int64, error) {
+	ctx = withQuery(ctx, "OutboxRepository", "DeleteProcessed")
+	query := "DELETE FROM outbox_events WHERE processed_at IS NOT NULL AND created_at < $1 AND ($2::uuid IS NULL OR tenant_id = $2)"
+	tag, err := c.db.Exec(ctx, query, before, tenantFilter(ctx))
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
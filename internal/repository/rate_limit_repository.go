@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"time"
+)
+
+type IRateLimitRepository interface {
+	Take(ctx context.Context, key string, capacity int, rate float64) (bool, float64, error)
+	DeleteIdle(ctx context.Context, before time.Time) (int64, error)
+}
+
+type RateLimitRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRateLimitRepository(db *pgxpool.Pool) *RateLimitRepository {
+	return &RateLimitRepository{
+		db: db,
+	}
+}
+
+// Take забирает токен из ведра key, возвращает, хватило ли токена, и остаток
+func (c *RateLimitRepository) Take(ctx context.Context, key string, capacity int, rate float64) (bool, float64, error) {
+	ctx = withQuery(ctx, "RateLimitRepository", "Take")
+	query := "select allowed, tokens from rate_limit_take($1, $2, $3)"
+
+	var allowed bool
+	var tokens float64
+	if err := c.db.QueryRow(ctx, query, key, capacity, rate).Scan(&allowed, &tokens); err != nil {
+		return false, 0, err
+	}
+	return allowed, tokens, nil
+}
+
+// DeleteIdle удаляет вёдра, к которым не обращались с before, возвращает число удалённых.
+// За это время ведро успевает наполниться, поэтому лимиты клиентов не сбрасываются
+func (c *RateLimitRepository) DeleteIdle(ctx context.Context, before time.Time) (int64, error) {
+	ctx = withQuery(ctx, "RateLimitRepository", "DeleteIdle")
+	query := "delete from rate_limit_buckets where updated_at < $1"
+	tag, err := c.db.Exec(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
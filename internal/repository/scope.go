@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"awesomeProject1/internal/auth"
+	"awesomeProject1/internal/tenant"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// ownerFilter возвращает пользователя, которым ограничиваются запросы к подпискам, nil — без ограничения.
+// Внутренние вызовы без субъекта снимают ограничение явно через auth.WithAllOwners, иначе это uuid.Nil:
+// под него не подходит ни одна запись, и забытый субъект не открывает чужие подписки
+func ownerFilter(ctx context.Context) *uuid.UUID {
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		return noPrincipalFilter(ctx)
+	}
+	return principal.Owner()
+}
+
+// reportFilter — ownerFilter для сумм: субъект с reports:read считает суммы по всем пользователям
+func reportFilter(ctx context.Context) *uuid.UUID {
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		return noPrincipalFilter(ctx)
+	}
+	if principal.Can(auth.PermReportsRead) {
+		return nil
+	}
+	return principal.Owner()
+}
+
+func noPrincipalFilter(ctx context.Context) *uuid.UUID {
+	if auth.AllOwners(ctx) {
+		return nil
+	}
+	nobody := uuid.Nil
+	return &nobody
+}
+
+// subjectFilter — субъект запроса, которым ограничены ключи идемпотентности, пустая строка без субъекта
+func subjectFilter(ctx context.Context) string {
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	return principal.Subject
+}
+
+// tenantFilter возвращает тенанта, которым ограничивается каждый запрос, nil — все тенанты.
+// Условие дублирует политики RLS: с ним запросы используют индексы по tenant_id и не зависят от роли соединения.
+// При вставке это значение tenant_id новой записи
+func tenantFilter(ctx context.Context) *uuid.UUID {
+	return tenant.Filter(ctx)
+}
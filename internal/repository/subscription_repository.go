@@ -6,43 +6,158 @@ import (
 	"errors"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"time"
 )
 
+// SubscriptionRepository — запросы к подпискам. Каждый запрос ограничен тенантом из ctx. Если в ctx есть субъект
+// без доступа ко всем подпискам, запрос видит и меняет только подписки его пользователя, а суммы без reports:read
+// считаются по ним же
 type SubscriptionRepository struct {
-	db *pgxpool.Pool
+	db DBTX
 }
 
 type ISubscriptionRepository interface {
-	FindAll(ctx context.Context, offset, limit int) ([]*dto.Subscription, int, error)
-	Delete(ctx context.Context, id uuid.UUID) (bool, error)
+	FindAll(ctx context.Context, filter *dto.SubscriptionFilter, offset, limit int) ([]*dto.Subscription, int, error)
+	FindAfter(ctx context.Context, filter *dto.SubscriptionFilter, after *dto.SubscriptionCursor, limit int) ([]*dto.Subscription, error)
+	FindByIds(ctx context.Context, ids []uuid.UUID) ([]*dto.Subscription, error)
+	FindByUserIds(ctx context.Context, userIds []uuid.UUID) ([]*dto.Subscription, error)
+	Delete(ctx context.Context, id uuid.UUID, version *int) (bool, error)
 	Create(ctx context.Context, category *dto.Subscription) (*dto.Subscription, error)
 	Update(ctx context.Context, queryParts string, values []any) (bool, error)
 	FindById(ctx context.Context, id uuid.UUID) (*dto.Subscription, bool, error)
 	GetTotal(ctx context.Context, start, end time.Time, serviceName, userId string) (int, error)
+	GetMonthlyTotals(ctx context.Context, start, end time.Time, serviceName, userId string) ([]*dto.MonthlyTotal, error)
+	GetTotalsByUserIds(ctx context.Context, start, end time.Time, serviceName string, userIds []uuid.UUID) (map[uuid.UUID]int, error)
+	Purge(ctx context.Context, filter *dto.SubscriptionFilter, endedBefore *time.Time) ([]*dto.Subscription, error)
+	GetActiveStats(ctx context.Context, at time.Time) ([]*dto.ServiceStats, error)
 }
 
-func NewSubscriptionRepository(db *pgxpool.Pool) *SubscriptionRepository {
+func NewSubscriptionRepository(db DBTX) *SubscriptionRepository {
 	return &SubscriptionRepository{
 		db: db,
 	}
 }
 func (c *SubscriptionRepository) GetTotal(ctx context.Context, start, end time.Time, serviceName, userId string) (int, error) {
+	ctx = withQuery(ctx, "SubscriptionRepository", "GetTotal")
 	query := `
         SELECT COALESCE(SUM(price), 0)
         FROM subscriptions
         WHERE start_date >= $1
           AND (start_date <= $2)
           AND ($3::uuid IS NULL OR user_id = $3)
-          AND ($4::text IS NULL OR service_name = $4);
+          AND ($4::text IS NULL OR service_name = $4)
+          AND ($5::uuid IS NULL OR user_id = $5)
+          AND ($6::uuid IS NULL OR tenant_id = $6);
     `
 
 	var total int
-	err := c.db.QueryRow(ctx, query, start, end, nullString(userId), nullString(serviceName)).Scan(&total)
+	err := c.db.QueryRow(ctx, query, start, end, nullString(userId), nullString(serviceName), reportFilter(ctx), tenantFilter(ctx)).Scan(&total)
 	return total, err
 }
 
+// GetMonthlyTotals разбивает сумму GetTotal по месяцам начала подписки.
+// Месяцы периода без подписок возвращаются с нулевой суммой
+func (c *SubscriptionRepository) GetMonthlyTotals(ctx context.Context, start, end time.Time, serviceName, userId string) ([]*dto.MonthlyTotal, error) {
+	ctx = withQuery(ctx, "SubscriptionRepository", "GetMonthlyTotals")
+	query := `
+        SELECT to_char(months.month, 'MM-YYYY'), COALESCE(SUM(s.price), 0)
+        FROM generate_series(date_trunc('month', $1::date), date_trunc('month', $2::date), interval '1 month') AS months(month)
+        LEFT JOIN subscriptions s
+          ON date_trunc('month', s.start_date) = months.month
+         AND s.start_date >= $1
+         AND s.start_date <= $2
+         AND ($3::uuid IS NULL OR s.user_id = $3)
+         AND ($4::text IS NULL OR s.service_name = $4)
+         AND ($5::uuid IS NULL OR s.user_id = $5)
+         AND ($6::uuid IS NULL OR s.tenant_id = $6)
+        GROUP BY months.month
+        ORDER BY months.month;
+    `
+
+	rows, err := c.db.Query(ctx, query, start, end, nullString(userId), nullString(serviceName), reportFilter(ctx), tenantFilter(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var totals []*dto.MonthlyTotal
+	for rows.Next() {
+		item := &dto.MonthlyTotal{}
+		if err := rows.Scan(&item.Month, &item.Total); err != nil {
+			return nil, err
+		}
+		totals = append(totals, item)
+	}
+
+	return totals, rows.Err()
+}
+
+// GetTotalsByUserIds считает GetTotal сразу для нескольких пользователей одним запросом.
+// Пользователи без подписок в результат не попадают
+func (c *SubscriptionRepository) GetTotalsByUserIds(ctx context.Context, start, end time.Time, serviceName string, userIds []uuid.UUID) (map[uuid.UUID]int, error) {
+	ctx = withQuery(ctx, "SubscriptionRepository", "GetTotalsByUserIds")
+	query := `
+        SELECT user_id, COALESCE(SUM(price), 0)
+        FROM subscriptions
+        WHERE start_date >= $1
+          AND (start_date <= $2)
+          AND user_id = ANY($3)
+          AND ($4::text IS NULL OR service_name = $4)
+          AND ($5::uuid IS NULL OR user_id = $5)
+          AND ($6::uuid IS NULL OR tenant_id = $6)
+        GROUP BY user_id;
+    `
+
+	rows, err := c.db.Query(ctx, query, start, end, userIds, nullString(serviceName), reportFilter(ctx), tenantFilter(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := make(map[uuid.UUID]int, len(userIds))
+	for rows.Next() {
+		var userId uuid.UUID
+		var total int
+		if err := rows.Scan(&userId, &total); err != nil {
+			return nil, err
+		}
+		totals[userId] = total
+	}
+
+	return totals, rows.Err()
+}
+
+// GetActiveStats считает по сервисам подписки, действующие в месяце at, и сумму их цен.
+// Подписка с end_date действует до конца этого месяца включительно
+func (c *SubscriptionRepository) GetActiveStats(ctx context.Context, at time.Time) ([]*dto.ServiceStats, error) {
+	ctx = withQuery(ctx, "SubscriptionRepository", "GetActiveStats")
+	query := `
+        SELECT service_name, COUNT(*), COALESCE(SUM(price), 0)
+        FROM subscriptions
+        WHERE start_date <= $1
+          AND (end_date IS NULL OR end_date >= date_trunc('month', $1::date))
+          AND ($2::uuid IS NULL OR tenant_id = $2)
+        GROUP BY service_name;
+    `
+
+	rows, err := c.db.Query(ctx, query, at, tenantFilter(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []*dto.ServiceStats
+	for rows.Next() {
+		item := &dto.ServiceStats{}
+		if err := rows.Scan(&item.ServiceName, &item.Active, &item.MonthlyRevenue); err != nil {
+			return nil, err
+		}
+		stats = append(stats, item)
+	}
+
+	return stats, rows.Err()
+}
+
 func nullString(s string) *string {
 	if s == "" {
 		return nil
@@ -51,6 +166,7 @@ func nullString(s string) *string {
 }
 
 func (c *SubscriptionRepository) Update(ctx context.Context, query string, values []any) (bool, error) {
+	ctx = withQuery(ctx, "SubscriptionRepository", "Update")
 	tag, err := c.db.Exec(ctx, query, values...)
 
 	if err != nil {
@@ -60,15 +176,18 @@ func (c *SubscriptionRepository) Update(ctx context.Context, query string, value
 	return tag.RowsAffected() != 0, nil
 }
 func (c *SubscriptionRepository) FindById(ctx context.Context, id uuid.UUID) (*dto.Subscription, bool, error) {
+	ctx = withQuery(ctx, "SubscriptionRepository", "FindById")
 	query := `
-		SELECT id, service_name, price, user_id, start_date, end_date, created_at, updated_at
+		SELECT id, service_name, price, user_id, start_date, end_date, created_at, updated_at, version
 		FROM public.subscriptions
 		WHERE id = $1
+		  AND ($2::uuid IS NULL OR user_id = $2)
+		  AND ($3::uuid IS NULL OR tenant_id = $3)
 	`
 
 	item := &dto.Subscription{}
 
-	err := c.db.QueryRow(ctx, query, id).Scan(
+	err := c.db.QueryRow(ctx, query, id, ownerFilter(ctx), tenantFilter(ctx)).Scan(
 		&item.ID,
 		&item.ServiceName,
 		&item.Price,
@@ -77,6 +196,7 @@ func (c *SubscriptionRepository) FindById(ctx context.Context, id uuid.UUID) (*d
 		&item.EndDate,
 		&item.CreatedAt,
 		&item.UpdatedAt,
+		&item.Version,
 	)
 
 	if err != nil {
@@ -89,9 +209,11 @@ func (c *SubscriptionRepository) FindById(ctx context.Context, id uuid.UUID) (*d
 	return item, true, nil
 }
 
-func (c *SubscriptionRepository) Delete(ctx context.Context, id uuid.UUID) (bool, error) {
-	query := "delete from public.Subscriptions where id = $1"
-	tag, err := c.db.Exec(ctx, query, id)
+// Delete удаляет подписку. Если version задана, удаление выполняется только для этой версии записи
+func (c *SubscriptionRepository) Delete(ctx context.Context, id uuid.UUID, version *int) (bool, error) {
+	ctx = withQuery(ctx, "SubscriptionRepository", "Delete")
+	query := "delete from public.Subscriptions where id = $1 and ($2::int IS NULL OR version = $2) and ($3::uuid IS NULL OR user_id = $3) and ($4::uuid IS NULL OR tenant_id = $4)"
+	tag, err := c.db.Exec(ctx, query, id, version, ownerFilter(ctx), tenantFilter(ctx))
 
 	if err != nil {
 		return false, err
@@ -99,18 +221,120 @@ func (c *SubscriptionRepository) Delete(ctx context.Context, id uuid.UUID) (bool
 
 	return tag.RowsAffected() != 0, nil
 }
-func (c *SubscriptionRepository) FindAll(ctx context.Context, offset, limit int) ([]*dto.Subscription, int, error) {
+func (c *SubscriptionRepository) FindAll(ctx context.Context, filter *dto.SubscriptionFilter, offset, limit int) ([]*dto.Subscription, int, error) {
+	ctx = withQuery(ctx, "SubscriptionRepository", "FindAll")
 	query := `
-		SELECT id, service_name, price, user_id, start_date, end_date, created_at, updated_at
+		SELECT id, service_name, price, user_id, start_date, end_date, created_at, updated_at, version
 		FROM public.subscriptions
+		WHERE ($3::uuid IS NULL OR user_id = $3)
+		  AND ($4::text IS NULL OR service_name = $4)
+		  AND ($5::uuid IS NULL OR user_id = $5)
+		  AND ($6::uuid IS NULL OR tenant_id = $6)
 		ORDER BY created_at DESC
 		OFFSET $1 LIMIT $2;
 	`
 
-	rows, err := c.db.Query(ctx, query, offset, limit)
+	userId, serviceName, owner, tenantId := nullString(filter.UserId), nullString(filter.ServiceName), ownerFilter(ctx), tenantFilter(ctx)
+
+	rows, err := c.db.Query(ctx, query, offset, limit, userId, serviceName, owner, tenantId)
 	if err != nil {
 		return nil, 0, err
 	}
+
+	subscriptions, err := scanSubscriptions(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Получаем общее количество подписок с учётом фильтров
+	var total int
+	countQuery := `
+		SELECT COUNT(*)
+		FROM public.subscriptions
+		WHERE ($1::uuid IS NULL OR user_id = $1)
+		  AND ($2::text IS NULL OR service_name = $2)
+		  AND ($3::uuid IS NULL OR user_id = $3)
+		  AND ($4::uuid IS NULL OR tenant_id = $4);
+	`
+	err = c.db.QueryRow(ctx, countQuery, userId, serviceName, owner, tenantId).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return subscriptions, total, nil
+}
+
+// FindAfter возвращает до limit подписок, следующих за after в порядке created_at, id по убыванию.
+// Без after — с начала списка
+func (c *SubscriptionRepository) FindAfter(ctx context.Context, filter *dto.SubscriptionFilter, after *dto.SubscriptionCursor, limit int) ([]*dto.Subscription, error) {
+	ctx = withQuery(ctx, "SubscriptionRepository", "FindAfter")
+	query := `
+		SELECT id, service_name, price, user_id, start_date, end_date, created_at, updated_at, version
+		FROM public.subscriptions
+		WHERE ($2::timestamptz IS NULL OR (created_at, id) < ($2, $3::uuid))
+		  AND ($4::uuid IS NULL OR user_id = $4)
+		  AND ($5::text IS NULL OR service_name = $5)
+		  AND ($6::uuid IS NULL OR user_id = $6)
+		  AND ($7::uuid IS NULL OR tenant_id = $7)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $1
+	`
+
+	var afterCreatedAt *time.Time
+	var afterId *uuid.UUID
+	if after != nil {
+		afterCreatedAt, afterId = &after.CreatedAt, &after.ID
+	}
+
+	rows, err := c.db.Query(ctx, query, limit, afterCreatedAt, afterId,
+		nullString(filter.UserId), nullString(filter.ServiceName), ownerFilter(ctx), tenantFilter(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	return scanSubscriptions(rows)
+}
+
+// FindByIds возвращает подписки с указанными id одним запросом, отсутствующие id пропускаются
+func (c *SubscriptionRepository) FindByIds(ctx context.Context, ids []uuid.UUID) ([]*dto.Subscription, error) {
+	ctx = withQuery(ctx, "SubscriptionRepository", "FindByIds")
+	query := `
+		SELECT id, service_name, price, user_id, start_date, end_date, created_at, updated_at, version
+		FROM public.subscriptions
+		WHERE id = ANY($1)
+		  AND ($2::uuid IS NULL OR user_id = $2)
+		  AND ($3::uuid IS NULL OR tenant_id = $3)
+	`
+
+	rows, err := c.db.Query(ctx, query, ids, ownerFilter(ctx), tenantFilter(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	return scanSubscriptions(rows)
+}
+
+// FindByUserIds возвращает все подписки указанных пользователей одним запросом
+func (c *SubscriptionRepository) FindByUserIds(ctx context.Context, userIds []uuid.UUID) ([]*dto.Subscription, error) {
+	ctx = withQuery(ctx, "SubscriptionRepository", "FindByUserIds")
+	query := `
+		SELECT id, service_name, price, user_id, start_date, end_date, created_at, updated_at, version
+		FROM public.subscriptions
+		WHERE user_id = ANY($1)
+		  AND ($2::uuid IS NULL OR user_id = $2)
+		  AND ($3::uuid IS NULL OR tenant_id = $3)
+		ORDER BY created_at DESC
+	`
+
+	rows, err := c.db.Query(ctx, query, userIds, ownerFilter(ctx), tenantFilter(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	return scanSubscriptions(rows)
+}
+
+func scanSubscriptions(rows pgx.Rows) ([]*dto.Subscription, error) {
 	defer rows.Close()
 
 	var subscriptions []*dto.Subscription
@@ -125,32 +349,27 @@ func (c *SubscriptionRepository) FindAll(ctx context.Context, offset, limit int)
 			&item.EndDate,
 			&item.CreatedAt,
 			&item.UpdatedAt,
+			&item.Version,
 		)
 		if err != nil {
-			return nil, 0, err
+			return nil, err
 		}
 		subscriptions = append(subscriptions, item)
 	}
 
-	// Получаем общее количество подписок
-	var total int
-	countQuery := `SELECT COUNT(*) FROM public.subscriptions;`
-	err = c.db.QueryRow(ctx, countQuery).Scan(&total)
-	if err != nil {
-		return nil, 0, err
-	}
-
-	return subscriptions, total, nil
+	return subscriptions, rows.Err()
 }
 
 func (c *SubscriptionRepository) Create(ctx context.Context, ci *dto.Subscription) (*dto.Subscription, error) {
-	query := "insert into public.Subscriptions (service_name, start_date, price, end_date, user_id) values ($1, $2, $3, $4, $5) returning id"
+	ctx = withQuery(ctx, "SubscriptionRepository", "Create")
+	query := "insert into public.Subscriptions (service_name, start_date, price, end_date, user_id, tenant_id) values ($1, $2, $3, $4, $5, $6) returning id, created_at, updated_at, version"
 	err := c.db.QueryRow(ctx, query,
 		ci.ServiceName,
 		ci.StartDate,
 		ci.Price,
 		ci.EndDate,
-		ci.UserID).Scan(&ci.ID)
+		ci.UserID,
+		tenantFilter(ctx)).Scan(&ci.ID, &ci.CreatedAt, &ci.UpdatedAt, &ci.Version)
 
 	if err != nil {
 		return ci, err
@@ -158,3 +377,25 @@ func (c *SubscriptionRepository) Create(ctx context.Context, ci *dto.Subscriptio
 
 	return ci, nil
 }
+
+// Purge удаляет подписки по фильтру и возвращает удалённые записи.
+// endedBefore оставляет только подписки, закончившиеся раньше указанной даты
+func (c *SubscriptionRepository) Purge(ctx context.Context, filter *dto.SubscriptionFilter, endedBefore *time.Time) ([]*dto.Subscription, error) {
+	ctx = withQuery(ctx, "SubscriptionRepository", "Purge")
+	query := `
+		DELETE FROM public.subscriptions
+		WHERE ($1::uuid IS NULL OR user_id = $1)
+		  AND ($2::text IS NULL OR service_name = $2)
+		  AND ($3::date IS NULL OR end_date < $3)
+		  AND ($4::uuid IS NULL OR user_id = $4)
+		  AND ($5::uuid IS NULL OR tenant_id = $5)
+		RETURNING id, service_name, price, user_id, start_date, end_date, created_at, updated_at, version
+	`
+
+	rows, err := c.db.Query(ctx, query, nullString(filter.UserId), nullString(filter.ServiceName), endedBefore, ownerFilter(ctx), tenantFilter(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	return scanSubscriptions(rows)
+}
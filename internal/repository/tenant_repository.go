@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"awesomeProject1/internal/dto"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type ITenantRepository interface {
+	Create(ctx context.Context, tenant *dto.Tenant) (*dto.Tenant, error)
+	FindById(ctx context.Context, id uuid.UUID) (*dto.Tenant, bool, error)
+	FindAll(ctx context.Context, offset, limit int) ([]*dto.Tenant, int, error)
+}
+
+// TenantRepository — запросы к справочнику тенантов. Справочник общий и тенантом из ctx не ограничивается
+type TenantRepository struct {
+	db DBTX
+}
+
+func NewTenantRepository(db DBTX) *TenantRepository {
+	return &TenantRepository{
+		db: db,
+	}
+}
+
+func (c *TenantRepository) Create(ctx context.Context, t *dto.Tenant) (*dto.Tenant, error) {
+	ctx = withQuery(ctx, "TenantRepository", "Create")
+	query := "insert into tenants (name) values ($1) returning id, created_at"
+	err := c.db.QueryRow(ctx, query, t.Name).Scan(&t.ID, &t.CreatedAt)
+	return t, err
+}
+
+func (c *TenantRepository) FindById(ctx context.Context, id uuid.UUID) (*dto.Tenant, bool, error) {
+	ctx = withQuery(ctx, "TenantRepository", "FindById")
+	item := &dto.Tenant{}
+	err := c.db.QueryRow(ctx, "SELECT id, name, created_at FROM tenants WHERE id = $1", id).Scan(&item.ID, &item.Name, &item.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	return item, true, nil
+}
+
+func (c *TenantRepository) FindAll(ctx context.Context, offset, limit int) ([]*dto.Tenant, int, error) {
+	ctx = withQuery(ctx, "TenantRepository", "FindAll")
+	rows, err := c.db.Query(ctx, "SELECT id, name, created_at FROM tenants ORDER BY created_at DESC OFFSET $1 LIMIT $2", offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var tenants []*dto.Tenant
+	for rows.Next() {
+		item := &dto.Tenant{}
+		if err := rows.Scan(&item.ID, &item.Name, &item.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		tenants = append(tenants, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	if err := c.db.QueryRow(ctx, "SELECT COUNT(*) FROM tenants").Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	return tenants, total, nil
+}
@@ -0,0 +1,301 @@
+package repository
+
+import (
+	"awesomeProject1/internal/dto"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+type IWebhookRepository interface {
+	Create(ctx context.Context, webhook *dto.Webhook) (*dto.Webhook, error)
+	FindById(ctx context.Context, id uuid.UUID) (*dto.Webhook, bool, error)
+	FindAll(ctx context.Context, offset, limit int) ([]*dto.Webhook, int, error)
+	Update(ctx context.Context, query string, values []any) (bool, error)
+	Delete(ctx context.Context, id uuid.UUID) (bool, error)
+
+	FindDeliveries(ctx context.Context, webhookId uuid.UUID, status dto.DeliveryStatus, offset, limit int) ([]*dto.WebhookDelivery, int, error)
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*dto.WebhookDelivery, error)
+	ReleaseDeliveries(ctx context.Context, ids []uuid.UUID) error
+	MarkDelivered(ctx context.Context, id uuid.UUID, statusCode int) error
+	MarkFailed(ctx context.Context, id uuid.UUID, statusCode *int, lastError string, nextAttemptAt time.Time, dead bool) error
+	RetryDelivery(ctx context.Context, webhookId, id uuid.UUID) (bool, error)
+}
+
+type WebhookRepository struct {
+	db DBTX
+}
+
+func NewWebhookRepository(db DBTX) *WebhookRepository {
+	return &WebhookRepository{
+		db: db,
+	}
+}
+
+func (c *WebhookRepository) Create(ctx context.Context, w *dto.Webhook) (*dto.Webhook, error) {
+	ctx = withQuery(ctx, "WebhookRepository", "Create")
+	query := "insert into webhooks (url, secret, event_types, active, tenant_id) values ($1, $2, $3, $4, $5) returning id, created_at, updated_at"
+	err := c.db.QueryRow(ctx, query, w.URL, w.Secret, w.EventTypes, w.Active, tenantFilter(ctx)).Scan(&w.ID, &w.CreatedAt, &w.UpdatedAt)
+	return w, err
+}
+
+func (c *WebhookRepository) FindById(ctx context.Context, id uuid.UUID) (*dto.Webhook, bool, error) {
+	ctx = withQuery(ctx, "WebhookRepository", "FindById")
+	query := `
+		SELECT id, url, secret, event_types, active, created_at, updated_at
+		FROM webhooks
+		WHERE id = $1
+		  AND ($2::uuid IS NULL OR tenant_id = $2)
+	`
+
+	item := &dto.Webhook{}
+	err := c.db.QueryRow(ctx, query, id, tenantFilter(ctx)).Scan(
+		&item.ID,
+		&item.URL,
+		&item.Secret,
+		&item.EventTypes,
+		&item.Active,
+		&item.CreatedAt,
+		&item.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	return item, true, nil
+}
+
+func (c *WebhookRepository) FindAll(ctx context.Context, offset, limit int) ([]*dto.Webhook, int, error) {
+	ctx = withQuery(ctx, "WebhookRepository", "FindAll")
+	query := `
+		SELECT id, url, secret, event_types, active, created_at, updated_at
+		FROM webhooks
+		WHERE ($3::uuid IS NULL OR tenant_id = $3)
+		ORDER BY created_at DESC
+		OFFSET $1 LIMIT $2
+	`
+
+	tenantId := tenantFilter(ctx)
+
+	rows, err := c.db.Query(ctx, query, offset, limit, tenantId)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var webhooks []*dto.Webhook
+	for rows.Next() {
+		item := &dto.Webhook{}
+		err := rows.Scan(
+			&item.ID,
+			&item.URL,
+			&item.Secret,
+			&item.EventTypes,
+			&item.Active,
+			&item.CreatedAt,
+			&item.UpdatedAt,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		webhooks = append(webhooks, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	if err := c.db.QueryRow(ctx, "SELECT COUNT(*) FROM webhooks WHERE ($1::uuid IS NULL OR tenant_id = $1)", tenantId).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	return webhooks, total, nil
+}
+
+func (c *WebhookRepository) Update(ctx context.Context, query string, values []any) (bool, error) {
+	ctx = withQuery(ctx, "WebhookRepository", "Update")
+	tag, err := c.db.Exec(ctx, query, values...)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() != 0, nil
+}
+
+// Delete удаляет вебхук вместе с журналом его доставок
+func (c *WebhookRepository) Delete(ctx context.Context, id uuid.UUID) (bool, error) {
+	ctx = withQuery(ctx, "WebhookRepository", "Delete")
+	tag, err := c.db.Exec(ctx, "delete from webhooks where id = $1 and ($2::uuid IS NULL OR tenant_id = $2)", id, tenantFilter(ctx))
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() != 0, nil
+}
+
+const deliveryColumns = `d.id, d.webhook_id, d.event_id, d.event_type, d.payload, d.status, d.attempts, d.next_attempt_at,
+	d.last_status_code, d.last_error, d.delivered_at, d.created_at, d.updated_at`
+
+// FindDeliveries возвращает журнал доставок вебхука, пустой status означает все доставки
+func (c *WebhookRepository) FindDeliveries(ctx context.Context, webhookId uuid.UUID, status dto.DeliveryStatus, offset, limit int) ([]*dto.WebhookDelivery, int, error) {
+	ctx = withQuery(ctx, "WebhookRepository", "FindDeliveries")
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries d
+		WHERE d.webhook_id = $1
+		  AND ($2::text IS NULL OR d.status = $2)
+		  AND ($5::uuid IS NULL OR d.tenant_id = $5)
+		ORDER BY d.created_at DESC
+		OFFSET $3 LIMIT $4
+	`
+
+	statusFilter, tenantId := nullString(string(status)), tenantFilter(ctx)
+
+	rows, err := c.db.Query(ctx, query, webhookId, statusFilter, offset, limit, tenantId)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	deliveries, err := scanDeliveries(rows, false)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	countQuery := "SELECT COUNT(*) FROM webhook_deliveries WHERE webhook_id = $1 AND ($2::text IS NULL OR status = $2) AND ($3::uuid IS NULL OR tenant_id = $3)"
+	if err := c.db.QueryRow(ctx, countQuery, webhookId, statusFilter, tenantId).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	return deliveries, total, nil
+}
+
+// ClaimDueDeliveries забирает доставки, время которых пришло, и откладывает их на lease,
+// чтобы другие экземпляры сервиса их не взяли. Попытка засчитывается сразу:
+// если процесс упадёт во время отправки, доставка повторится после lease
+func (c *WebhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*dto.WebhookDelivery, error) {
+	ctx = withQuery(ctx, "WebhookRepository", "ClaimDueDeliveries")
+	query := `
+		UPDATE webhook_deliveries d
+		SET attempts = d.attempts + 1,
+		    next_attempt_at = NOW() + make_interval(secs => $2)
+		FROM webhooks w
+		WHERE w.id = d.webhook_id
+		  AND d.id IN (
+			SELECT id
+			FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+			  AND ($3::uuid IS NULL OR tenant_id = $3)
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		  )
+		RETURNING ` + deliveryColumns + `, w.url, w.secret
+	`
+
+	rows, err := c.db.Query(ctx, query, limit, lease.Seconds(), tenantFilter(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	return scanDeliveries(rows, true)
+}
+
+// ReleaseDeliveries возвращает в очередь забранные, но не начатые доставки: снимает lease
+// и не засчитывает попытку, которую добавил ClaimDueDeliveries
+func (c *WebhookRepository) ReleaseDeliveries(ctx context.Context, ids []uuid.UUID) error {
+	ctx = withQuery(ctx, "WebhookRepository", "ReleaseDeliveries")
+	query := `
+		UPDATE webhook_deliveries
+		SET attempts = attempts - 1, next_attempt_at = NOW()
+		WHERE id = ANY($1) AND status = 'pending'
+		  AND ($2::uuid IS NULL OR tenant_id = $2)
+	`
+	_, err := c.db.Exec(ctx, query, ids, tenantFilter(ctx))
+	return err
+}
+
+func (c *WebhookRepository) MarkDelivered(ctx context.Context, id uuid.UUID, statusCode int) error {
+	ctx = withQuery(ctx, "WebhookRepository", "MarkDelivered")
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'delivered', last_status_code = $2, last_error = NULL, delivered_at = NOW()
+		WHERE id = $1
+		  AND ($3::uuid IS NULL OR tenant_id = $3)
+	`
+	_, err := c.db.Exec(ctx, query, id, statusCode, tenantFilter(ctx))
+	return err
+}
+
+// MarkFailed сохраняет результат неудачной попытки и назначает следующую, dead переводит доставку в dead-letter
+func (c *WebhookRepository) MarkFailed(ctx context.Context, id uuid.UUID, statusCode *int, lastError string, nextAttemptAt time.Time, dead bool) error {
+	ctx = withQuery(ctx, "WebhookRepository", "MarkFailed")
+	query := `
+		UPDATE webhook_deliveries
+		SET status = CASE WHEN $5 THEN 'dead' ELSE 'pending' END,
+		    last_status_code = $2,
+		    last_error = $3,
+		    next_attempt_at = $4
+		WHERE id = $1
+		  AND ($6::uuid IS NULL OR tenant_id = $6)
+	`
+	_, err := c.db.Exec(ctx, query, id, statusCode, lastError, nextAttemptAt, dead, tenantFilter(ctx))
+	return err
+}
+
+// RetryDelivery возвращает доставку из dead-letter в очередь с обнулённым счётчиком попыток
+func (c *WebhookRepository) RetryDelivery(ctx context.Context, webhookId, id uuid.UUID) (bool, error) {
+	ctx = withQuery(ctx, "WebhookRepository", "RetryDelivery")
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'pending', attempts = 0, next_attempt_at = NOW()
+		WHERE id = $1 AND webhook_id = $2 AND status = 'dead'
+		  AND ($3::uuid IS NULL OR tenant_id = $3)
+	`
+	tag, err := c.db.Exec(ctx, query, id, webhookId, tenantFilter(ctx))
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() != 0, nil
+}
+
+func scanDeliveries(rows pgx.Rows, withWebhook bool) ([]*dto.WebhookDelivery, error) {
+	defer rows.Close()
+
+	var deliveries []*dto.WebhookDelivery
+	for rows.Next() {
+		item := &dto.WebhookDelivery{}
+		dest := []any{
+			&item.ID,
+			&item.WebhookID,
+			&item.EventID,
+			&item.EventType,
+			&item.Payload,
+			&item.Status,
+			&item.Attempts,
+			&item.NextAttemptAt,
+			&item.LastStatusCode,
+			&item.LastError,
+			&item.DeliveredAt,
+			&item.CreatedAt,
+			&item.UpdatedAt,
+		}
+		if withWebhook {
+			dest = append(dest, &item.URL, &item.Secret)
+		}
+
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, item)
+	}
+
+	return deliveries, rows.Err()
+}
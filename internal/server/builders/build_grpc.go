@@ -0,0 +1,20 @@
+package builders
+
+import (
+	"awesomeProject1/internal/grpcHandlers"
+	"awesomeProject1/internal/pagination"
+	"awesomeProject1/internal/service"
+	"awesomeProject1/internal/store"
+	subscriptionv1 "awesomeProject1/pkg/proto/subscription/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+func BuildGrpcServices(server *grpc.Server, store *store.Store, pagination func() *pagination.Config) {
+	//Subscriptions
+	subscriptionService := service.NewSubscriptionService(store.SubscriptionRepository(), store.Transactor())
+	subscriptionv1.RegisterSubscriptionServiceServer(server, grpcHandlers.NewSubscriptionServer(subscriptionService, pagination))
+
+	// Reflection для grpcurl и подобных инструментов
+	reflection.Register(server)
+}
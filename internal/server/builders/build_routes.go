@@ -1,11 +1,21 @@
 package builders
 
 import (
+	"awesomeProject1/internal/auth"
+	"awesomeProject1/internal/events"
+	"awesomeProject1/internal/features"
+	"awesomeProject1/internal/graphqlHandlers"
 	"awesomeProject1/internal/handlers"
+	"awesomeProject1/internal/health"
+	"awesomeProject1/internal/middleware"
+	"awesomeProject1/internal/pagination"
+	"awesomeProject1/internal/ratelimit"
 	"awesomeProject1/internal/service"
 	"awesomeProject1/internal/store"
 	"github.com/gorilla/mux"
 	httpSwagger "github.com/swaggo/http-swagger"
+	"net/http"
+	"time"
 )
 
 var (
@@ -13,20 +23,91 @@ var (
 )
 
 type Builder struct {
-	Router *mux.Router
-	Store  *store.Store
+	Router         *mux.Router
+	Store          *store.Store
+	IdempotencyTTL time.Duration
+	Events         *events.Hub
+	Health         *health.Health
+	// Pagination и Features читают текущую конфигурацию, она может смениться без перезапуска
+	Pagination func() *pagination.Config
+	Features   func() features.Config
+	// Auth — способы аутентификации для /api/v1 и /admin
+	Auth    auth.Chain
+	APIKeys service.IAPIKeyService
+	// Tenants определяет тенанта запросов к /api/v1 и /admin
+	Tenants service.ITenantService
+	// RateLimit — лимиты запросов по клиентам и маршрутам
+	RateLimit *ratelimit.Limiter
 }
 
 func BuildRoutes(b *Builder) {
-	//Subscriptions
-	subscriptionService := service.NewSubscriptionService(b.Store.SubscriptionRepository())
-	subscriptionHandler := handlers.NewCatalogHandler(subscriptionService)
-	b.Router.HandleFunc(url+"/subscription", subscriptionHandler.Create).Methods("POST")
-	b.Router.HandleFunc(url+"/subscription", subscriptionHandler.Update).Methods("PATCH")
-	b.Router.HandleFunc(url+"/subscription/{id}", subscriptionHandler.Delete).Methods("DELETE")
-	b.Router.HandleFunc(url+"/subscription/{id}", subscriptionHandler.GetById).Methods("GET")
-	b.Router.HandleFunc(url+"/subscriptions/total", subscriptionHandler.GetTotal).Methods("GET")
-	b.Router.HandleFunc(url+"/subscriptions", subscriptionHandler.GetAll).Methods("GET")
+	feature := func(name string, handler http.HandlerFunc) http.Handler {
+		return features.Middleware(name, b.Features)(handler)
+	}
+	authenticate := middleware.Authenticate(b.Auth)
+	rateLimit := middleware.RateLimit(b.RateLimit)
+	rateLimitIP := middleware.RateLimitIP(b.RateLimit)
+	tenantHandler := handlers.NewTenantHandler(b.Tenants, b.Pagination)
+	//Permissions: маршрут отсекает субъектов без разрешения, чьи подписки доступны, решают сервисы
+	read := middleware.RequirePermission(auth.PermSubscriptionRead)
+	write := middleware.RequirePermission(auth.PermSubscriptionWrite)
+	reports := middleware.RequirePermission(auth.PermSubscriptionRead, auth.PermReportsRead)
+	manageWebhooks := middleware.RequirePermission(auth.PermAdminWebhooks)
+
+	//Public: проверки и документация нужны без ключа, календарь защищён своим токеном
+	calendarHandler := handlers.NewCalendarHandler(service.NewCalendarService(b.Store.SubscriptionRepository(), b.Store.CalendarTokenRepository()))
+	b.Router.Handle(url+"/users/{id}/calendar.ics", rateLimit(feature(features.Calendar, calendarHandler.Feed))).Methods("GET")
+	//Health
+	healthHandler := handlers.NewHealthHandler(b.Health)
+	b.Router.HandleFunc("/healthz", healthHandler.Live).Methods("GET")
+	b.Router.HandleFunc("/readyz", healthHandler.Ready).Methods("GET")
 	// Swagger UI
 	b.Router.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
+
+	api := b.Router.PathPrefix(url).Subrouter()
+	api.Use(rateLimitIP, authenticate, tenantHandler.Middleware, rateLimit)
+	//Subscriptions
+	subscriptionService := service.NewSubscriptionService(b.Store.SubscriptionRepository(), b.Store.Transactor())
+	subscriptionHandler := handlers.NewCatalogHandler(subscriptionService, b.Pagination)
+	idempotency := middleware.Idempotency(b.Store.IdempotencyRepository(), b.IdempotencyTTL)
+	api.Handle("/subscription", write(idempotency(http.HandlerFunc(subscriptionHandler.Create)))).Methods("POST")
+	api.Handle("/subscription", write(http.HandlerFunc(subscriptionHandler.Update))).Methods("PATCH")
+	api.Handle("/subscription/{id}", write(http.HandlerFunc(subscriptionHandler.Patch))).Methods("PATCH")
+	api.Handle("/subscription/{id}", write(http.HandlerFunc(subscriptionHandler.Delete))).Methods("DELETE")
+	api.Handle("/subscription/{id}", read(http.HandlerFunc(subscriptionHandler.GetById))).Methods("GET")
+	api.Handle("/subscriptions/total", reports(http.HandlerFunc(subscriptionHandler.GetTotal))).Methods("GET")
+	eventHandler := handlers.NewEventHandler(service.NewEventService(b.Store.OutboxRepository(), b.Events))
+	api.Handle("/subscriptions/events", read(feature(features.EventsStream, eventHandler.Stream))).Methods("GET")
+	api.Handle("/subscriptions", read(http.HandlerFunc(subscriptionHandler.GetAll))).Methods("GET")
+	//Webhooks: получают события по всем пользователям, поэтому нужно отдельное разрешение
+	webhookHandler := handlers.NewWebhookHandler(service.NewWebhookService(b.Store.WebhookRepository()), b.Pagination)
+	api.Handle("/webhooks", manageWebhooks(http.HandlerFunc(webhookHandler.Create))).Methods("POST")
+	api.Handle("/webhooks", manageWebhooks(http.HandlerFunc(webhookHandler.GetAll))).Methods("GET")
+	api.Handle("/webhooks/{id}", manageWebhooks(http.HandlerFunc(webhookHandler.GetById))).Methods("GET")
+	api.Handle("/webhooks/{id}", manageWebhooks(http.HandlerFunc(webhookHandler.Update))).Methods("PATCH")
+	api.Handle("/webhooks/{id}", manageWebhooks(http.HandlerFunc(webhookHandler.Delete))).Methods("DELETE")
+	api.Handle("/webhooks/{id}/deliveries", manageWebhooks(http.HandlerFunc(webhookHandler.GetDeliveries))).Methods("GET")
+	api.Handle("/webhooks/{id}/deliveries/{delivery_id}/retry", manageWebhooks(http.HandlerFunc(webhookHandler.RetryDelivery))).Methods("POST")
+	//Calendar
+	api.Handle("/users/{id}/calendar-token", read(feature(features.Calendar, calendarHandler.IssueToken))).Methods("POST")
+	//GraphQL: только чтение, разрешения на отдельные поля проверяет сервис
+	graphqlHandler := graphqlHandlers.NewHandler(subscriptionService, b.Pagination)
+	api.Handle("/graphql", reports(feature(features.GraphQL, graphqlHandler.ServeHTTP))).Methods("GET", "POST")
+
+	admin := b.Router.PathPrefix("/admin").Subrouter()
+	admin.Use(rateLimitIP, authenticate, tenantHandler.Middleware, rateLimit)
+	logLevelHandler := handlers.NewLogLevelHandler()
+	manageLogLevel := middleware.RequirePermission(auth.PermAdminLogLevel)
+	admin.Handle("/log-level", manageLogLevel(http.HandlerFunc(logLevelHandler.Get))).Methods("GET")
+	admin.Handle("/log-level", manageLogLevel(http.HandlerFunc(logLevelHandler.Set))).Methods("PUT")
+	apiKeyHandler := handlers.NewAPIKeyHandler(b.APIKeys, b.Pagination)
+	manageKeys := middleware.RequirePermission(auth.PermAdminAPIKeys)
+	admin.Handle("/api-keys", manageKeys(http.HandlerFunc(apiKeyHandler.Create))).Methods("POST")
+	admin.Handle("/api-keys", manageKeys(http.HandlerFunc(apiKeyHandler.GetAll))).Methods("GET")
+	admin.Handle("/api-keys/{id}", manageKeys(http.HandlerFunc(apiKeyHandler.Revoke))).Methods("DELETE")
+	//Tenants: заводит только субъект платформы, это проверяет сервис
+	manageTenants := middleware.RequirePermission(auth.PermAdminTenants)
+	admin.Handle("/tenants", manageTenants(http.HandlerFunc(tenantHandler.Create))).Methods("POST")
+	admin.Handle("/tenants", manageTenants(http.HandlerFunc(tenantHandler.GetAll))).Methods("GET")
+	admin.Handle("/tenants/{id}", manageTenants(http.HandlerFunc(tenantHandler.GetById))).Methods("GET")
 }
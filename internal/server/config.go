@@ -1,18 +1,202 @@
 package server
 
-import "awesomeProject1/internal/store"
+import (
+	"awesomeProject1/internal/auth"
+	"awesomeProject1/internal/features"
+	"awesomeProject1/internal/middleware"
+	"awesomeProject1/internal/pagination"
+	"awesomeProject1/internal/ratelimit"
+	"awesomeProject1/internal/store"
+	"awesomeProject1/internal/tracing"
+	"awesomeProject1/internal/webhooks"
+	"awesomeProject1/pkg/configLoader"
+	"awesomeProject1/pkg/logger"
+	"awesomeProject1/pkg/validator"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"time"
+)
 
 type Config struct {
 	BindAddr string `yaml:"bind_addr"`
-	LogLevel string `yaml:"log_level"`
-	LogDir   string `yaml:"log_dir"`
-	Storage  *store.Config
+	// GrpcBindAddr — адрес gRPC API, пустое значение отключает его
+	GrpcBindAddr string `yaml:"grpc_bind_addr"`
+	// MetricsBindAddr — адрес /metrics для Prometheus. Бизнес-метрики считаются по всем тенантам,
+	// поэтому порт не должен быть доступен снаружи. Пустое значение отключает метрики
+	MetricsBindAddr string `yaml:"metrics_bind_addr"`
+	// Logging — log_level, log_format, log_dir и log_sinks на верхнем уровне файла
+	Logging logger.Config `yaml:",inline"`
+	// IdempotencyTTL — сколько хранится ответ на запрос с Idempotency-Key
+	IdempotencyTTL time.Duration `yaml:"idempotency_ttl"`
+	HTTP           *HTTPConfig   `yaml:"http"`
+	Storage        *store.Config
+	Webhooks       *webhooks.Config `yaml:"webhooks"`
+	Tracing        *tracing.Config  `yaml:"tracing"`
+	Auth           *auth.Config     `yaml:"auth"`
+	// ConfigWatchInterval — как часто проверять, изменился ли файл конфигурации, 0 — только по SIGHUP
+	ConfigWatchInterval time.Duration `yaml:"config_watch_interval"`
+
+	// Секции ниже перечитываются без перезапуска, как и уровни лога
+	CORS       *middleware.CORSConfig `yaml:"cors"`
+	Features   features.Config        `yaml:"features"`
+	Pagination *pagination.Config     `yaml:"pagination"`
+	RateLimit  *ratelimit.Config      `yaml:"rate_limit"`
+}
+
+// HTTPConfig — таймауты HTTP сервера
+type HTTPConfig struct {
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	ReadTimeout       time.Duration `yaml:"read_timeout"`
+	// WriteTimeout не действует на поток SSE, он сам продлевает срок записи
+	WriteTimeout time.Duration `yaml:"write_timeout"`
+	IdleTimeout  time.Duration `yaml:"idle_timeout"`
+	// DrainDelay — сколько после сигнала остановки принимать запросы с /readyz = 503, прежде чем закрыть порт
+	DrainDelay time.Duration `yaml:"drain_delay"`
+	// ShutdownTimeout — сколько при остановке ждать завершения начатых запросов и фоновых задач
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
 }
 
 func NewConfig() *Config {
 	return &Config{
-		BindAddr: ":8080",
-		LogLevel: "debug",
-		Storage:  store.NewConfig(),
+		BindAddr:        ":8080",
+		GrpcBindAddr:    ":9090",
+		MetricsBindAddr: "127.0.0.1:9100",
+		Logging:         logger.Config{Level: "debug", Format: logger.FormatText},
+		IdempotencyTTL:  24 * time.Hour,
+		HTTP:            NewHTTPConfig(),
+		Storage:         store.NewConfig(),
+		Webhooks:        webhooks.NewConfig(),
+		Tracing:         tracing.NewConfig(),
+		Auth:            auth.NewConfig(),
+		CORS:            middleware.NewCORSConfig(),
+		Features:        features.NewConfig(),
+		Pagination:      pagination.NewConfig(),
+		RateLimit:       ratelimit.NewConfig(),
+	}
+}
+
+func NewHTTPConfig() *HTTPConfig {
+	return &HTTPConfig{
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       15 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       60 * time.Second,
+		DrainDelay:        5 * time.Second,
+		ShutdownTimeout:   30 * time.Second,
+	}
+}
+
+// Validate проверяет всю конфигурацию и возвращает сразу все найденные проблемы
+func (c *Config) Validate() error {
+	v := validator.New()
+	checkAddr(v, "bind_addr", c.BindAddr, true)
+	checkAddr(v, "grpc_bind_addr", c.GrpcBindAddr, false)
+	checkAddr(v, "metrics_bind_addr", c.MetricsBindAddr, false)
+	if c.ConfigWatchInterval < 0 {
+		v.AddError("config_watch_interval", validator.CodeTooSmall, "must not be negative")
 	}
+	if c.IdempotencyTTL <= 0 {
+		v.AddError("idempotency_ttl", validator.CodeTooSmall, "must be a positive duration")
+	}
+
+	errors := v.GetErrors()
+	errors = append(errors, c.Logging.Validate()...)
+	errors = append(errors, configLoader.Prefix("http", c.HTTP.Validate())...)
+	errors = append(errors, configLoader.Prefix("storage", c.Storage.Validate())...)
+	errors = append(errors, configLoader.Prefix("webhooks", c.Webhooks.Validate())...)
+	errors = append(errors, configLoader.Prefix("tracing", c.Tracing.Validate())...)
+	errors = append(errors, configLoader.Prefix("auth", c.Auth.Validate())...)
+	errors = append(errors, configLoader.Prefix("cors", c.CORS.Validate())...)
+	errors = append(errors, configLoader.Prefix("features", c.Features.Validate())...)
+	errors = append(errors, configLoader.Prefix("pagination", c.Pagination.Validate())...)
+	errors = append(errors, configLoader.Prefix("rate_limit", c.RateLimit.Validate())...)
+	return configLoader.ValidationError(errors)
+}
+
+// Validate проверяет таймауты, имена полей — ключи секции http
+func (c *HTTPConfig) Validate() []validator.FieldError {
+	v := validator.New()
+	timeouts := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"read_header_timeout", c.ReadHeaderTimeout},
+		{"read_timeout", c.ReadTimeout},
+		{"write_timeout", c.WriteTimeout},
+		{"idle_timeout", c.IdleTimeout},
+		{"drain_delay", c.DrainDelay},
+	}
+	// 0 у таймаутов net/http означает «без ограничения»
+	for _, timeout := range timeouts {
+		if timeout.value < 0 {
+			v.AddError(timeout.name, validator.CodeTooSmall, "must not be negative")
+		}
+	}
+	if c.ShutdownTimeout <= 0 {
+		v.AddError("shutdown_timeout", validator.CodeTooSmall, "must be a positive duration")
+	}
+	return v.GetErrors()
+}
+
+// checkAddr проверяет адрес вида host:port, пустой допускается только для необязательных адресов
+func checkAddr(v *validator.Validator, field, addr string, required bool) {
+	if addr == "" {
+		if required {
+			v.AddError(field, validator.CodeRequired, "is required")
+		}
+		return
+	}
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		v.AddError(field, validator.CodeInvalidFormat, fmt.Sprintf("must be host:port or :port, got %q", addr))
+	}
+}
+
+// ConfigSource — откуда читается конфигурация при запуске и перезагрузке
+type ConfigSource struct {
+	Path string
+	// Optional — без файла запускаться на значениях по умолчанию и переменных окружения
+	Optional bool
+}
+
+// Load читает конфигурацию: значения по умолчанию, файл, переменные APP_*.
+// warnings — переменные APP_*, которые не совпали ни с одним ключом
+func (s ConfigSource) Load() (config *Config, warnings []string, err error) {
+	config = NewConfig()
+
+	warnings, err = configLoader.Load(s.Path, config)
+	if errors.Is(err, fs.ErrNotExist) && s.Optional {
+		warnings, err = configLoader.Load("", config)
+	}
+	if err != nil {
+		return nil, warnings, err
+	}
+
+	return config, warnings, nil
+}
+
+// withReloadable возвращает копию c, в которую из next перенесено то, что можно поменять без перезапуска:
+// уровни лога, CORS, флаги возможностей, пагинация и лимиты запросов, кроме их хранилища. Остальное остаётся как при запуске
+func (c *Config) withReloadable(next *Config) *Config {
+	applied := *c
+	applied.Logging.Level = next.Logging.Level
+	applied.Logging.Sinks = make([]logger.SinkConfig, len(c.Logging.Sinks))
+	for i, sink := range c.Logging.Sinks {
+		applied.Logging.Sinks[i] = sink
+		for _, nextSink := range next.Logging.Sinks {
+			if nextSink.Name == sink.Name && nextSink.Type == sink.Type {
+				applied.Logging.Sinks[i].Level = nextSink.Level
+			}
+		}
+	}
+
+	applied.CORS = next.CORS
+	applied.Features = next.Features
+	applied.Pagination = next.Pagination
+
+	rateLimit := *next.RateLimit
+	rateLimit.Store = c.RateLimit.Store
+	applied.RateLimit = &rateLimit
+	return &applied
 }
@@ -1,33 +1,296 @@
 package server
 
 import (
+	"awesomeProject1/internal/auth"
+	"awesomeProject1/internal/events"
+	"awesomeProject1/internal/features"
+	"awesomeProject1/internal/grpcHandlers"
+	"awesomeProject1/internal/health"
+	"awesomeProject1/internal/metrics"
+	"awesomeProject1/internal/middleware"
+	"awesomeProject1/internal/pagination"
+	"awesomeProject1/internal/ratelimit"
 	"awesomeProject1/internal/server/builders"
+	"awesomeProject1/internal/service"
 	"awesomeProject1/internal/store"
+	"awesomeProject1/internal/tenant"
+	"awesomeProject1/internal/tracing"
+	"awesomeProject1/internal/webhooks"
 	"awesomeProject1/pkg/logger"
+	"context"
+	"errors"
+	"fmt"
 	"github.com/gorilla/mux"
+	"google.golang.org/grpc"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	tracingFlushTimeout = 5 * time.Second
+	// rateLimitSweepInterval — как часто забывать наполнившиеся вёдра лимитов в памяти
+	rateLimitSweepInterval = time.Minute
+	// idempotencySweepInterval — как часто удалять просроченные ключи идемпотентности
+	idempotencySweepInterval = 10 * time.Minute
+	// storeCheckTimeout — сколько ждать проверки роли соединения с базой при старте
+	storeCheckTimeout = 5 * time.Second
 )
 
 func (a *Api) configureRouter() {
 	router := mux.NewRouter()
+	router.Use(tracing.Middleware, middleware.RequestID, a.metrics.Middleware)
 	builder := &builders.Builder{
-		Router: router,
-		Store:  a.store,
+		Router:         router,
+		Store:          a.store,
+		IdempotencyTTL: a.config.Load().IdempotencyTTL,
+		Events:         a.events,
+		Health:         a.health,
+		Pagination:     a.pagination,
+		Features:       func() features.Config { return a.config.Load().Features },
+		Auth:           a.auth,
+		APIKeys:        a.apiKeys,
+		Tenants:        a.tenants,
+		RateLimit:      a.limiter,
 	}
 
 	builders.BuildRoutes(builder)
 	a.router = router
 }
 
+func (a *Api) configureHttp() {
+	config := a.config.Load()
+	cors := middleware.CORS(func() *middleware.CORSConfig { return a.config.Load().CORS })
+
+	a.httpServer = &http.Server{
+		Addr:              config.BindAddr,
+		Handler:           cors(a.router),
+		ReadHeaderTimeout: config.HTTP.ReadHeaderTimeout,
+		ReadTimeout:       config.HTTP.ReadTimeout,
+		WriteTimeout:      config.HTTP.WriteTimeout,
+		IdleTimeout:       config.HTTP.IdleTimeout,
+	}
+
+	// Shutdown не прерывает долгие запросы, поэтому потоки SSE закрываются отдельно:
+	// клиенты переподключатся к другому экземпляру с Last-Event-ID
+	a.httpServer.RegisterOnShutdown(a.events.Shutdown)
+}
+
+// configureMetrics готовит отдельный сервер для /metrics, без аутентификации и лимитов основного API
+func (a *Api) configureMetrics() {
+	config := a.config.Load()
+	router := http.NewServeMux()
+	router.Handle("GET /metrics", a.metrics.Handler())
+
+	a.metricsServer = &http.Server{
+		Addr:              config.MetricsBindAddr,
+		Handler:           router,
+		ReadHeaderTimeout: config.HTTP.ReadHeaderTimeout,
+		ReadTimeout:       config.HTTP.ReadTimeout,
+		WriteTimeout:      config.HTTP.WriteTimeout,
+		IdleTimeout:       config.HTTP.IdleTimeout,
+	}
+}
+
+func (a *Api) configureGrpc() {
+	ipUnary, ipStream := grpcHandlers.IPRateLimitInterceptors(a.limiter)
+	unary, stream := grpcHandlers.AuthInterceptors(a.auth)
+	tenantUnary, tenantStream := grpcHandlers.TenantInterceptors(a.tenants)
+	limitUnary, limitStream := grpcHandlers.RateLimitInterceptors(a.limiter)
+	a.grpcServer = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(ipUnary, unary, tenantUnary, limitUnary),
+		grpc.ChainStreamInterceptor(ipStream, stream, tenantStream, limitStream),
+	)
+	builders.BuildGrpcServices(a.grpcServer, a.store, a.pagination)
+}
+
+func (a *Api) pagination() *pagination.Config {
+	return a.config.Load().Pagination
+}
+
 func (a *Api) configureLogger() error {
-	return logger.Init(a.config.LogLevel, a.config.LogDir)
+	return logger.Init(&a.config.Load().Logging)
 }
 
-func (a *Api) configureStore() error {
-	store := store.New(a.config.Storage)
+func (a *Api) configureStore(ctx context.Context) error {
+	store := store.New(a.config.Load().Storage)
+	store.AddQueryTracer(tracing.QueryTracer())
+	store.AddQueryTracer(a.metrics.QueryTracer())
 
 	if err := store.Start(); err != nil {
 		return err
 	}
 	a.store = store
+
+	// Суперпользователь и роли с BYPASSRLS не видят политик тенантов, данные разделяют только условия в запросах
+	checkCtx, cancel := context.WithTimeout(ctx, storeCheckTimeout)
+	defer cancel()
+	if enforced, err := store.RowSecurityEnforced(checkCtx); err != nil {
+		logger.FromContext(ctx).WithError(err).Warn("Store -> RowSecurityEnforced")
+	} else if !enforced {
+		logger.FromContext(ctx).Warn("Store -> database role bypasses row level security, set storage.db_role to isolate tenants in the database")
+	}
+
+	a.metrics.Register(metrics.NewPoolCollector(store.Stat))
+	a.metrics.Register(metrics.NewBusinessCollector(store.SubscriptionRepository().GetActiveStats))
+	return nil
+}
+
+// configureAuth собирает способы аутентификации: ключи API из базы и, если настроены, JWT.
+// Роли субъектов переводятся в разрешения по политике из auth.policy_file, тенант запроса определяет сервис тенантов
+func (a *Api) configureAuth(ctx context.Context) error {
+	config := a.config.Load().Auth
+	policy, err := auth.LoadPolicy(config.PolicyFile)
+	if err != nil {
+		return err
+	}
+	a.apiKeys = service.NewAPIKeyService(a.store.APIKeyRepository(), policy, config.KeyTouchInterval)
+	a.tenants = service.NewTenantService(a.store.TenantRepository())
+
+	if !config.Enabled {
+		logger.FromContext(ctx).Warn("Auth -> disabled, every request is served as anonymous admin")
+		a.auth = auth.Chain{auth.Disabled()}
+		return nil
+	}
+
+	a.auth = auth.Chain{policy.Bind(auth.NewAPIKeyAuthenticator(verifyAPIKey(a.apiKeys)))}
+	if config.JWT.Configured() {
+		jwtAuthenticator, err := auth.NewJWTAuthenticator(config.JWT)
+		if err != nil {
+			return err
+		}
+		a.auth = append(a.auth, policy.Bind(jwtAuthenticator))
+	}
 	return nil
 }
+
+// verifyAPIKey переводит ответ сервиса ключей в ошибки auth: неподходящий ключ — 401, остальное — 500
+func verifyAPIKey(keys service.IAPIKeyService) auth.KeyVerifier {
+	return func(ctx context.Context, key string) (*auth.Principal, error) {
+		principal, sErr := keys.Authenticate(ctx, key)
+		if sErr == nil {
+			return principal, nil
+		}
+		if sErr.Kind == service.KindUnauthorized {
+			return nil, fmt.Errorf("%w: %s", auth.ErrInvalidCredentials, sErr.Message)
+		}
+		return nil, sErr
+	}
+}
+
+// configureRateLimit выбирает хранилище вёдер: память экземпляра или общая таблица в базе.
+// Хранилище меняется только перезапуском, сами лимиты читаются из текущей конфигурации
+func (a *Api) configureRateLimit() {
+	var buckets ratelimit.Store
+	switch a.config.Load().RateLimit.Store {
+	case ratelimit.StorePostgres:
+		buckets = a.store.RateLimitRepository()
+	default:
+		a.buckets = ratelimit.NewMemoryStore()
+		buckets = a.buckets
+	}
+	a.limiter = ratelimit.NewLimiter(buckets, func() *ratelimit.Config { return a.config.Load().RateLimit })
+}
+
+// sweepRateLimits чистит вёдра в памяти, вёдра в базе удаляет subctl purge
+func (a *Api) sweepRateLimits(ctx context.Context) {
+	if a.buckets != nil {
+		a.buckets.Run(ctx, rateLimitSweepInterval)
+	}
+}
+
+// sweepIdempotencyKeys удаляет просроченные ключи идемпотентности всех тенантов,
+// без него таблица растёт, пока не запустят subctl purge housekeeping
+func (a *Api) sweepIdempotencyKeys(ctx context.Context) {
+	ticker := time.NewTicker(idempotencySweepInterval)
+	defer ticker.Stop()
+
+	ctx = tenant.WithAll(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		deleted, err := a.store.IdempotencyRepository().DeleteExpired(ctx)
+		if err != nil {
+			logger.FromContext(ctx).WithError(err).Error("Idempotency -> DeleteExpired")
+			continue
+		}
+		if deleted > 0 {
+			logger.FromContext(ctx).WithFields(logger.Fields{"deleted": deleted}).Info("Idempotency -> expired keys deleted")
+		}
+	}
+}
+
+func (a *Api) configureWebhooks() {
+	a.dispatcher = webhooks.NewDispatcher(a.config.Load().Webhooks, a.store.OutboxRepository(), a.store.WebhookRepository())
+}
+
+func (a *Api) configureEvents() {
+	a.events = events.NewHub(a.store.OutboxRepository(), a.store.EventListener())
+}
+
+// configureHealth регистрирует проверки готовности. Новые зависимости (кэш, брокер) добавляются сюда же
+func (a *Api) configureHealth(ctx context.Context) {
+	repo := a.store.HealthRepository()
+
+	latest, latestErr := a.config.Load().Storage.LatestMigration()
+	if latestErr != nil {
+		logger.FromContext(ctx).WithError(latestErr).Error("Health -> LatestMigration")
+	}
+
+	newerSchema := sync.Once{}
+	a.health = health.New(
+		health.Check{Name: "postgres", Critical: true, Fn: repo.Ping},
+		health.Check{Name: "migrations", Critical: true, Fn: func(ctx context.Context) error {
+			if latestErr != nil {
+				return fmt.Errorf("cannot read migrations: %w", latestErr)
+			}
+
+			version, dirty, err := repo.SchemaVersion(ctx)
+			if err != nil {
+				return err
+			}
+			if dirty {
+				return fmt.Errorf("schema version %d is dirty", version)
+			}
+			if version < latest {
+				return fmt.Errorf("schema version %d, expected %d", version, latest)
+			}
+			// Более новую схему накатил следующий релиз при поэтапном обновлении, миграции совместимы
+			// с предыдущей версией, поэтому экземпляр остаётся готовым
+			if version > latest {
+				newerSchema.Do(func() {
+					logger.FromContext(ctx).WithFields(logger.Fields{"version": version, "latest": latest}).
+						Warn("Health -> schema version is newer than this build knows")
+				})
+			}
+			return nil
+		}},
+		// Без LISTEN перестают приходить события SSE, остальной API работает
+		health.Check{Name: "event_listener", Fn: func(ctx context.Context) error {
+			if !a.events.Listening() {
+				return errors.New("not listening for subscription events")
+			}
+			return nil
+		}},
+	)
+}
+
+// configureTracing возвращает функцию, которая при остановке отправляет накопленные span
+func (a *Api) configureTracing(ctx context.Context) (func(), error) {
+	shutdown, err := tracing.Init(ctx, a.config.Load().Tracing)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), tracingFlushTimeout)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			logger.FromContext(ctx).WithError(err).Error("Tracing -> shutdown")
+		}
+	}, nil
+}
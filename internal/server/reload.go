@@ -0,0 +1,86 @@
+package server
+
+import (
+	"awesomeProject1/pkg/configLoader"
+	"awesomeProject1/pkg/logger"
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// watchConfig перечитывает конфигурацию по SIGHUP, а при заданном config_watch_interval —
+// ещё и когда у файла меняется время изменения или размер
+func (a *Api) watchConfig(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if interval := a.config.Load().ConfigWatchInterval; interval > 0 && a.source.Path != "" {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	version := fileVersion(a.source.Path)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			logger.FromContext(ctx).Info("Config -> SIGHUP received, reloading")
+		case <-tick:
+			if current := fileVersion(a.source.Path); current == version {
+				continue
+			}
+			logger.FromContext(ctx).WithFields(logger.Fields{"path": a.source.Path}).Info("Config -> file changed, reloading")
+		}
+
+		version = fileVersion(a.source.Path)
+		a.reload(ctx)
+	}
+}
+
+// reload перечитывает конфигурацию и применяет то, что можно поменять без перезапуска.
+// С ошибками в новой конфигурации сервис продолжает работать со старой
+func (a *Api) reload(ctx context.Context) {
+	log := logger.FromContext(ctx)
+	next, warnings, err := a.source.Load()
+	for _, warning := range warnings {
+		log.WithFields(logger.Fields{"warning": warning}).Warn("Config -> reload")
+	}
+	if err == nil {
+		err = next.Validate()
+	}
+	if err != nil {
+		log.WithError(err).Error("Config -> reload failed, keeping current config")
+		return
+	}
+
+	applied := a.config.Load().withReloadable(next)
+	for _, key := range configLoader.Changed(applied, next) {
+		log.WithFields(logger.Fields{"key": key}).Warn("Config -> reload -> changed, restart the service to apply it")
+	}
+
+	if err := logger.Log.ApplyLevels(&applied.Logging); err != nil {
+		log.WithError(err).Error("Config -> reload -> ApplyLevels")
+	}
+	a.config.Store(applied)
+
+	log.Info("Config -> reloaded")
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func fileVersion(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}
+}
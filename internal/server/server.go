@@ -1,36 +1,256 @@
 package server
 
 import (
+	"awesomeProject1/internal/auth"
+	"awesomeProject1/internal/events"
+	"awesomeProject1/internal/health"
+	"awesomeProject1/internal/metrics"
+	"awesomeProject1/internal/ratelimit"
+	"awesomeProject1/internal/service"
 	"awesomeProject1/internal/store"
+	"awesomeProject1/internal/webhooks"
+	"awesomeProject1/pkg/configLoader"
 	"awesomeProject1/pkg/logger"
+	"context"
+	"errors"
+	"net"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/gorilla/mux"
+	"google.golang.org/grpc"
 )
 
 type Api struct {
-	config *Config
-	router *mux.Router
-	store  *store.Store
+	// config — текущая конфигурация, при перезагрузке заменяется целиком, см. reload
+	config     *configLoader.Snapshot[Config]
+	source     ConfigSource
+	router     *mux.Router
+	httpServer *http.Server
+	// metricsServer отдаёт /metrics на metrics_bind_addr
+	metricsServer *http.Server
+	grpcServer    *grpc.Server
+	store         *store.Store
+	dispatcher    *webhooks.Dispatcher
+	events        *events.Hub
+	health        *health.Health
+	metrics       *metrics.Metrics
+	auth          auth.Chain
+	apiKeys       *service.APIKeyService
+	tenants       *service.TenantService
+	limiter       *ratelimit.Limiter
+	// buckets — вёдра лимитов в памяти, nil при rate_limit.store = postgres
+	buckets *ratelimit.MemoryStore
 }
 
-func New(config *Config) *Api {
+func New(config *Config, source ConfigSource) *Api {
 	return &Api{
-		config: config,
+		config: configLoader.NewSnapshot(config),
+		source: source,
 	}
 }
 
-func (api *Api) Start() error {
+// Run запускает сервер и работает, пока не отменён ctx или один из серверов не упал.
+// После этого выполняется остановка: SSE потоки закрываются, начатые запросы дорабатывают
+// в пределах http.shutdown_timeout, затем останавливаются фоновые задачи, закрывается пул
+// соединений с базой, отправляются накопленные span и в конце закрывается лог
+func (api *Api) Run(ctx context.Context) error {
 	if err := api.configureLogger(); err != nil {
 		return err
 	}
-	defer logger.Log.Close()
+	defer api.closeLogger()
 
-	if err := api.configureStore(); err != nil {
+	flushTraces, err := api.configureTracing(ctx)
+	if err != nil {
 		return err
 	}
+	defer flushTraces()
 
+	api.metrics = metrics.New()
+	if err := api.configureStore(ctx); err != nil {
+		return err
+	}
+	defer api.store.Stop()
+
+	if err := api.configureAuth(ctx); err != nil {
+		return err
+	}
+
+	api.configureRateLimit()
+	api.configureEvents()
+	api.configureHealth(ctx)
 	api.configureRouter()
+	api.configureHttp()
+	api.configureMetrics()
+	api.configureGrpc()
+	api.configureWebhooks()
+
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	workers := sync.WaitGroup{}
+	workers.Add(5)
+	go func() {
+		defer workers.Done()
+		api.events.Run(workersCtx)
+	}()
+	go func() {
+		defer workers.Done()
+		api.dispatcher.Run(workersCtx)
+	}()
+	go func() {
+		defer workers.Done()
+		api.watchConfig(workersCtx)
+	}()
+	go func() {
+		defer workers.Done()
+		api.sweepRateLimits(workersCtx)
+	}()
+	go func() {
+		defer workers.Done()
+		api.sweepIdempotencyKeys(workersCtx)
+	}()
+
+	errs := make(chan error, 3)
+
+	config := api.config.Load()
+	listener, err := net.Listen("tcp", config.BindAddr)
+	if err != nil {
+		return err
+	}
+	go func() {
+		if err := api.httpServer.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+			errs <- err
+		}
+	}()
+
+	// gRPC API работает на отдельном порту, пустой адрес в конфиге отключает его
+	if config.GrpcBindAddr != "" {
+		grpcListener, err := net.Listen("tcp", config.GrpcBindAddr)
+		if err != nil {
+			api.shutdown(ctx, stopWorkers, &workers)
+			return err
+		}
+		go func() {
+			if err := api.grpcServer.Serve(grpcListener); err != nil {
+				errs <- err
+			}
+		}()
+	}
+
+	if config.MetricsBindAddr != "" {
+		metricsListener, err := net.Listen("tcp", config.MetricsBindAddr)
+		if err != nil {
+			api.shutdown(ctx, stopWorkers, &workers)
+			return err
+		}
+		go func() {
+			if err := api.metricsServer.Serve(metricsListener); !errors.Is(err, http.ErrServerClosed) {
+				errs <- err
+			}
+		}()
+	}
+
+	log := logger.FromContext(ctx)
+	log.WithFields(logger.Fields{
+		"http":    config.BindAddr,
+		"grpc":    config.GrpcBindAddr,
+		"metrics": config.MetricsBindAddr,
+	}).Info("Server started")
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		log.Info("Server -> shutdown requested")
+	case runErr = <-errs:
+		log.WithError(runErr).Error("Server -> stopped with error")
+	}
+
+	if err := api.shutdown(ctx, stopWorkers, &workers); err != nil && runErr == nil {
+		runErr = err
+	}
 
-	return http.ListenAndServe(api.config.BindAddr, api.router)
+	return runErr
+}
+
+// shutdown останавливает серверы и фоновые задачи, общий срок — http.shutdown_timeout.
+// Перед этим /readyz в течение http.drain_delay отвечает 503, чтобы балансировщик успел убрать экземпляр
+func (api *Api) shutdown(ctx context.Context, stopWorkers context.CancelFunc, workers *sync.WaitGroup) error {
+	log := logger.FromContext(ctx)
+	api.health.SetDraining()
+	if delay := api.config.Load().HTTP.DrainDelay; delay > 0 {
+		log.WithFields(logger.Fields{"delay": delay.String()}).Info("Server -> draining")
+		time.Sleep(delay)
+	}
+
+	deadline := time.Now().Add(api.config.Load().HTTP.ShutdownTimeout)
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	servers := sync.WaitGroup{}
+	servers.Add(2)
+	go func() {
+		defer servers.Done()
+		api.stopGrpc(ctx)
+	}()
+	go func() {
+		defer servers.Done()
+		if err := api.metricsServer.Shutdown(ctx); err != nil {
+			api.metricsServer.Close()
+		}
+	}()
+
+	// Shutdown перестаёт принимать соединения и ждёт завершения начатых запросов
+	err := api.httpServer.Shutdown(ctx)
+	if err != nil {
+		log.WithError(err).Error("Server -> http shutdown")
+		api.httpServer.Close()
+	}
+	servers.Wait()
+
+	stopWorkers()
+	if !waitTimeout(workers, time.Until(deadline)) {
+		log.Warn("Server -> background workers did not stop in time")
+	}
+
+	log.Info("Server stopped")
+	return err
+}
+
+func (api *Api) stopGrpc(ctx context.Context) {
+	stopped := make(chan struct{})
+	go func() {
+		api.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		logger.FromContext(ctx).Warn("Server -> grpc graceful stop timed out")
+		api.grpcServer.Stop()
+	}
+}
+
+func (api *Api) closeLogger() {
+	if err := logger.Log.Close(); err != nil {
+		println("Cannot close log file: " + err.Error())
+	}
+}
+
+// waitTimeout ждёт wg не дольше timeout, false — не дождались
+func waitTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(max(timeout, 0)):
+		return false
+	}
 }
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"awesomeProject1/internal/auth"
+	"awesomeProject1/internal/dto"
+	"awesomeProject1/internal/repository"
+	"awesomeProject1/internal/tenant"
+	"awesomeProject1/pkg/logger"
+	"awesomeProject1/pkg/validator"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ErrorAPIKeyNotFound = "API key not found or already revoked, please check the provided id"
+	ErrorAPIKeyInvalid  = "API key is invalid, expired or revoked"
+	ErrorAPIKeyTenant   = "API key must be bound to a tenant, select it with " + tenant.Header + " or set platform"
+
+	// apiKeyPrefix отличает ключи API от других секретов, например в сканерах утечек
+	apiKeyPrefix = "sk_"
+	// apiKeyShownLength — сколько первых символов ключа хранится открыто, чтобы узнать его в списке
+	apiKeyShownLength = len(apiKeyPrefix) + 8
+)
+
+type IAPIKeyService interface {
+	// Create выпускает ключ тенанта запроса или, с platform, ключ платформы. Ключ целиком возвращается только здесь
+	Create(ctx context.Context, req *dto.CreateAPIKeyRequest) (*dto.APIKeyResponse, *Error)
+	GetAll(ctx context.Context, offset, limit int) (*dto.APIKeyListResponse, *Error)
+	Revoke(ctx context.Context, id uuid.UUID) *Error
+	// Authenticate находит действующий ключ и возвращает его владельца с ролями ключа
+	Authenticate(ctx context.Context, key string) (*auth.Principal, *Error)
+}
+
+type APIKeyService struct {
+	APIKeyRepository repository.IAPIKeyRepository
+	// policy — роли, которые можно назначить ключу
+	policy *auth.Policy
+	// touchInterval — как часто обновлять время последнего использования ключа
+	touchInterval time.Duration
+}
+
+func NewAPIKeyService(repo repository.IAPIKeyRepository, policy *auth.Policy, touchInterval time.Duration) *APIKeyService {
+	return &APIKeyService{APIKeyRepository: repo, policy: policy, touchInterval: touchInterval}
+}
+
+func (c *APIKeyService) Create(ctx context.Context, req *dto.CreateAPIKeyRequest) (*dto.APIKeyResponse, *Error) {
+	if _, sErr := Authorize(ctx, auth.PermAdminAPIKeys); sErr != nil {
+		return nil, sErr
+	}
+	// Ключ платформы видит всех тенантов, поэтому выпускает его только субъект платформы
+	if req.Platform {
+		if sErr := authorizePlatform(ctx); sErr != nil {
+			return nil, sErr
+		}
+	}
+
+	// Роли проверяются по политике здесь, а не в DTO: политика задаётся конфигурацией
+	var errors []validator.FieldError
+	for i, role := range req.Roles {
+		if !c.policy.HasRole(role) {
+			errors = append(errors, validator.FieldError{
+				Field:   fmt.Sprintf("roles[%d]", i),
+				Code:    validator.CodeInvalidFormat,
+				Message: fmt.Sprintf("[roles] - Unknown role %q", role),
+			})
+		}
+	}
+	if len(errors) > 0 {
+		return nil, NewValidationError(errors)
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, internalError("CreateAPIKey", err)
+	}
+
+	key := apiKeyPrefix + secret
+	apiKey := req.ToAPIKey()
+	apiKey.Prefix = key[:apiKeyShownLength]
+	apiKey.KeyHash = hashToken(key)
+	if req.Platform {
+		// Запись без тенанта проходит политики RLS только без ограничения тенантом
+		ctx = tenant.WithAll(ctx)
+	} else {
+		tenantId, ok := tenant.FromContext(ctx)
+		if !ok {
+			return nil, NewError(KindInvalid, ErrorAPIKeyTenant)
+		}
+		apiKey.TenantID = uuid.NullUUID{UUID: tenantId, Valid: true}
+	}
+
+	var item *dto.APIKey
+	err = withRetry(ctx, func() (err error) {
+		item, err = c.APIKeyRepository.Create(ctx, apiKey)
+		return err
+	})
+
+	if err != nil {
+		return nil, dbError("CreateAPIKey", err)
+	}
+
+	response := item.ToResponse()
+	response.Key = key
+	return response, nil
+}
+
+func (c *APIKeyService) GetAll(ctx context.Context, offset, limit int) (*dto.APIKeyListResponse, *Error) {
+	if _, sErr := Authorize(ctx, auth.PermAdminAPIKeys); sErr != nil {
+		return nil, sErr
+	}
+
+	var items []*dto.APIKey
+	var total int
+	err := withRetry(ctx, func() (err error) {
+		items, total, err = c.APIKeyRepository.FindAll(ctx, offset, limit)
+		return err
+	})
+
+	if err != nil {
+		return nil, dbError("GetAPIKeys", err)
+	}
+
+	responses := make([]*dto.APIKeyResponse, len(items))
+	for i, item := range items {
+		responses[i] = item.ToResponse()
+	}
+
+	return &dto.APIKeyListResponse{
+		Total:  total,
+		Offset: offset,
+		Limit:  limit,
+		Keys:   responses,
+	}, nil
+}
+
+// Revoke отзывает ключ, запросы с ним перестают проходить сразу
+func (c *APIKeyService) Revoke(ctx context.Context, id uuid.UUID) *Error {
+	if _, sErr := Authorize(ctx, auth.PermAdminAPIKeys); sErr != nil {
+		return sErr
+	}
+
+	var ok bool
+	err := withRetry(ctx, func() (err error) {
+		ok, err = c.APIKeyRepository.Revoke(ctx, id)
+		return err
+	})
+
+	if err != nil {
+		return dbError("RevokeAPIKey", err)
+	}
+
+	if !ok {
+		return NewError(KindNotFound, ErrorAPIKeyNotFound)
+	}
+
+	return nil
+}
+
+func (c *APIKeyService) Authenticate(ctx context.Context, key string) (*auth.Principal, *Error) {
+	// Тенант запроса становится известен только из ключа, поэтому ключ ищется среди всех тенантов
+	ctx = tenant.WithAll(ctx)
+
+	var item *dto.APIKey
+	var ok bool
+	err := withRetry(ctx, func() (err error) {
+		item, ok, err = c.APIKeyRepository.FindByHash(ctx, hashToken(key))
+		return err
+	})
+
+	if err != nil {
+		return nil, dbError("AuthenticateAPIKey", err)
+	}
+
+	now := time.Now()
+	if !ok || !item.Active(now) {
+		return nil, NewError(KindUnauthorized, ErrorAPIKeyInvalid)
+	}
+
+	// Время последнего использования нужно только для списка ключей, ошибка не мешает запросу
+	if !item.LastUsedAt.Valid || now.Sub(item.LastUsedAt.Time) >= c.touchInterval {
+		if err := c.APIKeyRepository.Touch(ctx, item.ID, c.touchInterval); err != nil {
+			logger.FromContext(ctx).WithError(err).Warn("APIKeyService -> Touch")
+		}
+	}
+
+	principal := &auth.Principal{
+		Subject: "api_key:" + item.ID.String(),
+		UserID:  item.UserID.UUID,
+		Roles:   item.Roles,
+		Method:  auth.MethodAPIKey,
+	}
+	if item.TenantID.Valid {
+		principal.TenantID = &item.TenantID.UUID
+	}
+
+	return principal, nil
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"awesomeProject1/internal/auth"
+	"awesomeProject1/internal/dto"
+	"awesomeProject1/internal/repository"
+	"awesomeProject1/internal/tenant"
+	"awesomeProject1/pkg/ical"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ErrorCalendarToken = "Calendar token is invalid or was revoked"
+
+	// calendarHorizonMonths — на сколько месяцев вперёд в календаре показываются списания
+	calendarHorizonMonths = 12
+	calendarProdID        = "-//Subscription API//Subscriptions Calendar//RU"
+	calendarUIDDomain     = "subscriptions"
+)
+
+type ICalendarService interface {
+	// IssueToken выпускает новый токен ссылки на календарь, старый перестаёт действовать
+	IssueToken(ctx context.Context, userId uuid.UUID) (string, *Error)
+	GetCalendar(ctx context.Context, userId uuid.UUID, token string) (*ical.Calendar, *Error)
+}
+
+type CalendarService struct {
+	SubscriptionRepository  repository.ISubscriptionRepository
+	CalendarTokenRepository repository.ICalendarTokenRepository
+}
+
+func NewCalendarService(subscriptions repository.ISubscriptionRepository, tokens repository.ICalendarTokenRepository) *CalendarService {
+	return &CalendarService{
+		SubscriptionRepository:  subscriptions,
+		CalendarTokenRepository: tokens,
+	}
+}
+
+// IssueToken доступен только администратору и самому пользователю: по токену календарь отдаётся без аутентификации
+func (c *CalendarService) IssueToken(ctx context.Context, userId uuid.UUID) (string, *Error) {
+	owner, sErr := ownerScope(ctx, auth.PermSubscriptionRead)
+	if sErr != nil {
+		return "", sErr
+	}
+	if sErr := checkOwner(owner, userId); sErr != nil {
+		return "", sErr
+	}
+
+	token, err := generateSecret()
+	if err != nil {
+		return "", internalError("IssueCalendarToken", err)
+	}
+
+	err = withRetry(ctx, func() error {
+		return c.CalendarTokenRepository.Save(ctx, userId, hashToken(token))
+	})
+
+	if err != nil {
+		return "", dbError("IssueCalendarToken", err)
+	}
+
+	return token, nil
+}
+
+// GetCalendar собирает календарь пользователя: событие на каждое предстоящее списание
+// по действующим подпискам и на дату окончания подписки. Тенант определяется по токену
+func (c *CalendarService) GetCalendar(ctx context.Context, userId uuid.UUID, token string) (*ical.Calendar, *Error) {
+	if token == "" {
+		return nil, NewError(KindForbidden, ErrorCalendarToken)
+	}
+
+	var tenantId uuid.UUID
+	var ok bool
+	err := withRetry(ctx, func() (err error) {
+		tenantId, ok, err = c.CalendarTokenRepository.FindTenant(tenant.WithAll(ctx), userId, hashToken(token))
+		return err
+	})
+
+	if err != nil {
+		return nil, dbError("GetCalendar", err)
+	}
+
+	if !ok {
+		return nil, NewError(KindForbidden, ErrorCalendarToken)
+	}
+	// Доступ проверен токеном, субъекта у запроса нет, подписки ограничены пользователем токена
+	ctx = auth.WithAllOwners(tenant.WithID(ctx, tenantId))
+
+	var items []*dto.Subscription
+	err = withRetry(ctx, func() (err error) {
+		items, err = c.SubscriptionRepository.FindByUserIds(ctx, []uuid.UUID{userId})
+		return err
+	})
+
+	if err != nil {
+		return nil, dbError("GetCalendar", err)
+	}
+
+	today := truncateDay(time.Now())
+	currentMonth := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	horizon := currentMonth.AddDate(0, calendarHorizonMonths, 0)
+
+	calendar := &ical.Calendar{ProdID: calendarProdID, Name: "Подписки"}
+	for _, item := range items {
+		if item.EndDate.Valid && item.EndDate.Time.Before(currentMonth) {
+			continue
+		}
+
+		for charge := firstCharge(item.StartDate, today); !charge.After(horizon); charge = charge.AddDate(0, 1, 0) {
+			if item.EndDate.Valid && charge.After(item.EndDate.Time) {
+				break
+			}
+
+			calendar.Events = append(calendar.Events, &ical.Event{
+				UID:         fmt.Sprintf("%s-%s@%s", item.ID, charge.Format("200601"), calendarUIDDomain),
+				Date:        charge,
+				Summary:     fmt.Sprintf("%s — %d ₽", item.ServiceName, item.Price),
+				Description: fmt.Sprintf("Списание по подписке %s: %d ₽", item.ServiceName, item.Price),
+				Stamp:       item.UpdatedAt,
+				Sequence:    item.Version,
+			})
+		}
+
+		if item.EndDate.Valid {
+			calendar.Events = append(calendar.Events, &ical.Event{
+				UID:         fmt.Sprintf("%s-end@%s", item.ID, calendarUIDDomain),
+				Date:        item.EndDate.Time,
+				Summary:     fmt.Sprintf("%s — окончание подписки", item.ServiceName),
+				Description: fmt.Sprintf("Подписка %s заканчивается в %s", item.ServiceName, dto.FormatMonthYear(item.EndDate.Time)),
+				Stamp:       item.UpdatedAt,
+				Sequence:    item.Version,
+			})
+		}
+	}
+
+	return calendar, nil
+}
+
+// firstCharge — первое списание не раньше today. Подписка списывается первого числа каждого месяца, начиная со start
+func firstCharge(start, today time.Time) time.Time {
+	start = truncateDay(start)
+	if !start.Before(today) {
+		return start
+	}
+
+	charge := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if charge.Before(today) {
+		charge = charge.AddDate(0, 1, 0)
+	}
+	return charge
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
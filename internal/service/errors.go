@@ -0,0 +1,160 @@
+package service
+
+import (
+	"awesomeProject1/pkg/validator"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+// ErrorKind — вид ошибки сервиса, транспорт (HTTP, gRPC) сам решает, каким кодом её отдать
+type ErrorKind int
+
+const (
+	KindInternal ErrorKind = iota
+	KindInvalid
+	KindNotFound
+	KindConflict
+	KindPrecondition
+	KindUnprocessable
+	KindForbidden
+	KindUnauthorized
+)
+
+const (
+	ErrorInternal     = "Something went wrong, try later..."
+	ErrorNotFoundById = "Nothing found, please check the provided id"
+	ErrorPrecondition = "Subscription was modified by someone else, reload it and try again"
+	ErrorValidation   = "Request contains invalid fields, see errors for details"
+)
+
+// Error — ошибка слоя сервисов, не зависящая от транспорта
+type Error struct {
+	Kind    ErrorKind
+	Message string
+	// Err — исходная ошибка для логов, клиенту не отдаётся
+	Err error
+	// Errors — ошибки валидации отдельных полей
+	Errors []validator.FieldError
+}
+
+func NewError(kind ErrorKind, message string) *Error {
+	return &Error{Kind: kind, Message: message}
+}
+
+// NewValidationError создаёт ошибку с подробностями по полям
+func NewValidationError(errors []validator.FieldError) *Error {
+	return &Error{Kind: KindInvalid, Message: ErrorValidation, Errors: errors}
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Коды SQLSTATE, которые сервис переводит в понятные клиенту ответы
+const (
+	pgCheckViolation            = "23514"
+	pgUniqueViolation           = "23505"
+	pgInvalidTextRepresentation = "22P02"
+	pgSerializationFailure      = "40001"
+	pgDeadlockDetected          = "40P01"
+)
+
+const (
+	maxDbRetries   = 3
+	dbRetryBackoff = 20 * time.Millisecond
+
+	errorCheckViolation  = "Subscription data violates a database constraint"
+	errorUniqueViolation = "Subscription conflicts with an existing one"
+	errorInvalidText     = "Provided value has invalid format"
+)
+
+// Ограничения таблицы subscriptions и поля запроса, к которым они относятся
+var constraintFields = map[string]validator.FieldError{
+	"valid_date_range": {
+		Field:   "end_date",
+		Code:    validator.CodeInvalidRange,
+		Message: "end_date must be after start_date",
+	},
+	"subscriptions_price_check": {
+		Field:   "price",
+		Code:    validator.CodeTooSmall,
+		Message: "[price] - Min required: 0",
+	},
+}
+
+// dbError переводит ошибку репозитория в ошибку сервиса. Исходная ошибка сохраняется в Err,
+// чтобы попасть в лог вместе с id ответа
+func dbError(op string, err error) *Error {
+	wrapped := fmt.Errorf("SubscriptionService -> %s -> %w", op, err)
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return internalError(op, err)
+	}
+
+	switch pgErr.Code {
+	case pgCheckViolation:
+		sErr := &Error{Kind: KindUnprocessable, Message: errorCheckViolation, Err: wrapped}
+		if fieldErr, ok := constraintFields[pgErr.ConstraintName]; ok {
+			sErr.Errors = []validator.FieldError{fieldErr}
+		}
+		return sErr
+	case pgUniqueViolation:
+		return &Error{Kind: KindConflict, Message: errorUniqueViolation, Err: wrapped}
+	case pgInvalidTextRepresentation:
+		return &Error{Kind: KindInvalid, Message: errorInvalidText, Err: wrapped}
+	}
+
+	return internalError(op, err)
+}
+
+// internalError оборачивает непредвиденную ошибку сервиса
+func internalError(op string, err error) *Error {
+	return &Error{
+		Kind:    KindInternal,
+		Message: ErrorInternal,
+		Err:     fmt.Errorf("SubscriptionService -> %s -> %w", op, err),
+	}
+}
+
+// withRetry повторяет операцию при ошибках сериализации и взаимных блокировках
+func withRetry(ctx context.Context, fn func() error) error {
+	var err error
+	for attempt := 0; attempt < maxDbRetries; attempt++ {
+		if err = fn(); err == nil || !isRetryable(err) {
+			return err
+		}
+
+		trace.SpanFromContext(ctx).AddEvent("retry", trace.WithAttributes(
+			attribute.Int("attempt", attempt+1),
+			attribute.String("error", err.Error()),
+		))
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(dbRetryBackoff << attempt):
+		}
+	}
+	return err
+}
+
+func isRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == pgSerializationFailure || pgErr.Code == pgDeadlockDetected
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"awesomeProject1/internal/auth"
+	"awesomeProject1/internal/dto"
+	"awesomeProject1/internal/events"
+	"awesomeProject1/internal/repository"
+	"awesomeProject1/internal/tenant"
+	"context"
+)
+
+type IEventService interface {
+	// History возвращает события журнала после afterSeq
+	History(ctx context.Context, filter *dto.SubscriptionFilter, afterSeq int64, limit int) ([]*dto.Event, *Error)
+	// Late возвращает недавние события с номером не больше afterSeq: их транзакции могли завершиться
+	// позже события afterSeq, и клиент их ещё не получил
+	Late(ctx context.Context, filter *dto.SubscriptionFilter, afterSeq int64) ([]*dto.Event, *Error)
+	// Subscribe подписывает на новые события, подписку нужно закрыть
+	Subscribe(ctx context.Context, filter *dto.SubscriptionFilter) (*events.Subscription, *Error)
+}
+
+type EventService struct {
+	OutboxRepository repository.IOutboxRepository
+	Hub              *events.Hub
+}
+
+func NewEventService(repo repository.IOutboxRepository, hub *events.Hub) *EventService {
+	return &EventService{OutboxRepository: repo, Hub: hub}
+}
+
+func (c *EventService) History(ctx context.Context, filter *dto.SubscriptionFilter, afterSeq int64, limit int) ([]*dto.Event, *Error) {
+	filter, sErr := scopeFilter(ctx, filter)
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	var items []*dto.Event
+	err := withRetry(ctx, func() (err error) {
+		items, err = c.OutboxRepository.FindAfter(ctx, afterSeq, filter, limit)
+		return err
+	})
+
+	if err != nil {
+		return nil, dbError("History", err)
+	}
+
+	return items, nil
+}
+
+func (c *EventService) Late(ctx context.Context, filter *dto.SubscriptionFilter, afterSeq int64) ([]*dto.Event, *Error) {
+	filter, sErr := scopeFilter(ctx, filter)
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	var items []*dto.Event
+	err := withRetry(ctx, func() (err error) {
+		items, err = c.OutboxRepository.FindLate(ctx, afterSeq, events.ReorderWindow, events.ReorderMaxAge, filter)
+		return err
+	})
+
+	if err != nil {
+		return nil, dbError("Late", err)
+	}
+
+	return items, nil
+}
+
+func (c *EventService) Subscribe(ctx context.Context, filter *dto.SubscriptionFilter) (*events.Subscription, *Error) {
+	filter, sErr := scopeFilter(ctx, filter)
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	return c.Hub.Subscribe(tenant.Filter(ctx), filter), nil
+}
+
+// scopeFilter ограничивает фильтр событий пользователем субъекта, если субъект не администратор.
+// Журнал событий не знает о субъектах, поэтому ограничение задаётся через фильтр по user_id
+func scopeFilter(ctx context.Context, filter *dto.SubscriptionFilter) (*dto.SubscriptionFilter, *Error) {
+	owner, sErr := ownerScope(ctx, auth.PermSubscriptionRead)
+	if sErr != nil {
+		return nil, sErr
+	}
+	if sErr := checkOwnerFilter(owner, filter.UserId); sErr != nil {
+		return nil, sErr
+	}
+
+	if owner == nil {
+		return filter, nil
+	}
+
+	scoped := *filter
+	scoped.UserId = owner.String()
+	return &scoped, nil
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"awesomeProject1/internal/auth"
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ErrorUnauthenticated = "Authentication required"
+	ErrorNotOwner        = "Access to subscriptions of another user is forbidden"
+	ErrorNoOwner         = "Credentials are not bound to a user, access to subscriptions requires admin:subscriptions"
+	ErrorPermission      = "Permission %s is required"
+)
+
+// Authorize проверяет, что у субъекта запроса есть разрешение permission.
+// Без субъекта в ctx доступ запрещён: сервис не должен вызываться в обход аутентификации
+func Authorize(ctx context.Context, permission string) (*auth.Principal, *Error) {
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, NewError(KindUnauthorized, ErrorUnauthenticated)
+	}
+	if !principal.Can(permission) {
+		return nil, NewError(KindForbidden, fmt.Sprintf(ErrorPermission, permission))
+	}
+	return principal, nil
+}
+
+// ownerScope проверяет разрешение и возвращает пользователя, которым ограничен субъект, nil — доступ ко всем подпискам
+func ownerScope(ctx context.Context, permission string) (*uuid.UUID, *Error) {
+	principal, sErr := Authorize(ctx, permission)
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	owner := principal.Owner()
+	if owner != nil && *owner == uuid.Nil {
+		return nil, NewError(KindForbidden, ErrorNoOwner)
+	}
+
+	return owner, nil
+}
+
+// checkOwner запрещает субъекту, ограниченному owner, обращаться к подпискам пользователя userId
+func checkOwner(owner *uuid.UUID, userId uuid.UUID) *Error {
+	if owner != nil && *owner != userId {
+		return NewError(KindForbidden, ErrorNotOwner)
+	}
+	return nil
+}
+
+// checkOwnerFilter — checkOwner для фильтра по user_id из запроса, пустой фильтр разрешён
+func checkOwnerFilter(owner *uuid.UUID, userId string) *Error {
+	if owner == nil || userId == "" {
+		return nil
+	}
+
+	id, err := uuid.Parse(userId)
+	if err != nil {
+		return NewError(KindForbidden, ErrorNotOwner)
+	}
+	return checkOwner(owner, id)
+}
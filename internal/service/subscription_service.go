@@ -1,117 +1,392 @@
 package service
 
 import (
+	"awesomeProject1/internal/auth"
 	"awesomeProject1/internal/dto"
 	"awesomeProject1/internal/repository"
-	"awesomeProject1/pkg/httpHelpers"
-	"awesomeProject1/pkg/logger"
+	"awesomeProject1/internal/tenant"
 	"awesomeProject1/pkg/queryBuilder"
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/google/uuid"
-	"net/http"
+	"go.opentelemetry.io/otel/attribute"
+	"time"
 )
 
 type ISubscriptionService interface {
-	Create(cxt context.Context, req *dto.Subscription) (*dto.SubscriptionResponse, *httpHelpers.ServiceError)
-	Delete(cxt context.Context, id uuid.UUID) *httpHelpers.ServiceError
-	Update(cxt context.Context, req *dto.UpdateData) *httpHelpers.ServiceError
-	GetById(ctx context.Context, id uuid.UUID) (*dto.SubscriptionResponse, *httpHelpers.ServiceError)
-	GetTotalSum(ctx context.Context, req *dto.GetTotalSumRequest) (int, *httpHelpers.ServiceError)
-	GetAll(ctx context.Context, offset, limit int) (*dto.SubscriptionListResponse, *httpHelpers.ServiceError)
+	Create(cxt context.Context, req *dto.Subscription) (*dto.SubscriptionResponse, *Error)
+	Delete(cxt context.Context, id uuid.UUID, ifMatch []string) *Error
+	Update(cxt context.Context, req *dto.UpdateData) *Error
+	Patch(ctx context.Context, id uuid.UUID, ifMatch []string, patch dto.PatchFunc) (*dto.SubscriptionResponse, *Error)
+	GetById(ctx context.Context, id uuid.UUID) (*dto.SubscriptionResponse, *Error)
+	GetTotalSum(ctx context.Context, req *dto.GetTotalSumRequest) (int, *Error)
+	GetMonthlyTotals(ctx context.Context, req *dto.GetTotalSumRequest) ([]*dto.MonthlyTotal, *Error)
+	GetTotalsByUserIds(ctx context.Context, req *dto.GetTotalSumRequest, userIds []uuid.UUID) (map[uuid.UUID]int, *Error)
+	GetAll(ctx context.Context, filter *dto.SubscriptionFilter, offset, limit int) (*dto.SubscriptionListResponse, *Error)
+	GetAfter(ctx context.Context, filter *dto.SubscriptionFilter, after *dto.SubscriptionCursor, limit int) ([]*dto.SubscriptionResponse, *Error)
+	GetByIds(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*dto.SubscriptionResponse, *Error)
+	GetByUserIds(ctx context.Context, userIds []uuid.UUID) (map[uuid.UUID][]*dto.SubscriptionResponse, *Error)
 }
 
+// SubscriptionService — операции с подписками. Изменения пишутся вместе с событием в outbox
+// в одной транзакции, поэтому каждое изменение гарантированно доходит до вебхуков.
+// Субъект без прав администратора работает только с подписками своего пользователя
 type SubscriptionService struct {
 	SubscriptionRepository repository.ISubscriptionRepository
+	Transactor             repository.ITransactor
 }
 
-func NewSubscriptionService(repo repository.ISubscriptionRepository) *SubscriptionService {
-	return &SubscriptionService{SubscriptionRepository: repo}
+func NewSubscriptionService(repo repository.ISubscriptionRepository, transactor repository.ITransactor) *SubscriptionService {
+	return &SubscriptionService{SubscriptionRepository: repo, Transactor: transactor}
 }
 
-func (c *SubscriptionService) Create(cxt context.Context, req *dto.Subscription) (*dto.SubscriptionResponse, *httpHelpers.ServiceError) {
-	item, err := c.SubscriptionRepository.Create(cxt, req)
+func (c *SubscriptionService) Create(cxt context.Context, req *dto.Subscription) (_ *dto.SubscriptionResponse, sErr *Error) {
+	cxt, span := startSpan(cxt, "SubscriptionService.Create")
+	defer func() { endSpan(span, sErr) }()
+
+	owner, sErr := ownerScope(cxt, auth.PermSubscriptionWrite)
+	if sErr != nil {
+		return nil, sErr
+	}
+	if sErr := checkOwner(owner, req.UserID); sErr != nil {
+		return nil, sErr
+	}
+
+	var item *dto.Subscription
+	err := withRetry(cxt, func() error {
+		return c.Transactor.InTx(cxt, func(repos *repository.TxRepositories) (err error) {
+			item, err = repos.Subscriptions.Create(cxt, req)
+			if err != nil {
+				return err
+			}
+			return repos.Outbox.Add(cxt, dto.NewSubscriptionEvent(dto.EventSubscriptionCreated, item))
+		})
+	})
 
 	if err != nil {
-		logger.Log.Error("SubscriptionService -> Create -> err -> " + err.Error())
-		return nil, httpHelpers.NewServiceError(http.StatusInternalServerError, httpHelpers.Error500)
+		return nil, dbError("Create", err)
 	}
 
 	return item.ToResponse(), nil
 }
 
-func (c *SubscriptionService) Delete(ctx context.Context, id uuid.UUID) *httpHelpers.ServiceError {
-	ok, err := c.SubscriptionRepository.Delete(ctx, id)
+func (c *SubscriptionService) Delete(ctx context.Context, id uuid.UUID, ifMatch []string) (sErr *Error) {
+	ctx, span := startSpan(ctx, "SubscriptionService.Delete", attribute.String("subscription.id", id.String()))
+	defer func() { endSpan(span, sErr) }()
+
+	if _, sErr := ownerScope(ctx, auth.PermSubscriptionWrite); sErr != nil {
+		return sErr
+	}
+
+	var version *int
+
+	if len(ifMatch) > 0 {
+		item, sErr := c.checkIfMatch(ctx, id, ifMatch)
+		if sErr != nil {
+			return sErr
+		}
+		version = &item.Version
+	}
+
+	var ok bool
+	err := withRetry(ctx, func() error {
+		return c.Transactor.InTx(ctx, func(repos *repository.TxRepositories) error {
+			// Последнее состояние подписки нужно для события об удалении
+			item, found, err := repos.Subscriptions.FindById(ctx, id)
+			if err != nil || !found {
+				ok = false
+				return err
+			}
+
+			ok, err = repos.Subscriptions.Delete(ctx, id, version)
+			if err != nil || !ok {
+				return err
+			}
+			return repos.Outbox.Add(ctx, dto.NewSubscriptionEvent(dto.EventSubscriptionDeleted, item))
+		})
+	})
 
 	if err != nil {
-		logger.Log.Error("SubscriptionService -> Delete -> err -> " + err.Error())
-		return httpHelpers.NewServiceError(http.StatusInternalServerError, httpHelpers.Error500)
+		return dbError("Delete", err)
 	}
 
 	if !ok {
-		return httpHelpers.NewServiceError(http.StatusBadRequest, fmt.Sprintf("Cant delete Subscription by id: %s", id.String()))
+		if version != nil {
+			return NewError(KindPrecondition, ErrorPrecondition)
+		}
+		return NewError(KindNotFound, fmt.Sprintf("Cant delete Subscription by id: %s", id.String()))
 	}
 
 	return nil
 }
 
-func (c *SubscriptionService) GetById(ctx context.Context, id uuid.UUID) (*dto.SubscriptionResponse, *httpHelpers.ServiceError) {
-	item, ok, err := c.SubscriptionRepository.FindById(ctx, id)
+// Purge удаляет подписки по фильтру для административных задач. Событие об удалении
+// пишется на каждую подписку, поэтому вебхуки и поток SSE узнают об удалении как обычно
+func (c *SubscriptionService) Purge(ctx context.Context, filter *dto.SubscriptionFilter, endedBefore *time.Time) (_ int, sErr *Error) {
+	ctx, span := startSpan(ctx, "SubscriptionService.Purge")
+	defer func() { endSpan(span, sErr) }()
+
+	if ok, errors := filter.IsValid(); !ok {
+		return 0, NewValidationError(errors)
+	}
+
+	owner, sErr := ownerScope(ctx, auth.PermSubscriptionWrite)
+	if sErr != nil {
+		return 0, sErr
+	}
+	if sErr := checkOwnerFilter(owner, filter.UserId); sErr != nil {
+		return 0, sErr
+	}
+
+	var count int
+	err := withRetry(ctx, func() error {
+		return c.Transactor.InTx(ctx, func(repos *repository.TxRepositories) error {
+			items, err := repos.Subscriptions.Purge(ctx, filter, endedBefore)
+			if err != nil {
+				return err
+			}
+
+			for _, item := range items {
+				if err := repos.Outbox.Add(ctx, dto.NewSubscriptionEvent(dto.EventSubscriptionDeleted, item)); err != nil {
+					return err
+				}
+			}
+
+			count = len(items)
+			return nil
+		})
+	})
 
 	if err != nil {
-		logger.Log.Error("SubscriptionService -> GetById -> err -> " + err.Error())
-		return nil, httpHelpers.NewServiceError(http.StatusInternalServerError, httpHelpers.Error500)
+		return 0, dbError("Purge", err)
+	}
+
+	return count, nil
+}
+
+func (c *SubscriptionService) GetById(ctx context.Context, id uuid.UUID) (_ *dto.SubscriptionResponse, sErr *Error) {
+	ctx, span := startSpan(ctx, "SubscriptionService.GetById", attribute.String("subscription.id", id.String()))
+	defer func() { endSpan(span, sErr) }()
+
+	if _, sErr := ownerScope(ctx, auth.PermSubscriptionRead); sErr != nil {
+		return nil, sErr
+	}
+
+	item, ok, sErr := c.findById(ctx, "GetById", id)
+	if sErr != nil {
+		return nil, sErr
 	}
 
 	if !ok {
-		return nil, httpHelpers.NewServiceError(http.StatusBadRequest, httpHelpers.ErrorNotFoundById)
+		return nil, NewError(KindNotFound, ErrorNotFoundById)
 	}
 
 	return item.ToResponse(), nil
 }
 
-func (c *SubscriptionService) GetTotalSum(ctx context.Context, req *dto.GetTotalSumRequest) (int, *httpHelpers.ServiceError) {
-	sum, err := c.SubscriptionRepository.GetTotal(ctx, req.Start, req.End, req.ServiceName, req.UserId)
+func (c *SubscriptionService) GetTotalSum(ctx context.Context, req *dto.GetTotalSumRequest) (_ int, sErr *Error) {
+	ctx, span := startSpan(ctx, "SubscriptionService.GetTotalSum", totalAttributes(req)...)
+	defer func() { endSpan(span, sErr) }()
+
+	if sErr := checkTotalScope(ctx, req.UserId); sErr != nil {
+		return 0, sErr
+	}
+
+	var sum int
+	err := withRetry(ctx, func() (err error) {
+		sum, err = c.SubscriptionRepository.GetTotal(ctx, req.Start, req.End, req.ServiceName, req.UserId)
+		return err
+	})
 
 	if err != nil {
-		logger.Log.Error("SubscriptionService -> GetTotalSum -> err -> ", err.Error())
-		return 0, httpHelpers.NewServiceError(500, httpHelpers.Error500)
+		return 0, dbError("GetTotalSum", err)
 	}
 
 	return sum, nil
 }
 
-func (c *SubscriptionService) Update(cxt context.Context, req *dto.UpdateData) *httpHelpers.ServiceError {
+// GetMonthlyTotals возвращает сумму подписок за период с разбивкой по месяцам
+func (c *SubscriptionService) GetMonthlyTotals(ctx context.Context, req *dto.GetTotalSumRequest) (_ []*dto.MonthlyTotal, sErr *Error) {
+	ctx, span := startSpan(ctx, "SubscriptionService.GetMonthlyTotals", totalAttributes(req)...)
+	defer func() { endSpan(span, sErr) }()
+
+	if sErr := checkTotalScope(ctx, req.UserId); sErr != nil {
+		return nil, sErr
+	}
+
+	var totals []*dto.MonthlyTotal
+	err := withRetry(ctx, func() (err error) {
+		totals, err = c.SubscriptionRepository.GetMonthlyTotals(ctx, req.Start, req.End, req.ServiceName, req.UserId)
+		return err
+	})
+
+	if err != nil {
+		return nil, dbError("GetMonthlyTotals", err)
+	}
+
+	return totals, nil
+}
+
+// GetTotalsByUserIds считает суммарную стоимость подписок за период для нескольких пользователей сразу.
+// Фильтр по пользователю из req не учитывается
+func (c *SubscriptionService) GetTotalsByUserIds(ctx context.Context, req *dto.GetTotalSumRequest, userIds []uuid.UUID) (_ map[uuid.UUID]int, sErr *Error) {
+	ctx, span := startSpan(ctx, "SubscriptionService.GetTotalsByUserIds", attribute.Int("users.count", len(userIds)))
+	defer func() { endSpan(span, sErr) }()
+
+	if sErr := checkTotalScope(ctx, ""); sErr != nil {
+		return nil, sErr
+	}
+
+	var totals map[uuid.UUID]int
+	err := withRetry(ctx, func() (err error) {
+		totals, err = c.SubscriptionRepository.GetTotalsByUserIds(ctx, req.Start, req.End, req.ServiceName, userIds)
+		return err
+	})
+
+	if err != nil {
+		return nil, dbError("GetTotalsByUserIds", err)
+	}
+
+	return totals, nil
+}
+
+func (c *SubscriptionService) Update(cxt context.Context, req *dto.UpdateData) (sErr *Error) {
+	cxt, span := startSpan(cxt, "SubscriptionService.Update", attribute.String("subscription.id", req.ID.String()))
+	defer func() { endSpan(span, sErr) }()
+
+	owner, sErr := ownerScope(cxt, auth.PermSubscriptionWrite)
+	if sErr != nil {
+		return sErr
+	}
+	if req.UserID != nil {
+		if sErr := checkOwner(owner, *req.UserID); sErr != nil {
+			return sErr
+		}
+	}
+
+	if len(req.IfMatch) > 0 {
+		item, sErr := c.checkIfMatch(cxt, req.ID, req.IfMatch)
+		if sErr != nil {
+			return sErr
+		}
+		req.Version = &item.Version
+	}
+
 	qb := queryBuilder.NewQueryBuilder(true).
 		Set("user_id", req.UserID).
 		Set("price", req.Price).
 		Set("service_name", req.ServiceName).
 		Set("start_date", req.StartDate).
-		Set("end_date", req.EndDate)
+		Set("end_date", req.EndDate).
+		Increment("version").
+		Where("version", req.Version).
+		Where("user_id", owner).
+		Where("tenant_id", tenant.Filter(cxt))
 
 	query, values := qb.BuildUpdateQuery("public.Subscriptions", "id", req.ID)
-	ok, err := c.SubscriptionRepository.Update(cxt, query, values)
+	var ok bool
+	err := withRetry(cxt, func() error {
+		return c.Transactor.InTx(cxt, func(repos *repository.TxRepositories) (err error) {
+			ok, err = repos.Subscriptions.Update(cxt, query, values)
+			if err != nil || !ok {
+				return err
+			}
 
-	if err != nil {
-		logger.Log.Error("SubscriptionService -> Update -> err -> " + err.Error())
+			item, _, err := repos.Subscriptions.FindById(cxt, req.ID)
+			if err != nil {
+				return err
+			}
+			return repos.Outbox.Add(cxt, dto.NewSubscriptionEvent(dto.EventSubscriptionUpdated, item))
+		})
+	})
 
-		return httpHelpers.NewServiceError(http.StatusInternalServerError, httpHelpers.Error500)
+	if err != nil {
+		return dbError("Update", err)
 	}
 
 	if !ok {
-		logger.Log.Error(fmt.Sprintf("SubscriptionService -> Update -> err -> "+"Cant update Subscription item id: %d", req.ID))
-		return httpHelpers.NewServiceError(http.StatusBadRequest, httpHelpers.ErrorNotFoundById)
+		if req.Version != nil {
+			return NewError(KindPrecondition, ErrorPrecondition)
+		}
+		return NewError(KindNotFound, ErrorNotFoundById)
 	}
 
 	return nil
 }
 
-func (s *SubscriptionService) GetAll(ctx context.Context, offset, limit int) (*dto.SubscriptionListResponse, *httpHelpers.ServiceError) {
-	items, total, err := s.SubscriptionRepository.FindAll(ctx, offset, limit)
+// Patch применяет патч к текущему состоянию подписки, заново валидирует результат и сохраняет его целиком
+func (c *SubscriptionService) Patch(ctx context.Context, id uuid.UUID, ifMatch []string, patch dto.PatchFunc) (_ *dto.SubscriptionResponse, sErr *Error) {
+	ctx, span := startSpan(ctx, "SubscriptionService.Patch", attribute.String("subscription.id", id.String()))
+	defer func() { endSpan(span, sErr) }()
 
+	if _, sErr := ownerScope(ctx, auth.PermSubscriptionWrite); sErr != nil {
+		return nil, sErr
+	}
+
+	item, sErr := c.checkIfMatch(ctx, id, ifMatch)
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	current, err := json.Marshal(dto.NewSubscriptionPatchDocument(item))
+	if err != nil {
+		return nil, internalError("Patch", err)
+	}
+
+	patched, err := patch(current)
+	if err != nil {
+		return nil, &Error{Kind: KindInvalid, Message: fmt.Sprintf("Cant apply patch: %s", err.Error()), Err: err}
+	}
+
+	doc := dto.SubscriptionPatchDocument{}
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, &Error{Kind: KindInvalid, Message: fmt.Sprintf("Patched subscription is invalid: %s", err.Error()), Err: err}
+	}
+
+	if ok, errors := doc.IsValid(); !ok {
+		return nil, NewValidationError(errors)
+	}
+
+	updateData, err := doc.ToUpdateData(id)
 	if err != nil {
-		logger.Log.Error(err.Error())
-		return nil, httpHelpers.NewServiceError(http.StatusInternalServerError, "Ошибка при получении подписок")
+		return nil, internalError("Patch", err)
+	}
+
+	// Патч применялся к прочитанной версии, поэтому обновляем только её
+	updateData.Version = &item.Version
+
+	if sErr := c.Update(ctx, updateData); sErr != nil {
+		return nil, sErr
+	}
+
+	return c.GetById(ctx, id)
+}
+
+func (s *SubscriptionService) GetAll(ctx context.Context, filter *dto.SubscriptionFilter, offset, limit int) (_ *dto.SubscriptionListResponse, sErr *Error) {
+	ctx, span := startSpan(ctx, "SubscriptionService.GetAll", attribute.Int("offset", offset), attribute.Int("limit", limit))
+	defer func() { endSpan(span, sErr) }()
+
+	owner, sErr := ownerScope(ctx, auth.PermSubscriptionRead)
+	if sErr != nil {
+		return nil, sErr
+	}
+	if sErr := checkOwnerFilter(owner, filter.UserId); sErr != nil {
+		return nil, sErr
+	}
+
+	var items []*dto.Subscription
+	var total int
+	err := withRetry(ctx, func() (err error) {
+		items, total, err = s.SubscriptionRepository.FindAll(ctx, filter, offset, limit)
+		return err
+	})
+
+	if err != nil {
+		return nil, dbError("GetAll", err)
 	}
 
 	responses := make([]*dto.SubscriptionResponse, len(items))
@@ -126,3 +401,155 @@ func (s *SubscriptionService) GetAll(ctx context.Context, offset, limit int) (*d
 		Subscriptions: responses,
 	}, nil
 }
+
+// GetAfter возвращает страницу подписок после курсора after для полного обхода списка, например при выгрузке
+func (s *SubscriptionService) GetAfter(ctx context.Context, filter *dto.SubscriptionFilter, after *dto.SubscriptionCursor, limit int) (_ []*dto.SubscriptionResponse, sErr *Error) {
+	ctx, span := startSpan(ctx, "SubscriptionService.GetAfter", attribute.Int("limit", limit))
+	defer func() { endSpan(span, sErr) }()
+
+	owner, sErr := ownerScope(ctx, auth.PermSubscriptionRead)
+	if sErr != nil {
+		return nil, sErr
+	}
+	if sErr := checkOwnerFilter(owner, filter.UserId); sErr != nil {
+		return nil, sErr
+	}
+
+	var items []*dto.Subscription
+	err := withRetry(ctx, func() (err error) {
+		items, err = s.SubscriptionRepository.FindAfter(ctx, filter, after, limit)
+		return err
+	})
+
+	if err != nil {
+		return nil, dbError("GetAfter", err)
+	}
+
+	responses := make([]*dto.SubscriptionResponse, len(items))
+	for i, sub := range items {
+		responses[i] = sub.ToResponse()
+	}
+
+	return responses, nil
+}
+
+// GetByIds загружает несколько подписок одним запросом, ненайденные id в результат не попадают
+func (c *SubscriptionService) GetByIds(ctx context.Context, ids []uuid.UUID) (_ map[uuid.UUID]*dto.SubscriptionResponse, sErr *Error) {
+	ctx, span := startSpan(ctx, "SubscriptionService.GetByIds", attribute.Int("subscriptions.count", len(ids)))
+	defer func() { endSpan(span, sErr) }()
+
+	if _, sErr := ownerScope(ctx, auth.PermSubscriptionRead); sErr != nil {
+		return nil, sErr
+	}
+
+	var items []*dto.Subscription
+	err := withRetry(ctx, func() (err error) {
+		items, err = c.SubscriptionRepository.FindByIds(ctx, ids)
+		return err
+	})
+
+	if err != nil {
+		return nil, dbError("GetByIds", err)
+	}
+
+	result := make(map[uuid.UUID]*dto.SubscriptionResponse, len(items))
+	for _, item := range items {
+		result[item.ID] = item.ToResponse()
+	}
+
+	return result, nil
+}
+
+// GetByUserIds загружает подписки нескольких пользователей одним запросом и группирует их по user_id
+func (c *SubscriptionService) GetByUserIds(ctx context.Context, userIds []uuid.UUID) (_ map[uuid.UUID][]*dto.SubscriptionResponse, sErr *Error) {
+	ctx, span := startSpan(ctx, "SubscriptionService.GetByUserIds", attribute.Int("users.count", len(userIds)))
+	defer func() { endSpan(span, sErr) }()
+
+	if _, sErr := ownerScope(ctx, auth.PermSubscriptionRead); sErr != nil {
+		return nil, sErr
+	}
+
+	var items []*dto.Subscription
+	err := withRetry(ctx, func() (err error) {
+		items, err = c.SubscriptionRepository.FindByUserIds(ctx, userIds)
+		return err
+	})
+
+	if err != nil {
+		return nil, dbError("GetByUserIds", err)
+	}
+
+	result := make(map[uuid.UUID][]*dto.SubscriptionResponse, len(userIds))
+	for _, item := range items {
+		result[item.UserID] = append(result[item.UserID], item.ToResponse())
+	}
+
+	return result, nil
+}
+
+// checkIfMatch загружает подписку и сверяет её ETag со списком из If-Match.
+// Пустой список пропускает проверку, но подписка всё равно должна существовать
+func (c *SubscriptionService) checkIfMatch(ctx context.Context, id uuid.UUID, ifMatch []string) (*dto.Subscription, *Error) {
+	item, ok, sErr := c.findById(ctx, "checkIfMatch", id)
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	if !ok {
+		return nil, NewError(KindNotFound, ErrorNotFoundById)
+	}
+
+	if len(ifMatch) > 0 && !matchETag(ifMatch, item.ETag()) {
+		return nil, NewError(KindPrecondition, ErrorPrecondition)
+	}
+
+	return item, nil
+}
+
+func (c *SubscriptionService) findById(ctx context.Context, op string, id uuid.UUID) (*dto.Subscription, bool, *Error) {
+	var item *dto.Subscription
+	var ok bool
+	err := withRetry(ctx, func() (err error) {
+		item, ok, err = c.SubscriptionRepository.FindById(ctx, id)
+		return err
+	})
+
+	if err != nil {
+		return nil, false, dbError(op, err)
+	}
+
+	return item, ok, nil
+}
+
+// matchETag строго сравнивает ETag записи со списком из If-Match, "*" совпадает с любой версией
+func matchETag(tags []string, etag string) bool {
+	for _, tag := range tags {
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// checkTotalScope разрешает суммы по всем пользователям с reports:read, остальным — только по своим подпискам
+// с subscription:read. Без фильтра userId сумма ограничивается подписками субъекта в репозитории
+func checkTotalScope(ctx context.Context, userId string) *Error {
+	if principal, ok := auth.FromContext(ctx); ok && principal.Can(auth.PermReportsRead) {
+		return nil
+	}
+
+	owner, sErr := ownerScope(ctx, auth.PermSubscriptionRead)
+	if sErr != nil {
+		return sErr
+	}
+	return checkOwnerFilter(owner, userId)
+}
+
+func totalAttributes(req *dto.GetTotalSumRequest) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("period.start", dto.FormatMonthYear(req.Start)),
+		attribute.String("period.end", dto.FormatMonthYear(req.End)),
+		attribute.Bool("filter.user_id", req.UserId != ""),
+		attribute.Bool("filter.service_name", req.ServiceName != ""),
+	}
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"awesomeProject1/internal/auth"
+	"awesomeProject1/internal/dto"
+	"awesomeProject1/internal/repository"
+	"awesomeProject1/internal/tenant"
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ErrorTenantNotFound = "Tenant not found, please check the provided id"
+	ErrorTenantExists   = "Tenant with this name already exists"
+	ErrorTenantInvalid  = "Header " + tenant.Header + " must be a tenant uuid"
+	ErrorTenantMismatch = "Credentials are bound to another tenant than requested in " + tenant.Header
+	ErrorTenantPlatform = "Tenant management requires credentials that are not bound to a tenant"
+)
+
+type ITenantService interface {
+	Create(ctx context.Context, req *dto.CreateTenantRequest) (*dto.TenantResponse, *Error)
+	GetById(ctx context.Context, id uuid.UUID) (*dto.TenantResponse, *Error)
+	GetAll(ctx context.Context, offset, limit int) (*dto.TenantListResponse, *Error)
+	// Resolve определяет тенанта запроса по субъекту из ctx и значению заголовка X-Tenant-ID
+	Resolve(ctx context.Context, requested string) (uuid.UUID, *Error)
+}
+
+type TenantService struct {
+	TenantRepository repository.ITenantRepository
+}
+
+func NewTenantService(repo repository.ITenantRepository) *TenantService {
+	return &TenantService{TenantRepository: repo}
+}
+
+// Create заводит тенанта. Тенантами управляют только субъекты платформы: ключ или токен тенанта
+// не должен видеть, какие ещё компании обслуживает сервис
+func (c *TenantService) Create(ctx context.Context, req *dto.CreateTenantRequest) (*dto.TenantResponse, *Error) {
+	if sErr := authorizePlatform(ctx); sErr != nil {
+		return nil, sErr
+	}
+
+	var item *dto.Tenant
+	err := withRetry(ctx, func() (err error) {
+		item, err = c.TenantRepository.Create(ctx, req.ToTenant())
+		return err
+	})
+
+	if err != nil {
+		sErr := dbError("CreateTenant", err)
+		if sErr.Kind == KindConflict {
+			sErr.Message = ErrorTenantExists
+		}
+		return nil, sErr
+	}
+
+	return item.ToResponse(), nil
+}
+
+func (c *TenantService) GetById(ctx context.Context, id uuid.UUID) (*dto.TenantResponse, *Error) {
+	if sErr := authorizePlatform(ctx); sErr != nil {
+		return nil, sErr
+	}
+
+	item, sErr := c.find(ctx, id)
+	if sErr != nil {
+		return nil, sErr
+	}
+
+	return item.ToResponse(), nil
+}
+
+func (c *TenantService) GetAll(ctx context.Context, offset, limit int) (*dto.TenantListResponse, *Error) {
+	if sErr := authorizePlatform(ctx); sErr != nil {
+		return nil, sErr
+	}
+
+	var items []*dto.Tenant
+	var total int
+	err := withRetry(ctx, func() (err error) {
+		items, total, err = c.TenantRepository.FindAll(ctx, offset, limit)
+		return err
+	})
+
+	if err != nil {
+		return nil, dbError("GetTenants", err)
+	}
+
+	responses := make([]*dto.TenantResponse, len(items))
+	for i, item := range items {
+		responses[i] = item.ToResponse()
+	}
+
+	return &dto.TenantListResponse{
+		Total:   total,
+		Offset:  offset,
+		Limit:   limit,
+		Tenants: responses,
+	}, nil
+}
+
+// Resolve возвращает тенанта субъекта. Субъект платформы выбирает тенанта заголовком, для этого нужно
+// разрешение admin:tenants, без заголовка запрос идёт к тенанту по умолчанию, как до разделения на тенантов
+func (c *TenantService) Resolve(ctx context.Context, requested string) (uuid.UUID, *Error) {
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		return uuid.Nil, NewError(KindUnauthorized, ErrorUnauthenticated)
+	}
+
+	var requestedId uuid.UUID
+	if requested != "" {
+		id, err := uuid.Parse(requested)
+		if err != nil {
+			return uuid.Nil, NewError(KindInvalid, ErrorTenantInvalid)
+		}
+		requestedId = id
+	}
+
+	if principal.TenantID != nil {
+		if requested != "" && requestedId != *principal.TenantID {
+			return uuid.Nil, NewError(KindForbidden, ErrorTenantMismatch)
+		}
+		return *principal.TenantID, nil
+	}
+
+	if requested == "" {
+		return tenant.Default, nil
+	}
+
+	if !principal.Can(auth.PermAdminTenants) {
+		return uuid.Nil, NewError(KindForbidden, fmt.Sprintf(ErrorPermission, auth.PermAdminTenants))
+	}
+
+	if _, sErr := c.find(ctx, requestedId); sErr != nil {
+		return uuid.Nil, sErr
+	}
+
+	return requestedId, nil
+}
+
+func (c *TenantService) find(ctx context.Context, id uuid.UUID) (*dto.Tenant, *Error) {
+	var item *dto.Tenant
+	var ok bool
+	err := withRetry(ctx, func() (err error) {
+		item, ok, err = c.TenantRepository.FindById(ctx, id)
+		return err
+	})
+
+	if err != nil {
+		return nil, dbError("GetTenant", err)
+	}
+
+	if !ok {
+		return nil, NewError(KindNotFound, ErrorTenantNotFound)
+	}
+
+	return item, nil
+}
+
+// authorizePlatform пропускает субъектов с admin:tenants, не привязанных к тенанту
+func authorizePlatform(ctx context.Context) *Error {
+	principal, sErr := Authorize(ctx, auth.PermAdminTenants)
+	if sErr != nil {
+		return sErr
+	}
+	if principal.TenantID != nil {
+		return NewError(KindForbidden, ErrorTenantPlatform)
+	}
+	return nil
+}
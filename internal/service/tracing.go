@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("awesomeProject1/internal/service")
+
+var errorKindNames = map[ErrorKind]string{
+	KindInternal:      "internal",
+	KindInvalid:       "invalid",
+	KindNotFound:      "not_found",
+	KindConflict:      "conflict",
+	KindPrecondition:  "precondition",
+	KindUnprocessable: "unprocessable",
+	KindForbidden:     "forbidden",
+	KindUnauthorized:  "unauthorized",
+}
+
+// startSpan открывает span метода сервиса, закрывается через endSpan
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan закрывает span. Ошибкой span отмечаются только внутренние ошибки,
+// ответы вида «не найдено» или «неверный запрос» — обычная работа сервиса
+func endSpan(span trace.Span, sErr *Error) {
+	if sErr != nil {
+		span.SetAttributes(attribute.String("error.kind", errorKindNames[sErr.Kind]))
+		if sErr.Kind == KindInternal {
+			span.RecordError(sErr)
+			span.SetStatus(codes.Error, sErr.Message)
+		}
+	}
+	span.End()
+}
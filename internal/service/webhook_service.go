@@ -0,0 +1,242 @@
+package service
+
+import (
+	"awesomeProject1/internal/auth"
+	"awesomeProject1/internal/dto"
+	"awesomeProject1/internal/repository"
+	"awesomeProject1/internal/tenant"
+	"awesomeProject1/pkg/queryBuilder"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/google/uuid"
+)
+
+const (
+	ErrorWebhookNotFound  = "Webhook not found, please check the provided id"
+	ErrorDeliveryNotFound = "Dead delivery not found, only deliveries with status dead can be retried"
+
+	generatedSecretBytes = 32
+)
+
+type IWebhookService interface {
+	Create(ctx context.Context, req *dto.CreateWebhookRequest) (*dto.WebhookResponse, *Error)
+	Update(ctx context.Context, id uuid.UUID, req *dto.UpdateWebhookRequest) (*dto.WebhookResponse, *Error)
+	Delete(ctx context.Context, id uuid.UUID) *Error
+	GetById(ctx context.Context, id uuid.UUID) (*dto.WebhookResponse, *Error)
+	GetAll(ctx context.Context, offset, limit int) (*dto.WebhookListResponse, *Error)
+	GetDeliveries(ctx context.Context, id uuid.UUID, status dto.DeliveryStatus, offset, limit int) (*dto.WebhookDeliveryListResponse, *Error)
+	RetryDelivery(ctx context.Context, id, deliveryId uuid.UUID) *Error
+}
+
+type WebhookService struct {
+	WebhookRepository repository.IWebhookRepository
+}
+
+func NewWebhookService(repo repository.IWebhookRepository) *WebhookService {
+	return &WebhookService{WebhookRepository: repo}
+}
+
+// Create регистрирует вебхук. Секрет отдаётся в ответе только здесь, дальше его не получить
+func (c *WebhookService) Create(ctx context.Context, req *dto.CreateWebhookRequest) (*dto.WebhookResponse, *Error) {
+	if _, sErr := Authorize(ctx, auth.PermAdminWebhooks); sErr != nil {
+		return nil, sErr
+	}
+
+	webhook := req.ToWebhook()
+	if webhook.Secret == "" {
+		secret, err := generateSecret()
+		if err != nil {
+			return nil, internalError("CreateWebhook", err)
+		}
+		webhook.Secret = secret
+	}
+
+	var item *dto.Webhook
+	err := withRetry(ctx, func() (err error) {
+		item, err = c.WebhookRepository.Create(ctx, webhook)
+		return err
+	})
+
+	if err != nil {
+		return nil, dbError("CreateWebhook", err)
+	}
+
+	response := item.ToResponse()
+	response.Secret = item.Secret
+	return response, nil
+}
+
+func (c *WebhookService) Update(ctx context.Context, id uuid.UUID, req *dto.UpdateWebhookRequest) (*dto.WebhookResponse, *Error) {
+	if _, sErr := Authorize(ctx, auth.PermAdminWebhooks); sErr != nil {
+		return nil, sErr
+	}
+
+	query, values := queryBuilder.NewQueryBuilder(true).
+		Set("url", req.URL).
+		Set("secret", req.Secret).
+		Set("event_types", req.EventTypes).
+		Set("active", req.Active).
+		Where("tenant_id", tenant.Filter(ctx)).
+		BuildUpdateQuery("webhooks", "id", id)
+
+	// Пустой запрос ничего не меняет, просто возвращаем текущее состояние
+	if query == "" {
+		return c.GetById(ctx, id)
+	}
+
+	var ok bool
+	err := withRetry(ctx, func() (err error) {
+		ok, err = c.WebhookRepository.Update(ctx, query, values)
+		return err
+	})
+
+	if err != nil {
+		return nil, dbError("UpdateWebhook", err)
+	}
+
+	if !ok {
+		return nil, NewError(KindNotFound, ErrorWebhookNotFound)
+	}
+
+	return c.GetById(ctx, id)
+}
+
+func (c *WebhookService) Delete(ctx context.Context, id uuid.UUID) *Error {
+	if _, sErr := Authorize(ctx, auth.PermAdminWebhooks); sErr != nil {
+		return sErr
+	}
+
+	var ok bool
+	err := withRetry(ctx, func() (err error) {
+		ok, err = c.WebhookRepository.Delete(ctx, id)
+		return err
+	})
+
+	if err != nil {
+		return dbError("DeleteWebhook", err)
+	}
+
+	if !ok {
+		return NewError(KindNotFound, ErrorWebhookNotFound)
+	}
+
+	return nil
+}
+
+func (c *WebhookService) GetById(ctx context.Context, id uuid.UUID) (*dto.WebhookResponse, *Error) {
+	if _, sErr := Authorize(ctx, auth.PermAdminWebhooks); sErr != nil {
+		return nil, sErr
+	}
+
+	var item *dto.Webhook
+	var ok bool
+	err := withRetry(ctx, func() (err error) {
+		item, ok, err = c.WebhookRepository.FindById(ctx, id)
+		return err
+	})
+
+	if err != nil {
+		return nil, dbError("GetWebhook", err)
+	}
+
+	if !ok {
+		return nil, NewError(KindNotFound, ErrorWebhookNotFound)
+	}
+
+	return item.ToResponse(), nil
+}
+
+func (c *WebhookService) GetAll(ctx context.Context, offset, limit int) (*dto.WebhookListResponse, *Error) {
+	if _, sErr := Authorize(ctx, auth.PermAdminWebhooks); sErr != nil {
+		return nil, sErr
+	}
+
+	var items []*dto.Webhook
+	var total int
+	err := withRetry(ctx, func() (err error) {
+		items, total, err = c.WebhookRepository.FindAll(ctx, offset, limit)
+		return err
+	})
+
+	if err != nil {
+		return nil, dbError("GetWebhooks", err)
+	}
+
+	responses := make([]*dto.WebhookResponse, len(items))
+	for i, item := range items {
+		responses[i] = item.ToResponse()
+	}
+
+	return &dto.WebhookListResponse{
+		Total:    total,
+		Offset:   offset,
+		Limit:    limit,
+		Webhooks: responses,
+	}, nil
+}
+
+// GetDeliveries возвращает журнал доставок вебхука
+func (c *WebhookService) GetDeliveries(ctx context.Context, id uuid.UUID, status dto.DeliveryStatus, offset, limit int) (*dto.WebhookDeliveryListResponse, *Error) {
+	if _, sErr := Authorize(ctx, auth.PermAdminWebhooks); sErr != nil {
+		return nil, sErr
+	}
+
+	if _, sErr := c.GetById(ctx, id); sErr != nil {
+		return nil, sErr
+	}
+
+	var items []*dto.WebhookDelivery
+	var total int
+	err := withRetry(ctx, func() (err error) {
+		items, total, err = c.WebhookRepository.FindDeliveries(ctx, id, status, offset, limit)
+		return err
+	})
+
+	if err != nil {
+		return nil, dbError("GetDeliveries", err)
+	}
+
+	responses := make([]*dto.WebhookDeliveryResponse, len(items))
+	for i, item := range items {
+		responses[i] = item.ToResponse()
+	}
+
+	return &dto.WebhookDeliveryListResponse{
+		Total:      total,
+		Offset:     offset,
+		Limit:      limit,
+		Deliveries: responses,
+	}, nil
+}
+
+// RetryDelivery перезапускает доставку из dead-letter
+func (c *WebhookService) RetryDelivery(ctx context.Context, id, deliveryId uuid.UUID) *Error {
+	if _, sErr := Authorize(ctx, auth.PermAdminWebhooks); sErr != nil {
+		return sErr
+	}
+
+	var ok bool
+	err := withRetry(ctx, func() (err error) {
+		ok, err = c.WebhookRepository.RetryDelivery(ctx, id, deliveryId)
+		return err
+	})
+
+	if err != nil {
+		return dbError("RetryDelivery", err)
+	}
+
+	if !ok {
+		return NewError(KindNotFound, ErrorDeliveryNotFound)
+	}
+
+	return nil
+}
+
+func generateSecret() (string, error) {
+	buf := make([]byte, generatedSecretBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -1,21 +1,44 @@
 package store
 
 import (
+	"awesomeProject1/pkg/validator"
+	"errors"
+	"os"
+
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 )
 
 type Config struct {
-	DbConnString     string `yaml:"db_conn_string"`
-	DbMigrationsUrl  string `yaml:"db_migrations_url"`
+	DbConnString     string `yaml:"db_conn_string" secret:"true"`
+	DbMigrationsUrl  string `yaml:"db_migrations_url" secret:"true"`
 	DbMigrationsPath string `yaml:"db_migrations_path"`
+	// DbRole — роль, на которую переключаются соединения сервиса (SET ROLE). Нужна, если пользователь
+	// из db_conn_string — суперпользователь: на него не действуют политики разделения тенантов
+	DbRole string `yaml:"db_role"`
 }
 
 func NewConfig() *Config {
 	return &Config{}
 }
 
+// Validate проверяет значения, имена полей — ключи секции storage
+func (c *Config) Validate() []validator.FieldError {
+	v := validator.New()
+	if c.DbConnString == "" {
+		v.AddError("db_conn_string", validator.CodeRequired, "is required, set it in the file or APP_STORAGE_DB_CONN_STRING")
+	}
+	if c.DbMigrationsUrl == "" {
+		v.AddError("db_migrations_url", validator.CodeRequired, "is required, set it in the file or APP_STORAGE_DB_MIGRATIONS_URL")
+	}
+	if c.DbMigrationsPath == "" {
+		v.AddError("db_migrations_path", validator.CodeRequired, "is required")
+	}
+	return v.GetErrors()
+}
+
 func (c *Config) RunMigrations() error {
 	m, err := migrate.New(
 		c.DbMigrationsPath,
@@ -31,3 +54,67 @@ func (c *Config) RunMigrations() error {
 
 	return nil
 }
+
+// RollbackMigrations откатывает steps последних миграций
+func (c *Config) RollbackMigrations(steps int) error {
+	m, err := migrate.New(
+		c.DbMigrationsPath,
+		c.DbMigrationsUrl,
+	)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err := m.Steps(-steps); err != nil && err != migrate.ErrNoChange {
+		return err
+	}
+
+	return nil
+}
+
+// MigrationVersion возвращает номер применённой миграции и признак dirty — миграция упала на середине.
+// Если миграций ещё не было, возвращает 0
+func (c *Config) MigrationVersion() (uint, bool, error) {
+	m, err := migrate.New(
+		c.DbMigrationsPath,
+		c.DbMigrationsUrl,
+	)
+	if err != nil {
+		return 0, false, err
+	}
+	defer m.Close()
+
+	version, dirty, err := m.Version()
+	if err == migrate.ErrNilVersion {
+		return 0, false, nil
+	}
+
+	return version, dirty, err
+}
+
+// LatestMigration возвращает номер последней миграции в DbMigrationsPath — версию, до которой
+// RunMigrations доводит схему
+func (c *Config) LatestMigration() (uint, error) {
+	driver, err := source.Open(c.DbMigrationsPath)
+	if err != nil {
+		return 0, err
+	}
+	defer driver.Close()
+
+	version, err := driver.First()
+	if err != nil {
+		return 0, err
+	}
+
+	for {
+		next, err := driver.Next(version)
+		if errors.Is(err, os.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		version = next
+	}
+}
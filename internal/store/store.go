@@ -2,14 +2,31 @@ package store
 
 import (
 	"awesomeProject1/internal/repository"
+	"awesomeProject1/internal/tenant"
 	"context"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"sync"
 )
 
 type Store struct {
-	config                 *Config
-	db                     *pgxpool.Pool
-	subscriptionRepository *repository.SubscriptionRepository
+	config                  *Config
+	db                      *pgxpool.Pool
+	queryTracers            []pgx.QueryTracer
+	subscriptionRepository  *repository.SubscriptionRepository
+	idempotencyRepository   *repository.IdempotencyRepository
+	webhookRepository       *repository.WebhookRepository
+	outboxRepository        *repository.OutboxRepository
+	transactor              *repository.Transactor
+	eventListener           *repository.EventListener
+	calendarTokenRepository *repository.CalendarTokenRepository
+	healthRepository        *repository.HealthRepository
+	apiKeyRepository        *repository.APIKeyRepository
+	rateLimitRepository     *repository.RateLimitRepository
+	tenantRepository        *repository.TenantRepository
+
+	// tenantSettings — значение app.tenant_id на каждом соединении пула, чтобы не выставлять его повторно
+	tenantSettings sync.Map
 }
 
 func New(config *Config) *Store {
@@ -18,8 +35,24 @@ func New(config *Config) *Store {
 	}
 }
 
+// AddQueryTracer подключает наблюдение за запросами (метрики, трассировка), вызывается до Start
+func (s *Store) AddQueryTracer(tracer pgx.QueryTracer) {
+	s.queryTracers = append(s.queryTracers, tracer)
+}
+
 func (s *Store) Start() error {
-	db, err := pgxpool.New(context.Background(), s.config.DbConnString)
+	poolConfig, err := pgxpool.ParseConfig(s.config.DbConnString)
+	if err != nil {
+		return err
+	}
+	if len(s.queryTracers) > 0 {
+		poolConfig.ConnConfig.Tracer = multiTracer(s.queryTracers)
+	}
+	poolConfig.AfterConnect = s.setRole
+	poolConfig.PrepareConn = s.setTenant
+	poolConfig.BeforeClose = func(conn *pgx.Conn) { s.tenantSettings.Delete(conn) }
+
+	db, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
 	if err != nil {
 		return err
 	}
@@ -34,13 +67,121 @@ func (s *Store) Start() error {
 	return nil
 }
 
+// setRole переключает новое соединение на storage.db_role, чтобы на запросы действовали политики RLS
+func (s *Store) setRole(ctx context.Context, conn *pgx.Conn) error {
+	if s.config.DbRole == "" {
+		return nil
+	}
+	_, err := conn.Exec(ctx, "SET ROLE "+pgx.Identifier{s.config.DbRole}.Sanitize())
+	return err
+}
+
+// setTenant выставляет на соединении app.tenant_id тенанта из ctx перед каждым запросом или транзакцией.
+// Политики RLS читают его и скрывают строки других тенантов, даже если в запросе нет условия по tenant_id
+func (s *Store) setTenant(ctx context.Context, conn *pgx.Conn) (bool, error) {
+	setting := tenant.Setting(ctx)
+	if current, ok := s.tenantSettings.Load(conn); ok && current == setting {
+		return true, nil
+	}
+
+	if _, err := conn.Exec(ctx, "SELECT set_config('app.tenant_id', $1, false)", setting); err != nil {
+		return false, err
+	}
+	s.tenantSettings.Store(conn, setting)
+	return true, nil
+}
+
+// RowSecurityEnforced сообщает, действуют ли политики RLS на соединения сервиса.
+// Суперпользователь и роли с BYPASSRLS их обходят, тогда тенантов разделяют только условия в запросах
+func (s *Store) RowSecurityEnforced(ctx context.Context) (bool, error) {
+	var bypass bool
+	err := s.db.QueryRow(ctx, "SELECT rolsuper OR rolbypassrls FROM pg_roles WHERE rolname = current_user").Scan(&bypass)
+	return !bypass, err
+}
+
 func (s *Store) Stop() {
 	s.db.Close()
 }
 
+// Stat — статистика пула соединений
+func (s *Store) Stat() *pgxpool.Stat {
+	return s.db.Stat()
+}
+
 func (s *Store) SubscriptionRepository() *repository.SubscriptionRepository {
 	if s.subscriptionRepository == nil {
 		s.subscriptionRepository = repository.NewSubscriptionRepository(s.db)
 	}
 	return s.subscriptionRepository
 }
+
+func (s *Store) IdempotencyRepository() *repository.IdempotencyRepository {
+	if s.idempotencyRepository == nil {
+		s.idempotencyRepository = repository.NewIdempotencyRepository(s.db)
+	}
+	return s.idempotencyRepository
+}
+
+func (s *Store) WebhookRepository() *repository.WebhookRepository {
+	if s.webhookRepository == nil {
+		s.webhookRepository = repository.NewWebhookRepository(s.db)
+	}
+	return s.webhookRepository
+}
+
+func (s *Store) OutboxRepository() *repository.OutboxRepository {
+	if s.outboxRepository == nil {
+		s.outboxRepository = repository.NewOutboxRepository(s.db)
+	}
+	return s.outboxRepository
+}
+
+// Transactor — запуск нескольких операций репозиториев в одной транзакции
+func (s *Store) Transactor() *repository.Transactor {
+	if s.transactor == nil {
+		s.transactor = repository.NewTransactor(s.db)
+	}
+	return s.transactor
+}
+
+func (s *Store) EventListener() *repository.EventListener {
+	if s.eventListener == nil {
+		s.eventListener = repository.NewEventListener(s.db)
+	}
+	return s.eventListener
+}
+
+func (s *Store) CalendarTokenRepository() *repository.CalendarTokenRepository {
+	if s.calendarTokenRepository == nil {
+		s.calendarTokenRepository = repository.NewCalendarTokenRepository(s.db)
+	}
+	return s.calendarTokenRepository
+}
+
+func (s *Store) HealthRepository() *repository.HealthRepository {
+	if s.healthRepository == nil {
+		s.healthRepository = repository.NewHealthRepository(s.db)
+	}
+	return s.healthRepository
+}
+
+func (s *Store) APIKeyRepository() *repository.APIKeyRepository {
+	if s.apiKeyRepository == nil {
+		s.apiKeyRepository = repository.NewAPIKeyRepository(s.db)
+	}
+	return s.apiKeyRepository
+}
+
+func (s *Store) RateLimitRepository() *repository.RateLimitRepository {
+	if s.rateLimitRepository == nil {
+		s.rateLimitRepository = repository.NewRateLimitRepository(s.db)
+	}
+	return s.rateLimitRepository
+}
+
+func (s *Store) TenantRepository() *repository.TenantRepository {
+	if s.tenantRepository == nil {
+		s.tenantRepository = repository.NewTenantRepository(s.db)
+	}
+	return s.tenantRepository
+}
@@ -0,0 +1,23 @@
+package store
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// multiTracer передаёт события запросов нескольким трассировщикам, pgx принимает только один
+type multiTracer []pgx.QueryTracer
+
+func (t multiTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	for _, tracer := range t {
+		ctx = tracer.TraceQueryStart(ctx, conn, data)
+	}
+	return ctx
+}
+
+func (t multiTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	for i := len(t) - 1; i >= 0; i-- {
+		t[i].TraceQueryEnd(ctx, conn, data)
+	}
+}
@@ -0,0 +1,74 @@
+// Package tenant — компания-клиент, данные которой отделены от данных других компаний.
+// Тенант запроса хранится в ctx: по нему репозитории добавляют условие в запросы, а пул соединений
+// выставляет app.tenant_id, по которому политики RLS в базе скрывают чужие строки
+package tenant
+
+import (
+	"awesomeProject1/pkg/logger"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Header — заголовок, которым субъект без своего тенанта выбирает тенанта запроса
+const Header = "X-Tenant-ID"
+
+// AllSetting — значение app.tenant_id, при котором политики RLS пропускают строки всех тенантов
+const AllSetting = "*"
+
+// Default — тенант данных, созданных до разделения на тенантов, и субъектов без тенанта
+var Default = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+type contextKey struct{}
+
+type scope struct {
+	id  uuid.UUID
+	all bool
+}
+
+// WithID ограничивает запросы к базе в ctx тенантом id
+func WithID(ctx context.Context, id uuid.UUID) context.Context {
+	return context.WithValue(ctx, contextKey{}, scope{id: id})
+}
+
+// Attach ограничивает ctx запроса тенантом id и добавляет его в логгер запроса
+func Attach(ctx context.Context, id uuid.UUID) context.Context {
+	ctx = logger.WithFields(ctx, logger.Fields{"tenant_id": id.String()})
+	return WithID(ctx, id)
+}
+
+// WithAll снимает ограничение: для фоновых задач, которые обрабатывают данные всех тенантов,
+// например рассылки вебхуков и потока событий
+func WithAll(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKey{}, scope{all: true})
+}
+
+// FromContext возвращает тенанта из ctx, ok = false, если тенант не задан или задан WithAll
+func FromContext(ctx context.Context) (uuid.UUID, bool) {
+	s, ok := ctx.Value(contextKey{}).(scope)
+	return s.id, ok && !s.all
+}
+
+// Filter — тенант, которым ограничиваются запросы, nil — все тенанты.
+// Без тенанта в ctx это uuid.Nil: под него не подходит ни одна запись, как и под политики RLS
+func Filter(ctx context.Context) *uuid.UUID {
+	s, ok := ctx.Value(contextKey{}).(scope)
+	if ok && s.all {
+		return nil
+	}
+	id := s.id
+	return &id
+}
+
+// Setting — значение app.tenant_id для соединения с базой: id тенанта, AllSetting или пустая строка
+func Setting(ctx context.Context) string {
+	s, ok := ctx.Value(contextKey{}).(scope)
+	switch {
+	case !ok:
+		return ""
+	case s.all:
+		return AllSetting
+	default:
+		return s.id.String()
+	}
+}
@@ -0,0 +1,45 @@
+package tracing
+
+import "awesomeProject1/pkg/validator"
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOtlp   = "otlp"
+)
+
+type Config struct {
+	// Exporter — none, stdout (для локального запуска) или otlp
+	Exporter    string `yaml:"exporter"`
+	ServiceName string `yaml:"service_name"`
+	// Endpoint — адрес OTLP/HTTP коллектора, например otel-collector:4318. Пустой — из OTEL_EXPORTER_OTLP_ENDPOINT
+	Endpoint string `yaml:"endpoint"`
+	Insecure bool   `yaml:"insecure"`
+	// SampleRatio — доля трассируемых запросов, если вызывающий сервис не решил за нас
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+func NewConfig() *Config {
+	return &Config{
+		Exporter:    ExporterNone,
+		ServiceName: "subscriptions",
+		SampleRatio: 1,
+	}
+}
+
+// Validate проверяет значения, имена полей — ключи секции tracing
+func (c *Config) Validate() []validator.FieldError {
+	v := validator.New()
+	switch c.Exporter {
+	case ExporterNone, ExporterStdout, ExporterOtlp:
+	default:
+		v.AddError("exporter", validator.CodeInvalidFormat, "must be one of none, stdout, otlp")
+	}
+	if c.Exporter != ExporterNone && c.ServiceName == "" {
+		v.AddError("service_name", validator.CodeRequired, "is required when tracing is enabled")
+	}
+	if c.SampleRatio < 0 || c.SampleRatio > 1 {
+		v.AddError("sample_ratio", validator.CodeInvalidRange, "must be between 0 and 1")
+	}
+	return v.GetErrors()
+}
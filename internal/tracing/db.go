@@ -0,0 +1,85 @@
+package tracing
+
+import (
+	"awesomeProject1/internal/repository"
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const maxStatementLength = 2000
+
+var (
+	stringLiteral  = regexp.MustCompile(`'(?:[^']|'')*'`)
+	numericLiteral = regexp.MustCompile(`([^\w$.])\d+(?:\.\d+)?\b`)
+	whitespace     = regexp.MustCompile(`\s+`)
+)
+
+// QueryTracer записывает span на каждый SQL запрос. Имя span — метод репозитория,
+// аргументы запроса не записываются, литералы в тексте заменяются на ?
+func QueryTracer() pgx.QueryTracer {
+	return &queryTracer{tracer: otel.Tracer(instrumentationName)}
+}
+
+type queryTracer struct {
+	tracer trace.Tracer
+}
+
+func (t *queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	// Запросы вне трассы (фоновые задачи) не создают новых корневых span
+	if !trace.SpanFromContext(ctx).SpanContext().IsValid() {
+		return ctx
+	}
+
+	repo, method := repository.Caller(ctx)
+	statement := Sanitize(data.SQL)
+
+	ctx, _ = t.tracer.Start(ctx, repo+"."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system.name", "postgresql"),
+			attribute.String("db.operation.name", operation(statement)),
+			attribute.String("db.query.text", statement),
+		),
+	)
+	return ctx
+}
+
+func (t *queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+
+	if data.Err != nil && !errors.Is(data.Err, pgx.ErrNoRows) {
+		span.RecordError(data.Err)
+		span.SetStatus(codes.Error, data.Err.Error())
+	} else {
+		span.SetAttributes(attribute.Int64("db.response.affected_rows", data.CommandTag.RowsAffected()))
+	}
+	span.End()
+}
+
+// Sanitize убирает из текста запроса литералы и лишние пробелы. Значения передаются
+// параметрами $1, $2, поэтому литералы в запросах репозиториев — только константы, но мало ли
+func Sanitize(sql string) string {
+	sql = stringLiteral.ReplaceAllString(sql, "?")
+	sql = numericLiteral.ReplaceAllString(sql, "${1}?")
+	sql = strings.TrimSpace(whitespace.ReplaceAllString(sql, " "))
+	if len(sql) > maxStatementLength {
+		sql = sql[:maxStatementLength] + "..."
+	}
+	return sql
+}
+
+func operation(statement string) string {
+	op, _, _ := strings.Cut(statement, " ")
+	return strings.ToUpper(op)
+}
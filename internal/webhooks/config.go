@@ -0,0 +1,60 @@
+package webhooks
+
+import (
+	"awesomeProject1/pkg/validator"
+	"time"
+)
+
+type Config struct {
+	// PollInterval — как часто проверять outbox и очередь доставок
+	PollInterval time.Duration `yaml:"poll_interval"`
+	BatchSize    int           `yaml:"batch_size"`
+	// Workers — сколько доставок отправляется параллельно
+	Workers        int           `yaml:"workers"`
+	RequestTimeout time.Duration `yaml:"request_timeout"`
+	// MaxAttempts — после стольких неудачных попыток доставка уходит в dead-letter
+	MaxAttempts    int           `yaml:"max_attempts"`
+	InitialBackoff time.Duration `yaml:"initial_backoff"`
+	MaxBackoff     time.Duration `yaml:"max_backoff"`
+	// AllowPrivateTargets разрешает отправку на внутренние адреса, например на localhost при разработке
+	AllowPrivateTargets bool `yaml:"allow_private_targets"`
+}
+
+func NewConfig() *Config {
+	return &Config{
+		PollInterval:   time.Second,
+		BatchSize:      100,
+		Workers:        4,
+		RequestTimeout: 10 * time.Second,
+		MaxAttempts:    8,
+		InitialBackoff: 10 * time.Second,
+		MaxBackoff:     time.Hour,
+	}
+}
+
+// Validate проверяет значения, имена полей — ключи секции webhooks
+func (c *Config) Validate() []validator.FieldError {
+	v := validator.New()
+	if c.PollInterval <= 0 {
+		v.AddError("poll_interval", validator.CodeTooSmall, "must be a positive duration")
+	}
+	if c.RequestTimeout <= 0 {
+		v.AddError("request_timeout", validator.CodeTooSmall, "must be a positive duration")
+	}
+	if c.InitialBackoff <= 0 {
+		v.AddError("initial_backoff", validator.CodeTooSmall, "must be a positive duration")
+	}
+	if c.MaxBackoff < c.InitialBackoff {
+		v.AddError("max_backoff", validator.CodeInvalidRange, "must not be less than initial_backoff")
+	}
+	if c.BatchSize <= 0 {
+		v.AddError("batch_size", validator.CodeTooSmall, "must be greater than 0")
+	}
+	if c.Workers <= 0 {
+		v.AddError("workers", validator.CodeTooSmall, "must be greater than 0")
+	}
+	if c.MaxAttempts <= 0 {
+		v.AddError("max_attempts", validator.CodeTooSmall, "must be greater than 0")
+	}
+	return v.GetErrors()
+}
@@ -0,0 +1,219 @@
+package webhooks
+
+import (
+	"awesomeProject1/internal/dto"
+	"awesomeProject1/internal/repository"
+	"awesomeProject1/internal/tenant"
+	"awesomeProject1/pkg/logger"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/google/uuid"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	TimestampHeader = "X-Webhook-Timestamp"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+
+	maxErrorBodyBytes = 512
+)
+
+// Dispatcher переносит события из outbox в доставки и отправляет их на вебхуки.
+// Несколько экземпляров сервиса могут работать одновременно: записи забираются через SKIP LOCKED
+type Dispatcher struct {
+	config   *Config
+	outbox   repository.IOutboxRepository
+	webhooks repository.IWebhookRepository
+	client   *http.Client
+}
+
+func NewDispatcher(config *Config, outbox repository.IOutboxRepository, webhooks repository.IWebhookRepository) *Dispatcher {
+	return &Dispatcher{
+		config:   config,
+		outbox:   outbox,
+		webhooks: webhooks,
+		client:   &http.Client{Timeout: config.RequestTimeout, Transport: newTransport(config.AllowPrivateTargets)},
+	}
+}
+
+// Run обрабатывает очередь всех тенантов раз в PollInterval, пока не отменён ctx
+func (d *Dispatcher) Run(ctx context.Context) {
+	ctx = tenant.WithAll(ctx)
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		d.fanOut(ctx)
+		d.deliverDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *Dispatcher) fanOut(ctx context.Context) {
+	for ctx.Err() == nil {
+		processed, err := d.outbox.FanOut(ctx, d.config.BatchSize)
+		if err != nil {
+			logger.FromContext(ctx).WithError(err).Error("WebhookDispatcher -> FanOut")
+			return
+		}
+		if processed < d.config.BatchSize {
+			return
+		}
+	}
+}
+
+func (d *Dispatcher) deliverDue(ctx context.Context) {
+	deliveries, err := d.webhooks.ClaimDueDeliveries(ctx, d.config.BatchSize, d.lease())
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).Error("WebhookDispatcher -> ClaimDueDeliveries")
+		return
+	}
+
+	// Начатые отправки при остановке сервиса доводятся до конца, каждую ограничивает RequestTimeout,
+	// иначе прерванная попытка засчиталась бы как неудачная. Не начатые сразу возвращаются в очередь
+	sendCtx := context.WithoutCancel(ctx)
+
+	sem := make(chan struct{}, max(d.config.Workers, 1))
+	wg := sync.WaitGroup{}
+	for i, delivery := range deliveries {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			d.release(sendCtx, deliveries[i:])
+			break
+		}
+		wg.Add(1)
+		go func(delivery *dto.WebhookDelivery) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			d.deliver(sendCtx, delivery)
+		}(delivery)
+	}
+	wg.Wait()
+}
+
+// release возвращает в очередь доставки, отправка которых не началась
+func (d *Dispatcher) release(ctx context.Context, deliveries []*dto.WebhookDelivery) {
+	ids := make([]uuid.UUID, len(deliveries))
+	for i, delivery := range deliveries {
+		ids[i] = delivery.ID
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, d.config.RequestTimeout)
+	defer cancel()
+	if err := d.webhooks.ReleaseDeliveries(ctx, ids); err != nil {
+		logger.FromContext(ctx).WithError(err).WithFields(logger.Fields{"count": len(ids)}).Error("WebhookDispatcher -> ReleaseDeliveries")
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery *dto.WebhookDelivery) {
+	statusCode, err := d.send(ctx, delivery)
+
+	if err == nil {
+		if err := d.webhooks.MarkDelivered(ctx, delivery.ID, *statusCode); err != nil {
+			logger.FromContext(ctx).WithError(err).Error("WebhookDispatcher -> MarkDelivered")
+		}
+		return
+	}
+
+	dead := delivery.Attempts >= d.config.MaxAttempts
+	nextAttemptAt := time.Now().Add(d.backoff(delivery.Attempts))
+
+	log := logger.FromContext(ctx).WithError(err).WithFields(logger.Fields{
+		"delivery_id": delivery.ID,
+		"webhook_id":  delivery.WebhookID,
+		"attempt":     delivery.Attempts,
+	})
+	if dead {
+		log.Error("WebhookDispatcher -> delivery failed, moved to dead-letter")
+	} else {
+		log.Warn("WebhookDispatcher -> delivery failed")
+	}
+
+	if err := d.webhooks.MarkFailed(ctx, delivery.ID, statusCode, err.Error(), nextAttemptAt, dead); err != nil {
+		logger.FromContext(ctx).WithError(err).Error("WebhookDispatcher -> MarkFailed")
+	}
+}
+
+// lease — на сколько забирается пачка доставок. Пачка отправляется волнами по Workers штук,
+// каждую отправку ограничивает RequestTimeout, поэтому доставка не вернётся в очередь,
+// пока последняя волна ещё может быть в работе
+func (d *Dispatcher) lease() time.Duration {
+	workers := max(d.config.Workers, 1)
+	waves := (d.config.BatchSize + workers - 1) / workers
+	return time.Duration(waves+1)*d.config.RequestTimeout + d.config.PollInterval
+}
+
+// send отправляет событие, успехом считается любой 2xx ответ
+func (d *Dispatcher) send(ctx context.Context, delivery *dto.WebhookDelivery) (*int, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, delivery.ID.String())
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(delivery.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	statusCode := resp.StatusCode
+	if statusCode >= 200 && statusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return &statusCode, nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+	return &statusCode, fmt.Errorf("unexpected status %d: %s", statusCode, bytes.TrimSpace(body))
+}
+
+// backoff — экспоненциальная задержка перед следующей попыткой с разбросом ±20%,
+// чтобы повторы разных доставок не приходили одновременно
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	delay := d.config.InitialBackoff
+	for i := 1; i < attempt && delay < d.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	delay = min(delay, d.config.MaxBackoff)
+
+	jitter := time.Duration(rand.Int64N(int64(delay)/5+1)) * 2
+	return delay - delay/5 + jitter
+}
+
+// Sign возвращает значение заголовка X-Webhook-Signature: HMAC-SHA256 от "<timestamp>.<body>".
+// Получатель должен проверить подпись и отклонять запросы со старым timestamp
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,40 @@
+package webhooks
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// newTransport возвращает транспорт, который не соединяется с внутренними адресами: loopback, частными сетями,
+// link-local (в том числе метаданными облака 169.254.169.254). Проверяется адрес, с которым действительно
+// устанавливается соединение, после резолва имени, поэтому её не обойти DNS записью или редиректом.
+// Прокси из окружения не используется, иначе проверялся бы адрес прокси
+func newTransport(allowPrivate bool) *http.Transport {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if !allowPrivate {
+		dialer.Control = checkTarget
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
+
+func checkTarget(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := addrPort.Addr().Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return fmt.Errorf("webhook target %s is not allowed: internal address", ip)
+	}
+	return nil
+}
@@ -0,0 +1,2 @@
+-- Откат: удаление версии записи
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS version;
@@ -0,0 +1,4 @@
+-- Версия записи для оптимистичной блокировки (ETag / If-Match)
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+COMMENT ON COLUMN subscriptions.version IS 'Версия записи, увеличивается при каждом обновлении';
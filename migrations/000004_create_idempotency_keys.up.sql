@@ -0,0 +1,17 @@
+-- Ключи идемпотентности для повторных запросов создания
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key VARCHAR(255) PRIMARY KEY,
+    request_hash VARCHAR(64) NOT NULL,
+    status_code INTEGER,
+    headers JSONB,
+    response_body BYTEA,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+
+COMMENT ON TABLE idempotency_keys IS 'Сохранённые ответы на запросы с заголовком Idempotency-Key';
+COMMENT ON COLUMN idempotency_keys.request_hash IS 'SHA-256 от метода, пути и тела запроса';
+COMMENT ON COLUMN idempotency_keys.status_code IS 'HTTP статус ответа, NULL пока запрос обрабатывается';
+COMMENT ON COLUMN idempotency_keys.expires_at IS 'Время, после которого ключ можно использовать повторно';
@@ -0,0 +1,63 @@
+-- Подписки клиентов на события
+CREATE TABLE IF NOT EXISTS webhooks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    url TEXT NOT NULL,
+    secret VARCHAR(255) NOT NULL,
+    event_types TEXT[] NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_webhooks_updated_at
+    BEFORE UPDATE ON webhooks
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Transactional outbox: событие пишется в одной транзакции с изменением подписки
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id BIGSERIAL PRIMARY KEY,
+    event_id UUID NOT NULL UNIQUE,
+    event_type VARCHAR(64) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    processed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_outbox_events_unprocessed ON outbox_events(id) WHERE processed_at IS NULL;
+
+-- Доставки событий на конкретные вебхуки
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    webhook_id UUID NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    event_id UUID NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'dead')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_status_code INTEGER,
+    last_error TEXT,
+    delivered_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT webhook_deliveries_event_unique UNIQUE (webhook_id, event_id)
+);
+
+CREATE TRIGGER update_webhook_deliveries_updated_at
+    BEFORE UPDATE ON webhook_deliveries
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_webhook_deliveries_webhook_id ON webhook_deliveries(webhook_id, created_at);
+
+COMMENT ON TABLE webhooks IS 'Зарегистрированные клиентами адреса для уведомлений о событиях';
+COMMENT ON COLUMN webhooks.secret IS 'Ключ HMAC-SHA256 подписи тела запроса';
+COMMENT ON COLUMN webhooks.event_types IS 'Типы событий, которые отправляются на вебхук';
+COMMENT ON TABLE outbox_events IS 'События подписок, ожидающие рассылки по вебхукам';
+COMMENT ON COLUMN outbox_events.processed_at IS 'Время, когда по событию созданы доставки, NULL пока не обработано';
+COMMENT ON TABLE webhook_deliveries IS 'Журнал доставок событий на вебхуки';
+COMMENT ON COLUMN webhook_deliveries.status IS 'pending — ждёт отправки или повтора, delivered — доставлено, dead — попытки исчерпаны';
+COMMENT ON COLUMN webhook_deliveries.next_attempt_at IS 'Время следующей попытки отправки';
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS notify_outbox_events_insert ON outbox_events;
+DROP FUNCTION IF EXISTS notify_outbox_event();
@@ -0,0 +1,16 @@
+-- Уведомление экземпляров сервиса о новых событиях для потока SSE
+CREATE OR REPLACE FUNCTION notify_outbox_event()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('subscription_events', NEW.id::text);
+RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER notify_outbox_events_insert
+    AFTER INSERT ON outbox_events
+    FOR EACH ROW
+    EXECUTE FUNCTION notify_outbox_event();
+
+COMMENT ON FUNCTION notify_outbox_event() IS 'Отправляет id нового события в канал subscription_events';
+COMMENT ON COLUMN outbox_events.id IS 'Порядковый номер события, используется как id события SSE';
@@ -0,0 +1,9 @@
+-- Токены доступа к календарю подписок пользователя
+CREATE TABLE IF NOT EXISTS calendar_tokens (
+    user_id UUID PRIMARY KEY,
+    token_hash VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE calendar_tokens IS 'Токены ссылок на iCalendar ленту пользователя, у пользователя действует только последний выпущенный токен';
+COMMENT ON COLUMN calendar_tokens.token_hash IS 'SHA-256 от токена, сам токен не хранится';
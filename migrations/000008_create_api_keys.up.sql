@@ -0,0 +1,19 @@
+-- Ключи доступа к API
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL UNIQUE,
+    user_id UUID,
+    admin BOOLEAN NOT NULL DEFAULT FALSE,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE api_keys IS 'Ключи доступа к API, сам ключ показывается один раз при создании';
+COMMENT ON COLUMN api_keys.prefix IS 'Начало ключа, по нему ключ узнаётся в списке';
+COMMENT ON COLUMN api_keys.key_hash IS 'SHA-256 от ключа, сам ключ не хранится';
+COMMENT ON COLUMN api_keys.user_id IS 'Пользователь, от имени которого действует ключ, NULL — сервисный ключ';
+COMMENT ON COLUMN api_keys.admin IS 'Доступ к /admin/*';
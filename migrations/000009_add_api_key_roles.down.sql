@@ -0,0 +1,7 @@
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS admin BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE api_keys SET admin = 'admin' = ANY(roles);
+
+ALTER TABLE api_keys DROP COLUMN IF EXISTS roles;
+
+COMMENT ON COLUMN api_keys.admin IS 'Доступ к /admin/*';
@@ -0,0 +1,8 @@
+-- Роли ключей API вместо признака администратора
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS roles TEXT[] NOT NULL DEFAULT '{}';
+
+UPDATE api_keys SET roles = '{admin}' WHERE admin;
+
+ALTER TABLE api_keys DROP COLUMN IF EXISTS admin;
+
+COMMENT ON COLUMN api_keys.roles IS 'Роли ключа из политики доступа, пустой список — роли по умолчанию';
@@ -0,0 +1,2 @@
+DROP FUNCTION IF EXISTS rate_limit_take(TEXT, INTEGER, DOUBLE PRECISION);
+DROP TABLE IF EXISTS rate_limit_buckets;
@@ -0,0 +1,43 @@
+-- Вёдра токенов ограничения частоты запросов, общие для всех экземпляров сервиса
+CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+    key TEXT PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+COMMENT ON TABLE rate_limit_buckets IS 'Вёдра токенов rate_limit.store = postgres';
+COMMENT ON COLUMN rate_limit_buckets.key IS 'Маршрут или default и клиент: ключ API, пользователь или IP';
+COMMENT ON COLUMN rate_limit_buckets.tokens IS 'Токены на момент updated_at';
+
+CREATE INDEX IF NOT EXISTS idx_rate_limit_buckets_updated_at ON rate_limit_buckets (updated_at);
+
+-- Забирает токен из ведра p_key ёмкостью p_capacity, которое пополняется на p_rate токенов в секунду.
+-- Строка блокируется до конца транзакции, поэтому параллельные запросы одного клиента не теряют списания
+CREATE OR REPLACE FUNCTION rate_limit_take(p_key TEXT, p_capacity INTEGER, p_rate DOUBLE PRECISION)
+RETURNS TABLE (allowed BOOLEAN, tokens DOUBLE PRECISION) AS $$
+DECLARE
+    v_now TIMESTAMP WITH TIME ZONE := clock_timestamp();
+    v_tokens DOUBLE PRECISION;
+    v_updated_at TIMESTAMP WITH TIME ZONE;
+BEGIN
+    INSERT INTO rate_limit_buckets (key, tokens, updated_at)
+    VALUES (p_key, p_capacity, v_now)
+    ON CONFLICT (key) DO NOTHING;
+
+    SELECT b.tokens, b.updated_at INTO v_tokens, v_updated_at
+    FROM rate_limit_buckets b
+    WHERE b.key = p_key
+    FOR UPDATE;
+
+    v_tokens := LEAST(p_capacity, v_tokens + GREATEST(EXTRACT(EPOCH FROM v_now - v_updated_at), 0) * p_rate);
+    allowed := v_tokens >= 1;
+    IF allowed THEN
+        v_tokens := v_tokens - 1;
+    END IF;
+
+    UPDATE rate_limit_buckets b SET tokens = v_tokens, updated_at = v_now WHERE b.key = p_key;
+
+    tokens := v_tokens;
+    RETURN NEXT;
+END;
+$$ LANGUAGE plpgsql;
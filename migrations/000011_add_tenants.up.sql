@@ -0,0 +1,120 @@
+-- Тенанты — компании-клиенты, данные которых разделены
+CREATE TABLE IF NOT EXISTS tenants (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE tenants IS 'Компании-клиенты, каждая видит только свои данные';
+
+-- Все данные, созданные до разделения, принадлежат тенанту по умолчанию
+INSERT INTO tenants (id, name) VALUES ('00000000-0000-0000-0000-000000000001', 'default') ON CONFLICT DO NOTHING;
+
+-- tenant_id заполняется для существующих строк, после чего значение по умолчанию убирается:
+-- запись без явного тенанта — ошибка, а не строка тенанта по умолчанию
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants(id);
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants(id);
+ALTER TABLE webhooks ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants(id);
+ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants(id);
+ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants(id);
+ALTER TABLE calendar_tokens ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants(id);
+-- Существующие ключи становятся ключами платформы: без тенанта, как и раньше, они работают с тенантом по умолчанию
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS tenant_id UUID REFERENCES tenants(id);
+
+ALTER TABLE subscriptions ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE idempotency_keys ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE webhooks ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE outbox_events ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE webhook_deliveries ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE calendar_tokens ALTER COLUMN tenant_id DROP DEFAULT;
+
+-- Ключи идемпотентности и токены календаря уникальны внутри тенанта
+ALTER TABLE idempotency_keys DROP CONSTRAINT idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (tenant_id, key);
+ALTER TABLE calendar_tokens DROP CONSTRAINT calendar_tokens_pkey;
+ALTER TABLE calendar_tokens ADD PRIMARY KEY (tenant_id, user_id);
+
+CREATE INDEX IF NOT EXISTS idx_subscriptions_tenant_user ON subscriptions(tenant_id, user_id);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_tenant_created_at ON subscriptions(tenant_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_webhooks_tenant_id ON webhooks(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_tenant_id ON outbox_events(tenant_id, id);
+CREATE INDEX IF NOT EXISTS idx_api_keys_tenant_id ON api_keys(tenant_id);
+
+COMMENT ON COLUMN subscriptions.tenant_id IS 'Тенант, которому принадлежит подписка';
+COMMENT ON COLUMN api_keys.tenant_id IS 'Тенант ключа, NULL — ключ платформы, тенант выбирается заголовком X-Tenant-ID';
+
+-- Тенант соединения задаёт сервис через set_config('app.tenant_id', ...): id тенанта или * для фоновых задач,
+-- которые обрабатывают всех тенантов. Без значения политики не пропускают ни одной строки
+CREATE OR REPLACE FUNCTION app_tenant_id()
+RETURNS UUID AS $$
+    SELECT CASE
+        WHEN current_setting('app.tenant_id', true) ~ '^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$'
+        THEN current_setting('app.tenant_id', true)::uuid
+    END
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION app_all_tenants()
+RETURNS BOOLEAN AS $$
+    SELECT COALESCE(current_setting('app.tenant_id', true) = '*', false)
+$$ LANGUAGE sql STABLE;
+
+COMMENT ON FUNCTION app_tenant_id() IS 'Тенант текущего соединения из app.tenant_id, NULL если не задан';
+COMMENT ON FUNCTION app_all_tenants() IS 'Соединение фоновой задачи, которой видны все тенанты';
+
+-- FORCE действует и на владельца таблиц. Суперпользователь и роли с BYPASSRLS политики обходят всегда,
+-- поэтому сервис переключается на роль subscriptions_app (storage.db_role).
+-- Миграции с изменением данных после этой должны выставлять app.tenant_id = '*'
+ALTER TABLE subscriptions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE subscriptions FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON subscriptions USING (app_all_tenants() OR tenant_id = app_tenant_id());
+
+ALTER TABLE idempotency_keys ENABLE ROW LEVEL SECURITY;
+ALTER TABLE idempotency_keys FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON idempotency_keys USING (app_all_tenants() OR tenant_id = app_tenant_id());
+
+ALTER TABLE webhooks ENABLE ROW LEVEL SECURITY;
+ALTER TABLE webhooks FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON webhooks USING (app_all_tenants() OR tenant_id = app_tenant_id());
+
+ALTER TABLE outbox_events ENABLE ROW LEVEL SECURITY;
+ALTER TABLE outbox_events FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON outbox_events USING (app_all_tenants() OR tenant_id = app_tenant_id());
+
+ALTER TABLE webhook_deliveries ENABLE ROW LEVEL SECURITY;
+ALTER TABLE webhook_deliveries FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON webhook_deliveries USING (app_all_tenants() OR tenant_id = app_tenant_id());
+
+ALTER TABLE calendar_tokens ENABLE ROW LEVEL SECURITY;
+ALTER TABLE calendar_tokens FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON calendar_tokens USING (app_all_tenants() OR tenant_id = app_tenant_id());
+
+ALTER TABLE api_keys ENABLE ROW LEVEL SECURITY;
+ALTER TABLE api_keys FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON api_keys USING (app_all_tenants() OR tenant_id = app_tenant_id());
+
+-- Роль сервиса без прав суперпользователя, чтобы политики действовали. Создание роли требует CREATEROLE,
+-- без него роль создаётся заранее администратором базы и выдаётся пользователю сервиса.
+-- Комментарий отмечает, что роль создала миграция: откат удаляет только такую роль
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT FROM pg_roles WHERE rolname = 'subscriptions_app') THEN
+        CREATE ROLE subscriptions_app NOLOGIN;
+        COMMENT ON ROLE subscriptions_app IS 'created by migration 000011_add_tenants';
+    END IF;
+END
+$$;
+
+-- Повторная выдача роли, которая уже есть у пользователя (например, через другую роль), требует ADMIN OPTION
+DO $$
+BEGIN
+    IF NOT pg_has_role(CURRENT_USER, 'subscriptions_app', 'MEMBER') THEN
+        GRANT subscriptions_app TO CURRENT_USER;
+    END IF;
+END
+$$;
+
+GRANT USAGE ON SCHEMA public TO subscriptions_app;
+GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO subscriptions_app;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO subscriptions_app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO subscriptions_app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT USAGE, SELECT ON SEQUENCES TO subscriptions_app;
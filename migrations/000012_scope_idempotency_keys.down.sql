@@ -0,0 +1,12 @@
+-- Из записей с одинаковым ключом у разных субъектов остаётся одна
+DELETE FROM idempotency_keys a
+USING idempotency_keys b
+WHERE a.tenant_id = b.tenant_id
+  AND a.key = b.key
+  AND a.subject > b.subject;
+
+ALTER TABLE idempotency_keys DROP CONSTRAINT idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (tenant_id, key);
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS subject;
+
+COMMENT ON COLUMN idempotency_keys.expires_at IS 'Время, после которого ключ можно использовать повторно';
@@ -0,0 +1,10 @@
+-- Ключ идемпотентности уникален для субъекта запроса: одинаковые ключи разных клиентов не пересекаются,
+-- и клиент не получит сохранённый ответ на чужой запрос. Старые записи остаются без субъекта и истекают сами
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS subject TEXT NOT NULL DEFAULT '';
+ALTER TABLE idempotency_keys ALTER COLUMN subject DROP DEFAULT;
+
+ALTER TABLE idempotency_keys DROP CONSTRAINT idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (tenant_id, subject, key);
+
+COMMENT ON COLUMN idempotency_keys.subject IS 'Субъект запроса: ключ API или субъект токена';
+COMMENT ON COLUMN idempotency_keys.expires_at IS 'Пока запрос обрабатывается — конец аренды ключа, после ответа — время, после которого ключ можно использовать повторно';
@@ -0,0 +1,52 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// APIKeysService — маршруты /admin/api-keys, нужно разрешение admin:api-keys
+type APIKeysService struct {
+	client *Client
+}
+
+// Create выпускает ключ. Сам ключ в ответе возвращается только здесь, сервер хранит лишь его хеш
+func (s *APIKeysService) Create(ctx context.Context, req *CreateAPIKeyRequest, opts ...RequestOption) (*APIKeyInfo, error) {
+	r := &request{method: http.MethodPost, path: "/admin/api-keys", root: true, body: req}
+
+	out := &APIKeyInfo{}
+	if _, err := s.client.do(ctx, r.apply(opts), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *APIKeysService) List(ctx context.Context, offset, limit int, opts ...RequestOption) (*APIKeyList, error) {
+	r := &request{method: http.MethodGet, path: "/admin/api-keys", root: true, query: pageQuery(offset, limit), idempotent: true}
+
+	out := &APIKeyList{}
+	if _, err := s.client.do(ctx, r.apply(opts), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *APIKeysService) All(ctx context.Context, pageSize int) iter.Seq2[*APIKeyInfo, error] {
+	return paginate(ctx, pageSize, func(ctx context.Context, offset, limit int) ([]*APIKeyInfo, int, error) {
+		page, err := s.List(ctx, offset, limit)
+		if err != nil {
+			return nil, 0, err
+		}
+		return page.Keys, page.Total, nil
+	})
+}
+
+// Revoke отзывает ключ, запросы с ним сразу перестают проходить аутентификацию
+func (s *APIKeysService) Revoke(ctx context.Context, id uuid.UUID, opts ...RequestOption) error {
+	r := &request{method: http.MethodDelete, path: "/admin/api-keys/" + pathEscape(id.String()), root: true, idempotent: true}
+	_, err := s.client.do(ctx, r.apply(opts), nil)
+	return err
+}
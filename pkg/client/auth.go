@@ -0,0 +1,31 @@
+package client
+
+import "net/http"
+
+// Authenticator добавляет к запросу данные авторизации
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// AuthFunc позволяет передать функцию как Authenticator
+type AuthFunc func(req *http.Request) error
+
+func (f AuthFunc) Authenticate(req *http.Request) error {
+	return f(req)
+}
+
+// BearerToken передаёт токен в заголовке Authorization: Bearer
+func BearerToken(token string) Authenticator {
+	return AuthFunc(func(req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	})
+}
+
+// APIKey передаёт ключ в указанном заголовке, например X-API-Key
+func APIKey(header, key string) Authenticator {
+	return AuthFunc(func(req *http.Request) error {
+		req.Header.Set(header, key)
+		return nil
+	})
+}
@@ -0,0 +1,45 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/google/uuid"
+)
+
+// CalendarService — iCalendar лента пользователя
+type CalendarService struct {
+	client *Client
+}
+
+// IssueToken выпускает новую ссылку на календарь, прежняя перестаёт работать
+func (s *CalendarService) IssueToken(ctx context.Context, userId uuid.UUID, opts ...RequestOption) (*CalendarToken, error) {
+	r := &request{method: http.MethodPost, path: "/users/" + pathEscape(userId.String()) + "/calendar-token"}
+
+	out := &CalendarToken{}
+	if _, err := s.client.do(ctx, r.apply(opts), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Feed возвращает календарь в формате iCalendar
+func (s *CalendarService) Feed(ctx context.Context, userId uuid.UUID, token string, opts ...RequestOption) ([]byte, error) {
+	r := &request{
+		method:     http.MethodGet,
+		path:       "/users/" + pathEscape(userId.String()) + "/calendar.ics",
+		query:      url.Values{"token": {token}},
+		idempotent: true,
+	}
+
+	resp, body, err := s.client.send(ctx, r.apply(opts))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, newAPIError(resp, body)
+	}
+
+	return body, nil
+}
@@ -0,0 +1,245 @@
+// Package client — типизированный клиент Subscription API.
+//
+//	c, err := client.New("http://localhost:8080/api/v1", client.WithAuth(client.BearerToken(token)))
+//	sub, err := c.Subscriptions.Get(ctx, id)
+//
+// Ответы разворачиваются из конверта SuccessMessage, ошибки возвращаются как *APIError
+package client
+
+import (
+	"awesomeProject1/pkg/httpHelpers"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const defaultTimeout = 30 * time.Second
+
+// apiPrefix — префикс маршрутов API. Маршруты /admin и пробы /healthz, /readyz лежат вне его,
+// их путь отсчитывается от rootPath — baseURL без префикса
+const apiPrefix = "/api/v1"
+
+// Client — клиент API. Методы сгруппированы по ресурсам
+type Client struct {
+	baseURL    *url.URL
+	rootPath   string
+	httpClient *http.Client
+	auth       Authenticator
+	retry      RetryPolicy
+	userAgent  string
+	tenant     string
+
+	Subscriptions *SubscriptionsService
+	Webhooks      *WebhooksService
+	Calendar      *CalendarService
+	Events        *EventsService
+	GraphQL       *GraphQLService
+	APIKeys       *APIKeysService
+	Tenants       *TenantsService
+	LogLevel      *LogLevelService
+	Health        *HealthService
+}
+
+type Option func(*Client)
+
+// WithHTTPClient задаёт свой http.Client, например с другим таймаутом или транспортом
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) { c.httpClient = httpClient }
+}
+
+// WithAuth задаёт способ авторизации запросов
+func WithAuth(auth Authenticator) Option {
+	return func(c *Client) { c.auth = auth }
+}
+
+// WithRetry задаёт политику повторов, RetryPolicy{} отключает повторы
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *Client) { c.retry = policy }
+}
+
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) { c.userAgent = userAgent }
+}
+
+// TenantHeader — заголовок, которым ключ или токен платформы выбирает тенанта запросов
+const TenantHeader = "X-Tenant-ID"
+
+// WithTenant отправляет запросы к тенанту tenantId. Нужен только ключу или токену платформы,
+// ключ тенанта и так работает с данными своего тенанта
+func WithTenant(tenantId string) Option {
+	return func(c *Client) { c.tenant = tenantId }
+}
+
+// New создаёт клиент. baseURL — адрес API вместе с префиксом, например http://localhost:8080/api/v1
+func New(baseURL string, opts ...Option) (*Client, error) {
+	parsed, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("client: invalid base url: %w", err)
+	}
+	if parsed.Scheme == "" || parsed.Host == "" {
+		return nil, fmt.Errorf("client: base url must be absolute, got %q", baseURL)
+	}
+
+	c := &Client{
+		baseURL:    parsed,
+		rootPath:   strings.TrimSuffix(parsed.Path, apiPrefix),
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		retry:      DefaultRetryPolicy(),
+		userAgent:  "subscriptions-go-client",
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	c.Subscriptions = &SubscriptionsService{client: c}
+	c.Webhooks = &WebhooksService{client: c}
+	c.Calendar = &CalendarService{client: c}
+	c.Events = &EventsService{client: c}
+	c.GraphQL = &GraphQLService{client: c}
+	c.APIKeys = &APIKeysService{client: c}
+	c.Tenants = &TenantsService{client: c}
+	c.LogLevel = &LogLevelService{client: c}
+	c.Health = &HealthService{client: c}
+
+	return c, nil
+}
+
+// request — описание одного вызова API
+type request struct {
+	method      string
+	path        string
+	root        bool
+	query       url.Values
+	header      http.Header
+	body        any
+	rawBody     []byte
+	contentType string
+	// idempotent — запрос можно безопасно повторить
+	idempotent bool
+}
+
+// do выполняет запрос с повторами, разворачивает конверт SuccessMessage в out (если out не nil)
+// и возвращает заголовки ответа
+func (c *Client) do(ctx context.Context, req *request, out any) (http.Header, error) {
+	resp, body, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return resp.Header, newAPIError(resp, body)
+	}
+
+	if out == nil || len(body) == 0 {
+		return resp.Header, nil
+	}
+
+	envelope := struct {
+		Data json.RawMessage `json:"data"`
+	}{}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return resp.Header, fmt.Errorf("client: cannot decode response: %w", err)
+	}
+
+	if len(envelope.Data) == 0 {
+		return resp.Header, nil
+	}
+
+	if err := json.Unmarshal(envelope.Data, out); err != nil {
+		return resp.Header, fmt.Errorf("client: cannot decode response data: %w", err)
+	}
+
+	return resp.Header, nil
+}
+
+// send отправляет запрос, повторяя его по политике повторов, и читает тело ответа целиком
+func (c *Client) send(ctx context.Context, req *request) (*http.Response, []byte, error) {
+	payload := req.rawBody
+	if req.body != nil {
+		var err error
+		if payload, err = json.Marshal(req.body); err != nil {
+			return nil, nil, fmt.Errorf("client: cannot encode request: %w", err)
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		httpReq, err := c.newRequest(ctx, req, payload)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		resp, err := c.httpClient.Do(httpReq)
+		var body []byte
+		if err == nil {
+			body, err = io.ReadAll(resp.Body)
+			resp.Body.Close()
+		}
+
+		if !c.retry.shouldRetry(req, attempt, resp, err) {
+			if err != nil {
+				return nil, nil, err
+			}
+			return resp, body, nil
+		}
+
+		if err := c.retry.wait(ctx, attempt, resp); err != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+func (c *Client) newRequest(ctx context.Context, req *request, payload []byte) (*http.Request, error) {
+	u := *c.baseURL
+	u.Path = c.baseURL.Path + req.path
+	if req.root {
+		u.Path = c.rootPath + req.path
+	}
+	u.RawQuery = req.query.Encode()
+
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, values := range req.header {
+		for _, value := range values {
+			httpReq.Header.Add(name, value)
+		}
+	}
+
+	if payload != nil {
+		contentType := req.contentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+	httpReq.Header.Set("Accept", "application/json, "+httpHelpers.ProblemContentType)
+	httpReq.Header.Set("User-Agent", c.userAgent)
+	if c.tenant != "" {
+		httpReq.Header.Set(TenantHeader, c.tenant)
+	}
+
+	if c.auth != nil {
+		if err := c.auth.Authenticate(httpReq); err != nil {
+			return nil, fmt.Errorf("client: authenticate request: %w", err)
+		}
+	}
+
+	return httpReq, nil
+}
+
+func pathEscape(segment string) string {
+	return url.PathEscape(segment)
+}
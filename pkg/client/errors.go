@@ -0,0 +1,83 @@
+package client
+
+import (
+	"awesomeProject1/pkg/httpHelpers"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// Ошибки по статусу ответа, проверяются через errors.Is(err, client.ErrConflict)
+var (
+	ErrBadRequest         = errors.New("bad request")
+	ErrUnauthorized       = errors.New("unauthorized")
+	ErrForbidden          = errors.New("forbidden")
+	ErrNotFound           = errors.New("not found")
+	ErrConflict           = errors.New("conflict")
+	ErrPreconditionFailed = errors.New("precondition failed")
+	ErrUnprocessable      = errors.New("unprocessable entity")
+	ErrRateLimited        = errors.New("rate limited")
+	ErrServer             = errors.New("server error")
+)
+
+var statusErrors = map[int]error{
+	http.StatusBadRequest:          ErrBadRequest,
+	http.StatusUnauthorized:        ErrUnauthorized,
+	http.StatusForbidden:           ErrForbidden,
+	http.StatusNotFound:            ErrNotFound,
+	http.StatusConflict:            ErrConflict,
+	http.StatusPreconditionFailed:  ErrPreconditionFailed,
+	http.StatusUnprocessableEntity: ErrUnprocessable,
+	http.StatusTooManyRequests:     ErrRateLimited,
+}
+
+// APIError — ответ API с ошибкой. Problem содержит тело RFC 7807, если сервер его вернул
+type APIError struct {
+	StatusCode int
+	Problem    *Problem
+}
+
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+
+	problem := &httpHelpers.ErrorMessage{}
+	if err := json.Unmarshal(body, problem); err == nil && problem.Status != 0 {
+		apiErr.Problem = problem
+	}
+
+	return apiErr
+}
+
+func (e *APIError) Error() string {
+	if e.Problem != nil && e.Problem.Detail != "" {
+		return fmt.Sprintf("api error %d: %s", e.StatusCode, e.Problem.Detail)
+	}
+	return fmt.Sprintf("api error %d: %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Is сопоставляет ошибку со статусными ErrXxx
+func (e *APIError) Is(target error) bool {
+	if target == ErrServer {
+		return e.StatusCode >= http.StatusInternalServerError
+	}
+	return statusErrors[e.StatusCode] == target
+}
+
+// FieldErrors — ошибки валидации по полям, если они есть
+func (e *APIError) FieldErrors() []FieldError {
+	if e.Problem == nil {
+		return nil
+	}
+	return e.Problem.Errors
+}
+
+// RequestID — id ошибки из ответа, по нему её можно найти в логах сервера
+func (e *APIError) RequestID() string {
+	if e.Problem == nil || e.Problem.Id == uuid.Nil {
+		return ""
+	}
+	return e.Problem.Id.String()
+}
@@ -0,0 +1,156 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// EventsService — поток изменений подписок (Server-Sent Events)
+type EventsService struct {
+	client *Client
+}
+
+// ErrStopStream можно вернуть из обработчика Stream, чтобы закрыть поток без ошибки
+var ErrStopStream = errors.New("client: stop stream")
+
+// Stream читает поток событий и вызывает fn для каждого. lastEventID > 0 продолжает поток после этого события.
+// При обрыве соединения или ответе 5xx Stream переподключается по политике повторов с Last-Event-ID последнего полученного события,
+// поэтому события не теряются. Возвращает ошибку fn, ошибку API или ctx.Err()
+func (s *EventsService) Stream(ctx context.Context, filter *SubscriptionFilter, lastEventID int64, fn func(*Event) error) error {
+	query := url.Values{}
+	if filter != nil {
+		setIfNotEmpty(query, "user_id", filter.UserId)
+		setIfNotEmpty(query, "service_name", filter.ServiceName)
+	}
+
+	// Общий таймаут http.Client оборвал бы долгий поток, его ограничивает только ctx
+	streamClient := *s.client.httpClient
+	streamClient.Timeout = 0
+
+	for attempt := 1; ; attempt++ {
+		r := &request{method: http.MethodGet, path: "/subscriptions/events", query: query}
+		if lastEventID > 0 {
+			r.setHeader("Last-Event-ID", strconv.FormatInt(lastEventID, 10))
+		}
+
+		received, err := s.read(ctx, &streamClient, r, &lastEventID, fn)
+		if errors.Is(err, ErrStopStream) {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode < http.StatusInternalServerError {
+			return err
+		}
+		if apiErr == nil && !isStreamError(err) {
+			return err
+		}
+
+		// Полученные события означают, что соединение работало: считаем попытки заново
+		if received {
+			attempt = 1
+		}
+		if attempt >= s.client.retry.MaxAttempts {
+			return fmt.Errorf("client: event stream: %w", err)
+		}
+		if err := s.client.retry.wait(ctx, attempt, nil); err != nil {
+			return err
+		}
+	}
+}
+
+// read держит одно соединение. received — было ли получено хотя бы одно событие
+func (s *EventsService) read(ctx context.Context, httpClient *http.Client, r *request, lastEventID *int64, fn func(*Event) error) (bool, error) {
+	httpReq, err := s.client.newRequest(ctx, r, nil)
+	if err != nil {
+		return false, err
+	}
+	httpReq.Header.Set("Accept", "text/event-stream")
+
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return false, streamError{err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return false, newAPIError(resp, body)
+	}
+
+	received := false
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+
+	var id, data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case line == "":
+			// Пустая строка завершает событие
+			if data.Len() > 0 {
+				event := &Event{}
+				if err := json.Unmarshal([]byte(data.String()), event); err != nil {
+					return received, fmt.Errorf("client: cannot decode event: %w", err)
+				}
+				if seq, err := strconv.ParseInt(id.String(), 10, 64); err == nil {
+					event.Seq = seq
+					*lastEventID = seq
+				}
+				received = true
+				if err := fn(event); err != nil {
+					return received, err
+				}
+			}
+			id.Reset()
+			data.Reset()
+		case strings.HasPrefix(line, ":"):
+			// Комментарий-пинг
+		default:
+			field, value, _ := strings.Cut(line, ":")
+			value = strings.TrimPrefix(value, " ")
+			switch field {
+			case "id":
+				id.Reset()
+				id.WriteString(value)
+			case "data":
+				if data.Len() > 0 {
+					data.WriteByte('\n')
+				}
+				data.WriteString(value)
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return received, streamError{err}
+	}
+
+	// Сервер закрыл соединение — переподключаемся
+	return received, streamError{errors.New("stream closed by server")}
+}
+
+// streamError — обрыв соединения, после которого поток переподключается
+type streamError struct {
+	err error
+}
+
+func (e streamError) Error() string { return e.err.Error() }
+func (e streamError) Unwrap() error { return e.err }
+
+func isStreamError(err error) bool {
+	var sErr streamError
+	return errors.As(err, &sErr)
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// GraphQLService — POST /graphql
+type GraphQLService struct {
+	client *Client
+}
+
+// GraphQLError — ошибка из поля errors ответа GraphQL
+type GraphQLError struct {
+	Message    string         `json:"message"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// GraphQLErrors — все ошибки ответа. Данные при этом могут быть заполнены частично
+type GraphQLErrors []GraphQLError
+
+func (e GraphQLErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Message
+	}
+	return "graphql: " + strings.Join(messages, "; ")
+}
+
+// Query выполняет запрос и раскладывает data в out. Ошибки GraphQL возвращаются как GraphQLErrors
+func (s *GraphQLService) Query(ctx context.Context, query string, variables map[string]any, out any, opts ...RequestOption) error {
+	r := &request{
+		method: http.MethodPost,
+		path:   "/graphql",
+		body: map[string]any{
+			"query":     query,
+			"variables": variables,
+		},
+		// Запросы схемы только читают данные
+		idempotent: true,
+	}
+
+	resp, body, err := s.client.send(ctx, r.apply(opts))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return newAPIError(resp, body)
+	}
+
+	result := struct {
+		Data   json.RawMessage `json:"data"`
+		Errors GraphQLErrors   `json:"errors"`
+	}{}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("client: cannot decode graphql response: %w", err)
+	}
+
+	if out != nil && len(result.Data) > 0 && string(result.Data) != "null" {
+		if err := json.Unmarshal(result.Data, out); err != nil {
+			return fmt.Errorf("client: cannot decode graphql data: %w", err)
+		}
+	}
+
+	if len(result.Errors) > 0 {
+		return result.Errors
+	}
+
+	return nil
+}
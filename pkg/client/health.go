@@ -0,0 +1,49 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// HealthService — пробы /healthz и /readyz. Ответы проб без конверта data и без повторов:
+// проба показывает состояние экземпляра на момент запроса
+type HealthService struct {
+	client *Client
+}
+
+// Live проверяет, что процесс жив
+func (s *HealthService) Live(ctx context.Context, opts ...RequestOption) error {
+	r := &request{method: http.MethodGet, path: "/healthz", root: true}
+	_, err := s.probe(ctx, r.apply(opts))
+	return err
+}
+
+// Ready возвращает отчёт о зависимостях. Если экземпляр не готов, вместе с отчётом возвращается
+// *APIError со статусом 503, errors.Is(err, ErrServer) == true
+func (s *HealthService) Ready(ctx context.Context, opts ...RequestOption) (*HealthReport, error) {
+	r := &request{method: http.MethodGet, path: "/readyz", root: true}
+	return s.probe(ctx, r.apply(opts))
+}
+
+func (s *HealthService) probe(ctx context.Context, req *request) (*HealthReport, error) {
+	resp, body, err := s.client.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiErr error
+	if resp.StatusCode >= http.StatusBadRequest {
+		apiErr = newAPIError(resp, body)
+	}
+
+	out := &HealthReport{}
+	if err := json.Unmarshal(body, out); err != nil || out.Status == "" {
+		if apiErr != nil {
+			return nil, apiErr
+		}
+		return nil, fmt.Errorf("client: cannot decode response: %w", err)
+	}
+	return out, apiErr
+}
@@ -0,0 +1,36 @@
+package client
+
+import (
+	"context"
+	"iter"
+)
+
+const defaultPageSize = 100
+
+// paginate обходит все страницы списка, fetch возвращает элементы страницы и общее количество
+func paginate[T any](ctx context.Context, pageSize int, fetch func(ctx context.Context, offset, limit int) ([]T, int, error)) iter.Seq2[T, error] {
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+
+	return func(yield func(T, error) bool) {
+		for offset := 0; ; offset += pageSize {
+			items, total, err := fetch(ctx, offset, pageSize)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			if len(items) < pageSize || offset+len(items) >= total {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// LogLevelService — маршрут /admin/log-level, нужно разрешение admin:log-level.
+// Уровни возвращаются как имя приёмника → уровень
+type LogLevelService struct {
+	client *Client
+}
+
+func (s *LogLevelService) Get(ctx context.Context, opts ...RequestOption) (map[string]string, error) {
+	r := &request{method: http.MethodGet, path: "/admin/log-level", root: true, idempotent: true}
+
+	out := map[string]string{}
+	if _, err := s.client.do(ctx, r.apply(opts), &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Set меняет уровень приёмника sink, пустой sink — всех приёмников. Действует до перезапуска сервера
+func (s *LogLevelService) Set(ctx context.Context, sink, level string, opts ...RequestOption) (map[string]string, error) {
+	body := map[string]string{"sink": sink, "level": level}
+	r := &request{method: http.MethodPut, path: "/admin/log-level", root: true, body: body, idempotent: true}
+
+	out := map[string]string{}
+	if _, err := s.client.do(ctx, r.apply(opts), &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
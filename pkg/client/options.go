@@ -0,0 +1,43 @@
+package client
+
+import "net/http"
+
+// RequestOption меняет отдельный запрос
+type RequestOption func(*request)
+
+// WithIfMatch выполняет изменение, только если ETag подписки совпадает. Такой запрос безопасно повторять
+func WithIfMatch(etag string) RequestOption {
+	return func(r *request) {
+		r.setHeader("If-Match", etag)
+		r.idempotent = true
+	}
+}
+
+// WithIdempotencyKey задаёт свой ключ идемпотентности вместо сгенерированного
+func WithIdempotencyKey(key string) RequestOption {
+	return func(r *request) {
+		r.setHeader("Idempotency-Key", key)
+		r.idempotent = true
+	}
+}
+
+// WithHeader добавляет произвольный заголовок
+func WithHeader(name, value string) RequestOption {
+	return func(r *request) {
+		r.setHeader(name, value)
+	}
+}
+
+func (r *request) setHeader(name, value string) {
+	if r.header == nil {
+		r.header = http.Header{}
+	}
+	r.header.Set(name, value)
+}
+
+func (r *request) apply(opts []RequestOption) *request {
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
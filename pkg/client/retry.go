@@ -0,0 +1,78 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy — повторы запросов с экспоненциальной задержкой.
+// Повторяются сетевые ошибки и ответы 429, 502, 503, 504, но только для запросов,
+// которые безопасно выполнить дважды: GET, DELETE, запросы с If-Match или Idempotency-Key
+type RetryPolicy struct {
+	// MaxAttempts — общее число попыток, 0 или 1 отключают повторы
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 200 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+	}
+}
+
+var retryableStatuses = map[int]bool{
+	http.StatusTooManyRequests:    true,
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     true,
+}
+
+func (p RetryPolicy) shouldRetry(req *request, attempt int, resp *http.Response, err error) bool {
+	if attempt >= p.MaxAttempts || !req.idempotent {
+		return false
+	}
+
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+
+	return retryableStatuses[resp.StatusCode]
+}
+
+// wait ждёт перед следующей попыткой: Retry-After из ответа, иначе экспоненциальная задержка с разбросом
+func (p RetryPolicy) wait(ctx context.Context, attempt int, resp *http.Response) error {
+	delay := p.backoff(attempt)
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			delay = time.Duration(seconds) * time.Second
+		}
+	}
+	if p.MaxBackoff > 0 {
+		delay = min(delay, p.MaxBackoff)
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.InitialBackoff << (attempt - 1)
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
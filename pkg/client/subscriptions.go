@@ -0,0 +1,149 @@
+package client
+
+import (
+	"awesomeProject1/pkg/jsonPatch"
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SubscriptionsService — маршруты /subscription и /subscriptions
+type SubscriptionsService struct {
+	client *Client
+}
+
+// TotalQuery — параметры GET /subscriptions/total
+type TotalQuery struct {
+	Start       time.Time
+	End         time.Time
+	UserID      string
+	ServiceName string
+}
+
+// Create создаёт подписку. Запрос всегда уходит с Idempotency-Key, поэтому повтор после сетевой ошибки не создаст дубль
+func (s *SubscriptionsService) Create(ctx context.Context, req *CreateSubscriptionRequest, opts ...RequestOption) (*Subscription, error) {
+	r := &request{method: http.MethodPost, path: "/subscription", body: req}
+	r.setHeader("Idempotency-Key", uuid.NewString())
+	r.idempotent = true
+
+	out := &Subscription{}
+	if _, err := s.client.do(ctx, r.apply(opts), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *SubscriptionsService) Get(ctx context.Context, id uuid.UUID, opts ...RequestOption) (*Subscription, error) {
+	r := &request{method: http.MethodGet, path: "/subscription/" + pathEscape(id.String()), idempotent: true}
+
+	out := &Subscription{}
+	if _, err := s.client.do(ctx, r.apply(opts), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// List возвращает одну страницу подписок, filter может быть nil
+func (s *SubscriptionsService) List(ctx context.Context, filter *SubscriptionFilter, offset, limit int, opts ...RequestOption) (*SubscriptionList, error) {
+	query := url.Values{}
+	query.Set("offset", strconv.Itoa(offset))
+	query.Set("limit", strconv.Itoa(limit))
+	if filter != nil {
+		setIfNotEmpty(query, "user_id", filter.UserId)
+		setIfNotEmpty(query, "service_name", filter.ServiceName)
+	}
+
+	r := &request{method: http.MethodGet, path: "/subscriptions", query: query, idempotent: true}
+
+	out := &SubscriptionList{}
+	if _, err := s.client.do(ctx, r.apply(opts), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// All обходит все подписки постранично:
+//
+//	for sub, err := range c.Subscriptions.All(ctx, nil, 100) { ... }
+func (s *SubscriptionsService) All(ctx context.Context, filter *SubscriptionFilter, pageSize int) iter.Seq2[*Subscription, error] {
+	return paginate(ctx, pageSize, func(ctx context.Context, offset, limit int) ([]*Subscription, int, error) {
+		page, err := s.List(ctx, filter, offset, limit)
+		if err != nil {
+			return nil, 0, err
+		}
+		return page.Subscriptions, page.Total, nil
+	})
+}
+
+// Update — PATCH /subscription, меняет только переданные поля
+func (s *SubscriptionsService) Update(ctx context.Context, req *UpdateSubscriptionRequest, opts ...RequestOption) error {
+	r := &request{method: http.MethodPatch, path: "/subscription", body: req}
+	_, err := s.client.do(ctx, r.apply(opts), nil)
+	return err
+}
+
+// MergePatch применяет JSON Merge Patch (RFC 7386), patch сериализуется в JSON
+func (s *SubscriptionsService) MergePatch(ctx context.Context, id uuid.UUID, patch any, opts ...RequestOption) (*Subscription, error) {
+	return s.patch(ctx, id, jsonPatch.MergePatchMediaType, patch, opts)
+}
+
+// JSONPatch применяет операции JSON Patch (RFC 6902)
+func (s *SubscriptionsService) JSONPatch(ctx context.Context, id uuid.UUID, ops []jsonPatch.Operation, opts ...RequestOption) (*Subscription, error) {
+	return s.patch(ctx, id, jsonPatch.JSONPatchMediaType, ops, opts)
+}
+
+func (s *SubscriptionsService) patch(ctx context.Context, id uuid.UUID, contentType string, patch any, opts []RequestOption) (*Subscription, error) {
+	payload, err := json.Marshal(patch)
+	if err != nil {
+		return nil, fmt.Errorf("client: cannot encode patch: %w", err)
+	}
+
+	r := &request{
+		method:      http.MethodPatch,
+		path:        "/subscription/" + pathEscape(id.String()),
+		rawBody:     payload,
+		contentType: contentType,
+	}
+
+	out := &Subscription{}
+	if _, err := s.client.do(ctx, r.apply(opts), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *SubscriptionsService) Delete(ctx context.Context, id uuid.UUID, opts ...RequestOption) error {
+	r := &request{method: http.MethodDelete, path: "/subscription/" + pathEscape(id.String()), idempotent: true}
+	_, err := s.client.do(ctx, r.apply(opts), nil)
+	return err
+}
+
+// Total — суммарная стоимость подписок за период
+func (s *SubscriptionsService) Total(ctx context.Context, q TotalQuery, opts ...RequestOption) (int, error) {
+	query := url.Values{}
+	query.Set("start", q.Start.Format("01-2006"))
+	query.Set("end", q.End.Format("01-2006"))
+	setIfNotEmpty(query, "user_id", q.UserID)
+	setIfNotEmpty(query, "service_name", q.ServiceName)
+
+	r := &request{method: http.MethodGet, path: "/subscriptions/total", query: query, idempotent: true}
+
+	var total int
+	if _, err := s.client.do(ctx, r.apply(opts), &total); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func setIfNotEmpty(query url.Values, key, value string) {
+	if value != "" {
+		query.Set(key, value)
+	}
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// TenantsService — маршруты /admin/tenants, нужно разрешение admin:tenants
+type TenantsService struct {
+	client *Client
+}
+
+func (s *TenantsService) Create(ctx context.Context, req *CreateTenantRequest, opts ...RequestOption) (*Tenant, error) {
+	r := &request{method: http.MethodPost, path: "/admin/tenants", root: true, body: req}
+
+	out := &Tenant{}
+	if _, err := s.client.do(ctx, r.apply(opts), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *TenantsService) Get(ctx context.Context, id uuid.UUID, opts ...RequestOption) (*Tenant, error) {
+	r := &request{method: http.MethodGet, path: "/admin/tenants/" + pathEscape(id.String()), root: true, idempotent: true}
+
+	out := &Tenant{}
+	if _, err := s.client.do(ctx, r.apply(opts), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *TenantsService) List(ctx context.Context, offset, limit int, opts ...RequestOption) (*TenantList, error) {
+	r := &request{method: http.MethodGet, path: "/admin/tenants", root: true, query: pageQuery(offset, limit), idempotent: true}
+
+	out := &TenantList{}
+	if _, err := s.client.do(ctx, r.apply(opts), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *TenantsService) All(ctx context.Context, pageSize int) iter.Seq2[*Tenant, error] {
+	return paginate(ctx, pageSize, func(ctx context.Context, offset, limit int) ([]*Tenant, int, error) {
+		page, err := s.List(ctx, offset, limit)
+		if err != nil {
+			return nil, 0, err
+		}
+		return page.Tenants, page.Total, nil
+	})
+}
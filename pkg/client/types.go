@@ -0,0 +1,58 @@
+package client
+
+import (
+	"awesomeProject1/internal/dto"
+	"awesomeProject1/internal/health"
+	"awesomeProject1/pkg/httpHelpers"
+	"awesomeProject1/pkg/validator"
+)
+
+// Псевдонимы типов API, чтобы пользоваться ими за пределами модуля
+type (
+	Subscription              = dto.SubscriptionResponse
+	SubscriptionList          = dto.SubscriptionListResponse
+	CreateSubscriptionRequest = dto.CreateSubscriptionRequest
+	UpdateSubscriptionRequest = dto.UpdateSubscriptionRequest
+	SubscriptionFilter        = dto.SubscriptionFilter
+
+	Webhook              = dto.WebhookResponse
+	WebhookList          = dto.WebhookListResponse
+	CreateWebhookRequest = dto.CreateWebhookRequest
+	UpdateWebhookRequest = dto.UpdateWebhookRequest
+	WebhookDelivery      = dto.WebhookDeliveryResponse
+	WebhookDeliveryList  = dto.WebhookDeliveryListResponse
+	DeliveryStatus       = dto.DeliveryStatus
+
+	APIKeyInfo          = dto.APIKeyResponse
+	APIKeyList          = dto.APIKeyListResponse
+	CreateAPIKeyRequest = dto.CreateAPIKeyRequest
+
+	Tenant              = dto.TenantResponse
+	TenantList          = dto.TenantListResponse
+	CreateTenantRequest = dto.CreateTenantRequest
+
+	Event         = dto.Event
+	EventType     = dto.EventType
+	CalendarToken = dto.CalendarTokenResponse
+
+	HealthReport = health.Report
+	HealthCheck  = health.CheckResult
+
+	Problem    = httpHelpers.ErrorMessage
+	FieldError = validator.FieldError
+)
+
+const (
+	EventSubscriptionCreated = dto.EventSubscriptionCreated
+	EventSubscriptionUpdated = dto.EventSubscriptionUpdated
+	EventSubscriptionDeleted = dto.EventSubscriptionDeleted
+
+	DeliveryPending   = dto.DeliveryPending
+	DeliveryDelivered = dto.DeliveryDelivered
+	DeliveryDead      = dto.DeliveryDead
+
+	HealthOk       = health.StatusOk
+	HealthFail     = health.StatusFail
+	HealthDegraded = health.StatusDegraded
+	HealthDraining = health.StatusDraining
+)
@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// WebhooksService — маршруты /webhooks
+type WebhooksService struct {
+	client *Client
+}
+
+// Create регистрирует вебхук. Secret в ответе возвращается только здесь
+func (s *WebhooksService) Create(ctx context.Context, req *CreateWebhookRequest, opts ...RequestOption) (*Webhook, error) {
+	r := &request{method: http.MethodPost, path: "/webhooks", body: req}
+
+	out := &Webhook{}
+	if _, err := s.client.do(ctx, r.apply(opts), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *WebhooksService) Get(ctx context.Context, id uuid.UUID, opts ...RequestOption) (*Webhook, error) {
+	r := &request{method: http.MethodGet, path: "/webhooks/" + pathEscape(id.String()), idempotent: true}
+
+	out := &Webhook{}
+	if _, err := s.client.do(ctx, r.apply(opts), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *WebhooksService) List(ctx context.Context, offset, limit int, opts ...RequestOption) (*WebhookList, error) {
+	r := &request{method: http.MethodGet, path: "/webhooks", query: pageQuery(offset, limit), idempotent: true}
+
+	out := &WebhookList{}
+	if _, err := s.client.do(ctx, r.apply(opts), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *WebhooksService) All(ctx context.Context, pageSize int) iter.Seq2[*Webhook, error] {
+	return paginate(ctx, pageSize, func(ctx context.Context, offset, limit int) ([]*Webhook, int, error) {
+		page, err := s.List(ctx, offset, limit)
+		if err != nil {
+			return nil, 0, err
+		}
+		return page.Webhooks, page.Total, nil
+	})
+}
+
+func (s *WebhooksService) Update(ctx context.Context, id uuid.UUID, req *UpdateWebhookRequest, opts ...RequestOption) (*Webhook, error) {
+	r := &request{method: http.MethodPatch, path: "/webhooks/" + pathEscape(id.String()), body: req, idempotent: true}
+
+	out := &Webhook{}
+	if _, err := s.client.do(ctx, r.apply(opts), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *WebhooksService) Delete(ctx context.Context, id uuid.UUID, opts ...RequestOption) error {
+	r := &request{method: http.MethodDelete, path: "/webhooks/" + pathEscape(id.String()), idempotent: true}
+	_, err := s.client.do(ctx, r.apply(opts), nil)
+	return err
+}
+
+// Deliveries возвращает страницу журнала доставок, пустой status — все доставки
+func (s *WebhooksService) Deliveries(ctx context.Context, id uuid.UUID, status DeliveryStatus, offset, limit int, opts ...RequestOption) (*WebhookDeliveryList, error) {
+	query := pageQuery(offset, limit)
+	setIfNotEmpty(query, "status", string(status))
+
+	r := &request{method: http.MethodGet, path: "/webhooks/" + pathEscape(id.String()) + "/deliveries", query: query, idempotent: true}
+
+	out := &WebhookDeliveryList{}
+	if _, err := s.client.do(ctx, r.apply(opts), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *WebhooksService) AllDeliveries(ctx context.Context, id uuid.UUID, status DeliveryStatus, pageSize int) iter.Seq2[*WebhookDelivery, error] {
+	return paginate(ctx, pageSize, func(ctx context.Context, offset, limit int) ([]*WebhookDelivery, int, error) {
+		page, err := s.Deliveries(ctx, id, status, offset, limit)
+		if err != nil {
+			return nil, 0, err
+		}
+		return page.Deliveries, page.Total, nil
+	})
+}
+
+// RetryDelivery возвращает доставку из dead-letter в очередь
+func (s *WebhooksService) RetryDelivery(ctx context.Context, id, deliveryId uuid.UUID, opts ...RequestOption) error {
+	r := &request{
+		method:     http.MethodPost,
+		path:       "/webhooks/" + pathEscape(id.String()) + "/deliveries/" + pathEscape(deliveryId.String()) + "/retry",
+		idempotent: true,
+	}
+	_, err := s.client.do(ctx, r.apply(opts), nil)
+	return err
+}
+
+func pageQuery(offset, limit int) url.Values {
+	query := url.Values{}
+	query.Set("offset", strconv.Itoa(offset))
+	query.Set("limit", strconv.Itoa(limit))
+	return query
+}
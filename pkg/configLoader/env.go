@@ -0,0 +1,50 @@
+package configLoader
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvName возвращает имя переменной окружения для ключа: http.read_timeout -> APP_HTTP_READ_TIMEOUT
+func EnvName(path string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(path, ".", "_"))
+}
+
+// applyEnv переопределяет поля значениями переменных APP_*. Строки берутся как есть,
+// остальное разбирается как YAML: 15s, 100, true, [{type: stdout}].
+// problems — значения, которые не удалось разобрать, unknown — переменные APP_*, которым нет поля
+func applyEnv(target any, environ []string) (problems, unknown []string) {
+	known := make(map[string]field)
+	for _, f := range fields(reflect.ValueOf(target), "") {
+		known[EnvName(f.path)] = f
+	}
+
+	for _, pair := range environ {
+		name, value, _ := strings.Cut(pair, "=")
+		if !strings.HasPrefix(name, EnvPrefix) {
+			continue
+		}
+
+		f, ok := known[name]
+		if !ok {
+			unknown = append(unknown, fmt.Sprintf("%s does not match any config key", name))
+			continue
+		}
+
+		if f.value.Kind() == reflect.String {
+			f.value.SetString(value)
+			continue
+		}
+		if err := yaml.Unmarshal([]byte(value), f.value.Addr().Interface()); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: cannot parse %q as %s", name, value, f.value.Type()))
+		}
+	}
+
+	sort.Strings(problems)
+	sort.Strings(unknown)
+	return problems, unknown
+}
@@ -0,0 +1,181 @@
+package configLoader
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+type testSink struct {
+	Type  string `yaml:"type"`
+	Level string `yaml:"level"`
+}
+
+type testStorage struct {
+	ConnString string `yaml:"db_conn_string" secret:"true"`
+	MaxConns   int    `yaml:"max_conns"`
+}
+
+type TestCommon struct {
+	Name string `yaml:"name"`
+}
+
+type testConfig struct {
+	Common   TestCommon    `yaml:",inline"`
+	BindAddr string        `yaml:"bind_addr"`
+	Timeout  time.Duration `yaml:"timeout"`
+	Debug    bool          `yaml:"debug"`
+	Sinks    []testSink    `yaml:"sinks"`
+	Storage  testStorage   `yaml:"storage"`
+	Tracing  *testStorage  `yaml:"tracing"`
+	Internal string        `yaml:"-"`
+}
+
+func newTestConfig() *testConfig {
+	return &testConfig{
+		BindAddr: ":8080",
+		Timeout:  time.Second,
+		Storage:  testStorage{MaxConns: 10},
+		Tracing:  &testStorage{},
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	tests := map[string]string{
+		"bind_addr":              "APP_BIND_ADDR",
+		"http.read_timeout":      "APP_HTTP_READ_TIMEOUT",
+		"storage.db_conn_string": "APP_STORAGE_DB_CONN_STRING",
+	}
+	for path, want := range tests {
+		if got := EnvName(path); got != want {
+			t.Errorf("EnvName(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	tests := []struct {
+		name         string
+		environ      []string
+		want         func(c *testConfig)
+		wantProblems int
+		wantUnknown  []string
+	}{
+		{
+			name:    "string taken as is",
+			environ: []string{"APP_BIND_ADDR=:9090", "APP_STORAGE_DB_CONN_STRING=postgres://u:p@db:5432/app?sslmode=disable"},
+			want: func(c *testConfig) {
+				c.BindAddr = ":9090"
+				c.Storage.ConnString = "postgres://u:p@db:5432/app?sslmode=disable"
+			},
+		},
+		{
+			name:    "scalars parsed as yaml",
+			environ: []string{"APP_TIMEOUT=15s", "APP_DEBUG=true", "APP_STORAGE_MAX_CONNS=25"},
+			want: func(c *testConfig) {
+				c.Timeout = 15 * time.Second
+				c.Debug = true
+				c.Storage.MaxConns = 25
+			},
+		},
+		{
+			name:    "slice of structs",
+			environ: []string{"APP_SINKS=[{type: stdout, level: debug}, {type: file}]"},
+			want: func(c *testConfig) {
+				c.Sinks = []testSink{{Type: "stdout", Level: "debug"}, {Type: "file"}}
+			},
+		},
+		{
+			name:    "inline and pointer sections",
+			environ: []string{"APP_NAME=subscriptions", "APP_TRACING_MAX_CONNS=3"},
+			want: func(c *testConfig) {
+				c.Common.Name = "subscriptions"
+				c.Tracing.MaxConns = 3
+			},
+		},
+		{
+			name:    "other variables ignored",
+			environ: []string{"PATH=/usr/bin", "HOME=/root", "APPLICATION=x"},
+		},
+		{
+			name:         "unparsable values reported",
+			environ:      []string{"APP_TIMEOUT=soon", "APP_STORAGE_MAX_CONNS=many", "APP_BIND_ADDR=:9090"},
+			want:         func(c *testConfig) { c.BindAddr = ":9090" },
+			wantProblems: 2,
+		},
+		{
+			name:    "unknown and skipped keys reported",
+			environ: []string{"APP_TIMEOUTS=1s", "APP_INTERNAL=x", "APP_STORAGE=x", "APP_DEBUG=true"},
+			want:    func(c *testConfig) { c.Debug = true },
+			wantUnknown: []string{
+				"APP_INTERNAL does not match any config key",
+				"APP_STORAGE does not match any config key",
+				"APP_TIMEOUTS does not match any config key",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newTestConfig()
+			problems, unknown := applyEnv(got, tt.environ)
+
+			want := newTestConfig()
+			if tt.want != nil {
+				tt.want(want)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("config = %+v, want %+v", got, want)
+			}
+			if len(problems) != tt.wantProblems {
+				t.Errorf("problems = %q, want %d", problems, tt.wantProblems)
+			}
+			if !reflect.DeepEqual(unknown, tt.wantUnknown) {
+				t.Errorf("unknown = %q, want %q", unknown, tt.wantUnknown)
+			}
+		})
+	}
+}
+
+func TestLoadEnvOverridesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	file := "bind_addr: \":7070\"\ntimeout: 5s\nstorage:\n  max_conns: 20\n"
+	if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("APP_TIMEOUT", "30s")
+	t.Setenv("APP_UNKNOWN_KEY", "x")
+
+	config := newTestConfig()
+	warnings, err := Load(path, config)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if config.BindAddr != ":7070" || config.Storage.MaxConns != 20 {
+		t.Errorf("file values not applied: %+v", config)
+	}
+	if config.Timeout != 30*time.Second {
+		t.Errorf("timeout = %v, environment must override the file", config.Timeout)
+	}
+	if want := "APP_UNKNOWN_KEY does not match any config key"; !slices.Contains(warnings, want) {
+		t.Errorf("warnings = %q, want %q among them", warnings, want)
+	}
+}
+
+func TestLoadEnvProblems(t *testing.T) {
+	t.Setenv("APP_STORAGE_MAX_CONNS", "many")
+
+	_, err := Load("", newTestConfig())
+	var configErr *Error
+	if !errors.As(err, &configErr) {
+		t.Fatalf("Load() error = %v, want *Error", err)
+	}
+	if configErr.Source != "environment" || len(configErr.Problems) != 1 {
+		t.Errorf("error = %+v, want one problem from environment", configErr)
+	}
+}
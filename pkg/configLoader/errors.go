@@ -0,0 +1,39 @@
+package configLoader
+
+import (
+	"awesomeProject1/pkg/validator"
+	"fmt"
+	"strings"
+)
+
+// Error — все проблемы конфигурации из одного источника, чтобы их можно было исправить за один раз
+type Error struct {
+	// Source — файл, environment или validation
+	Source   string
+	Problems []string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("config %s: %d problem(s):\n  - %s", e.Source, len(e.Problems), strings.Join(e.Problems, "\n  - "))
+}
+
+// ValidationError собирает ошибки проверки значений, nil — ошибок нет
+func ValidationError(errors []validator.FieldError) error {
+	if len(errors) == 0 {
+		return nil
+	}
+
+	problems := make([]string, len(errors))
+	for i, fieldErr := range errors {
+		problems[i] = fieldErr.Field + ": " + fieldErr.Message
+	}
+	return &Error{Source: "validation", Problems: problems}
+}
+
+// Prefix добавляет путь секции к полям ошибок вложенной конфигурации: read_timeout -> http.read_timeout
+func Prefix(prefix string, errors []validator.FieldError) []validator.FieldError {
+	for i := range errors {
+		errors[i].Field = prefix + "." + errors[i].Field
+	}
+	return errors
+}
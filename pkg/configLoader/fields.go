@@ -0,0 +1,82 @@
+package configLoader
+
+import (
+	"reflect"
+	"strings"
+)
+
+// field — значение конфигурации, которое не раскладывается дальше на ключи
+type field struct {
+	// path — ключи через точку, как в файле: storage.db_conn_string
+	path   string
+	value  reflect.Value
+	secret bool
+}
+
+// fields обходит структуру по yaml тегам. Вложенные структуры и указатели на них раскладываются на ключи,
+// inline структуры остаются на уровне родителя, всё остальное — поле
+func fields(v reflect.Value, prefix string) []field {
+	var result []field
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+
+		name, inline, skip := yamlKey(structField)
+		if skip {
+			continue
+		}
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+
+		value := v.Field(i)
+		switch {
+		case inline:
+			result = append(result, fields(value, prefix)...)
+		case isSection(structField.Type):
+			result = append(result, fields(value, path)...)
+		default:
+			result = append(result, field{path: path, value: value, secret: structField.Tag.Get("secret") == "true"})
+		}
+	}
+	return result
+}
+
+// yamlKey повторяет правила yaml.v3: ключ из тега, без тега — имя поля в нижнем регистре
+func yamlKey(structField reflect.StructField) (name string, inline bool, skip bool) {
+	tag := structField.Tag.Get("yaml")
+	if tag == "-" {
+		return "", false, true
+	}
+
+	parts := strings.Split(tag, ",")
+	for _, option := range parts[1:] {
+		if option == "inline" {
+			inline = true
+		}
+	}
+
+	name = parts[0]
+	if name == "" {
+		name = strings.ToLower(structField.Name)
+	}
+	return name, inline, false
+}
+
+func isSection(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t.PkgPath() != "time"
+}
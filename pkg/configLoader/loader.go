@@ -0,0 +1,99 @@
+package configLoader
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix — префикс переменных окружения, которые переопределяют значения из файла:
+// storage.db_conn_string задаётся через APP_STORAGE_DB_CONN_STRING
+const EnvPrefix = "APP_"
+
+// Load заполняет target в порядке: значения по умолчанию, которые уже лежат в target, файл path,
+// переменные APP_*. Формат файла определяется по расширению: .yaml, .yml или .toml.
+// Неизвестные ключи файла считаются ошибкой. Переменные APP_*, которым нет поля, возвращаются
+// предупреждениями: в окружении контейнера бывают чужие переменные с тем же префиксом.
+// Пустой path — только переменные окружения
+func Load(path string, target any) (warnings []string, err error) {
+	if path != "" {
+		if err := LoadFile(path, target); err != nil {
+			return nil, err
+		}
+	}
+
+	problems, unknown := applyEnv(target, os.Environ())
+	if len(problems) > 0 {
+		return unknown, &Error{Source: "environment", Problems: problems}
+	}
+	return unknown, nil
+}
+
+// LoadFile заполняет target только из файла path, без переменных APP_*, например для отдельных файлов
+// рядом с конфигурацией. Формат и строгость проверки ключей такие же, как в Load
+func LoadFile(path string, target any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = decodeYAML(data, target)
+	case ".toml":
+		err = decodeTOML(data, target)
+	default:
+		return fmt.Errorf("config %s: unsupported file extension, expected .yaml, .yml or .toml", path)
+	}
+
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		return &Error{Source: path, Problems: typeErr.Errors}
+	}
+	if err != nil {
+		return fmt.Errorf("config %s: %w", path, err)
+	}
+	return nil
+}
+
+func decodeYAML(data []byte, target any) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(target); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+var yamlLinePrefix = regexp.MustCompile(`^line \d+: `)
+
+// decodeTOML переводит TOML в YAML, чтобы ключи описывались одними yaml тегами и проверялись так же строго.
+// Номера строк после перевода не совпадают с исходным файлом, поэтому убираются из ошибок
+func decodeTOML(data []byte, target any) error {
+	var values map[string]any
+	if err := toml.Unmarshal(data, &values); err != nil {
+		return err
+	}
+
+	converted, err := yaml.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	err = decodeYAML(converted, target)
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		for i, problem := range typeErr.Errors {
+			typeErr.Errors[i] = yamlLinePrefix.ReplaceAllString(problem, "")
+		}
+	}
+	return err
+}
@@ -0,0 +1,45 @@
+package configLoader
+
+import (
+	"reflect"
+	"sync/atomic"
+)
+
+// Snapshot — текущая конфигурация, которую можно атомарно заменить при перезагрузке.
+// Потребители читают её через Load на каждый запрос и не держат у себя копий
+type Snapshot[T any] struct {
+	current atomic.Pointer[T]
+}
+
+func NewSnapshot[T any](value *T) *Snapshot[T] {
+	snapshot := &Snapshot[T]{}
+	snapshot.current.Store(value)
+	return snapshot
+}
+
+func (s *Snapshot[T]) Load() *T {
+	return s.current.Load()
+}
+
+// Store заменяет конфигурацию. Сохранённое значение больше нельзя менять, только заменить целиком
+func (s *Snapshot[T]) Store(value *T) {
+	s.current.Store(value)
+}
+
+// Changed возвращает ключи, значения которых в a и b различаются
+func Changed(a, b any) []string {
+	before := fields(reflect.ValueOf(a), "")
+	after := make(map[string]field)
+	for _, f := range fields(reflect.ValueOf(b), "") {
+		after[f.path] = f
+	}
+
+	var changed []string
+	for _, f := range before {
+		other, ok := after[f.path]
+		if !ok || !reflect.DeepEqual(f.value.Interface(), other.value.Interface()) {
+			changed = append(changed, f.path)
+		}
+	}
+	return changed
+}
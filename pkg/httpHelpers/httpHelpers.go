@@ -1,40 +1,65 @@
 package httpHelpers
 
 import (
+	"awesomeProject1/pkg/logger"
+	"awesomeProject1/pkg/validator"
 	"encoding/json"
 	"github.com/google/uuid"
+	"github.com/gorilla/mux"
 	"net/http"
+	"strings"
 	"time"
 )
 
 const (
-	Error500          = "Something went wrong, try later..."
-	ErrorParse        = "Cant parse data, please check provided data"
-	ErrorNotFoundById = "Nothing found, please check the provided id"
+	Error500        = "Something went wrong, try later..."
+	ErrorParse      = "Cant parse data, please check provided data"
+	ErrorValidation = "Request contains invalid fields, see errors for details"
+)
+
+// Типы проблем RFC 7807
+const (
+	ProblemContentType    = "application/problem+json"
+	ProblemTypeDefault    = "about:blank"
+	ProblemTypeValidation = "/problems/validation-error"
 )
 
 type ServiceError struct {
 	Code    int
 	Message string
 	Err     error
+	// Errors — ошибки валидации отдельных полей
+	Errors []validator.FieldError
 }
 
 func NewServiceError(code int, message string) *ServiceError {
 	return &ServiceError{Code: code, Message: message}
 }
 
+// NewValidationServiceError создаёт ошибку сервиса с подробностями по полям
+func NewValidationServiceError(errors []validator.FieldError) *ServiceError {
+	return &ServiceError{Code: http.StatusBadRequest, Message: ErrorValidation, Errors: errors}
+}
+
 type SuccessMessage struct {
 	Status  int  `json:"status"`
 	Data    any  `json:"data,omitempty"`
 	Success bool `json:"success"`
 }
 
+// ErrorMessage — ответ с ошибкой в формате RFC 7807 (application/problem+json).
+// Поля error, success, id и time сохранены для совместимости с существующими клиентами
 type ErrorMessage struct {
-	Status  int       `json:"status"`
-	Error   string    `json:"error"`
-	Success bool      `json:"success"`
-	Id      uuid.UUID `json:"id"`
-	Time    time.Time `json:"time"`
+	Type     string                 `json:"type" example:"about:blank"`
+	Title    string                 `json:"title" example:"Bad Request"`
+	Status   int                    `json:"status" example:"400"`
+	Detail   string                 `json:"detail,omitempty"`
+	Instance string                 `json:"instance,omitempty" example:"/api/v1/subscription"`
+	Errors   []validator.FieldError `json:"errors,omitempty"`
+	Error    string                 `json:"error"`
+	Success  bool                   `json:"success"`
+	Id       uuid.UUID              `json:"id"`
+	Time     time.Time              `json:"time"`
 }
 
 func NewSuccessMessage(status int, data any) *SuccessMessage {
@@ -47,7 +72,10 @@ func NewSuccessMessage(status int, data any) *SuccessMessage {
 
 func NewErrorMessage(error string, status int) *ErrorMessage {
 	return &ErrorMessage{
+		Type:    ProblemTypeDefault,
+		Title:   http.StatusText(status),
 		Status:  status,
+		Detail:  error,
 		Error:   error,
 		Success: false,
 		Id:      uuid.New(),
@@ -61,8 +89,102 @@ func RespondSuccess(w http.ResponseWriter, status int, data interface{}) {
 	json.NewEncoder(w).Encode(NewSuccessMessage(status, data))
 }
 
-func RespondError(w http.ResponseWriter, status int, error string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(NewErrorMessage(error, status))
+func RespondError(w http.ResponseWriter, r *http.Request, status int, error string) {
+	respondProblem(w, r, NewErrorMessage(error, status), nil)
+}
+
+// RespondValidationError отвечает 400 со списком ошибок по полям
+func RespondValidationError(w http.ResponseWriter, r *http.Request, errors []validator.FieldError) {
+	RespondServiceError(w, r, NewValidationServiceError(errors))
+}
+
+// RespondServiceError отвечает ошибкой, полученной из слоя сервисов
+func RespondServiceError(w http.ResponseWriter, r *http.Request, sErr *ServiceError) {
+	message := NewErrorMessage(sErr.Message, sErr.Code)
+	if len(sErr.Errors) > 0 {
+		message.Type = ProblemTypeValidation
+		message.Title = "Validation failed"
+		message.Errors = sErr.Errors
+	}
+	respondProblem(w, r, message, sErr.Err)
+}
+
+// respondProblem пишет ошибку в лог запроса и отправляет её клиенту. id ошибки совпадает с X-Request-ID,
+// поэтому по нему находятся все записи лога этого запроса
+func respondProblem(w http.ResponseWriter, r *http.Request, message *ErrorMessage, cause error) {
+	message.Instance = r.URL.RequestURI()
+
+	entry := logger.FromContext(r.Context())
+	if id := RequestID(r.Context()); id != uuid.Nil {
+		message.Id = id
+	} else {
+		// Запрос прошёл мимо middleware RequestID, id ошибки записывается в лог отдельно
+		entry = entry.WithFields(logger.Fields{"request_id": message.Id.String()})
+	}
+
+	fields := logger.Fields{
+		"status":   message.Status,
+		"instance": message.Instance,
+	}
+	if len(message.Errors) > 0 {
+		codes := make([]string, len(message.Errors))
+		for i, fieldErr := range message.Errors {
+			codes[i] = fieldErr.Field + ": " + fieldErr.Code
+		}
+		fields["fields"] = strings.Join(codes, ", ")
+	}
+	entry = entry.WithFields(fields)
+	if cause != nil {
+		entry = entry.WithError(cause)
+	}
+	if message.Status >= http.StatusInternalServerError {
+		entry.Error("ErrorResponse -> " + message.Detail)
+	} else {
+		entry.Warn("ErrorResponse -> " + message.Detail)
+	}
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(message.Status)
+	json.NewEncoder(w).Encode(message)
+}
+
+// ParseETags разбирает значение заголовков If-Match / If-None-Match в список ETag
+func ParseETags(header string) []string {
+	var tags []string
+	for _, tag := range strings.Split(header, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// MatchETag проверяет, совпадает ли etag с одним из тегов списка.
+// При weak = false используется строгое сравнение (If-Match), иначе слабое (If-None-Match)
+func MatchETag(tags []string, etag string, weak bool) bool {
+	for _, tag := range tags {
+		if tag == "*" {
+			return true
+		}
+		if weak {
+			if strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+			continue
+		}
+		if !strings.HasPrefix(tag, "W/") && tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// RouteTemplate возвращает шаблон маршрута mux (/api/v1/subscription/{id}), ok = false, если маршрут не найден
+func RouteTemplate(r *http.Request) (string, bool) {
+	if route := mux.CurrentRoute(r); route != nil {
+		if template, err := route.GetPathTemplate(); err == nil {
+			return template, true
+		}
+	}
+	return "", false
 }
@@ -0,0 +1,21 @@
+package httpHelpers
+
+import (
+	"context"
+	"github.com/google/uuid"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// WithRequestID сохраняет id запроса в ctx
+func WithRequestID(ctx context.Context, id uuid.UUID) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID возвращает id запроса или uuid.Nil, если запрос прошёл мимо middleware
+func RequestID(ctx context.Context) uuid.UUID {
+	id, _ := ctx.Value(requestIDKey{}).(uuid.UUID)
+	return id
+}
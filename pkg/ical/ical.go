@@ -0,0 +1,100 @@
+// Package ical формирует календари в формате iCalendar (RFC 5545)
+package ical
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	ContentType = "text/calendar; charset=utf-8"
+
+	dateFormat     = "20060102"
+	dateTimeFormat = "20060102T150405Z"
+	maxLineOctets  = 75
+)
+
+// Calendar — VCALENDAR с набором событий
+type Calendar struct {
+	ProdID string
+	Name   string
+	Events []*Event
+}
+
+// Event — VEVENT на целый день. UID должен быть стабильным, чтобы календарь заменял событие, а не дублировал его
+type Event struct {
+	UID         string
+	Date        time.Time
+	Summary     string
+	Description string
+	// Stamp — время последнего изменения данных события, попадает в DTSTAMP
+	Stamp time.Time
+	// Sequence увеличивается при каждом изменении события
+	Sequence int
+}
+
+// Encode сериализует календарь: строки через CRLF, длинные строки переносятся по 75 октетов
+func (c *Calendar) Encode() []byte {
+	buf := &bytes.Buffer{}
+
+	writeLine(buf, "BEGIN:VCALENDAR")
+	writeLine(buf, "VERSION:2.0")
+	writeLine(buf, "PRODID:"+escapeText(c.ProdID))
+	writeLine(buf, "CALSCALE:GREGORIAN")
+	writeLine(buf, "METHOD:PUBLISH")
+	if c.Name != "" {
+		writeLine(buf, "X-WR-CALNAME:"+escapeText(c.Name))
+	}
+
+	for _, event := range c.Events {
+		writeLine(buf, "BEGIN:VEVENT")
+		writeLine(buf, "UID:"+escapeText(event.UID))
+		writeLine(buf, "DTSTAMP:"+event.Stamp.UTC().Format(dateTimeFormat))
+		writeLine(buf, "DTSTART;VALUE=DATE:"+event.Date.Format(dateFormat))
+		writeLine(buf, "DTEND;VALUE=DATE:"+event.Date.AddDate(0, 0, 1).Format(dateFormat))
+		writeLine(buf, "SUMMARY:"+escapeText(event.Summary))
+		if event.Description != "" {
+			writeLine(buf, "DESCRIPTION:"+escapeText(event.Description))
+		}
+		writeLine(buf, "SEQUENCE:"+strconv.Itoa(event.Sequence))
+		writeLine(buf, "TRANSP:TRANSPARENT")
+		writeLine(buf, "END:VEVENT")
+	}
+
+	writeLine(buf, "END:VCALENDAR")
+	return buf.Bytes()
+}
+
+// writeLine пишет строку с переносом (folding): продолжение начинается с пробела,
+// многобайтовые символы UTF-8 не разрываются
+func writeLine(buf *bytes.Buffer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+		// Пробел в начале строки продолжения тоже занимает октет
+		limit = maxLineOctets - 1
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
+
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+)
+
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
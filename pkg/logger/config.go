@@ -0,0 +1,104 @@
+package logger
+
+import (
+	"awesomeProject1/pkg/validator"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+const (
+	SinkStdout = "stdout"
+	SinkStderr = "stderr"
+	SinkFile   = "file"
+	SinkSyslog = "syslog"
+
+	defaultFileName = "log.txt"
+)
+
+// Config — настройки логгера. Level и Format действуют на приёмники, у которых они не заданы.
+// Без приёмников лог пишется в <Dir>/log.txt, а если Dir не задан — в stdout
+type Config struct {
+	Level  string       `yaml:"log_level"`
+	Format string       `yaml:"log_format"`
+	Dir    string       `yaml:"log_dir"`
+	Sinks  []SinkConfig `yaml:"log_sinks"`
+}
+
+// SinkConfig — приёмник лога со своим форматом и уровнем
+type SinkConfig struct {
+	// Name — имя для смены уровня через /admin/log-level, по умолчанию совпадает с Type
+	Name   string `yaml:"name,omitempty"`
+	Type   string `yaml:"type"`
+	Level  string `yaml:"level,omitempty"`
+	Format string `yaml:"format,omitempty"`
+
+	// Path — файл приёмника file, по умолчанию <log_dir>/log.txt
+	Path string `yaml:"path,omitempty"`
+	// MaxSizeMB — размер файла, после которого он переименовывается и лог начинается заново
+	MaxSizeMB int `yaml:"max_size_mb,omitempty"`
+	// RotateEvery — ротация по времени, 0 — только по размеру
+	RotateEvery time.Duration `yaml:"rotate_every,omitempty"`
+	// MaxAgeDays и MaxBackups — сколько хранить старые файлы, 0 — без ограничения
+	MaxAgeDays int  `yaml:"max_age_days,omitempty"`
+	MaxBackups int  `yaml:"max_backups,omitempty"`
+	Compress   bool `yaml:"compress,omitempty"`
+
+	// Address — сокет syslog, пустое значение — локальный /dev/log
+	Network string `yaml:"network,omitempty"`
+	Address string `yaml:"address,omitempty"`
+	Tag     string `yaml:"tag,omitempty"`
+}
+
+// sinks возвращает приёмники из конфигурации, а если их нет — приёмник по умолчанию
+func (c *Config) sinks() []SinkConfig {
+	if len(c.Sinks) > 0 {
+		return c.Sinks
+	}
+	if c.Dir != "" {
+		return []SinkConfig{{Type: SinkFile}}
+	}
+	return []SinkConfig{{Type: SinkStdout}}
+}
+
+// Validate проверяет уровни, форматы и приёмники, имена полей — ключи файла конфигурации
+func (c *Config) Validate() []validator.FieldError {
+	v := validator.New()
+	if _, err := logrus.ParseLevel(c.Level); err != nil {
+		v.AddError("log_level", validator.CodeInvalidFormat, err.Error())
+	}
+	if _, err := newFormatter(c.Format); err != nil {
+		v.AddError("log_format", validator.CodeInvalidFormat, err.Error())
+	}
+
+	names := make(map[string]bool, len(c.Sinks))
+	for i, sink := range c.Sinks {
+		field := fmt.Sprintf("log_sinks[%d]", i)
+		switch sink.Type {
+		case SinkStdout, SinkStderr, SinkFile, SinkSyslog:
+		default:
+			v.AddError(field+".type", validator.CodeInvalidFormat, "must be one of stdout, stderr, file, syslog")
+		}
+		if sink.Level != "" {
+			if _, err := logrus.ParseLevel(sink.Level); err != nil {
+				v.AddError(field+".level", validator.CodeInvalidFormat, err.Error())
+			}
+		}
+		if _, err := newFormatter(sink.Format); err != nil {
+			v.AddError(field+".format", validator.CodeInvalidFormat, err.Error())
+		}
+		if sink.Type == SinkFile && sink.Path == "" && c.Dir == "" {
+			v.AddError(field+".path", validator.CodeRequired, "is required when log_dir is empty")
+		}
+		if sink.MaxSizeMB < 0 || sink.MaxAgeDays < 0 || sink.MaxBackups < 0 || sink.RotateEvery < 0 {
+			v.AddError(field, validator.CodeTooSmall, "rotation limits must not be negative")
+		}
+
+		name := sinkName(sink)
+		if names[name] {
+			v.AddError(field+".name", validator.CodeInvalidFormat, fmt.Sprintf("sink name %q is used twice, set name explicitly", name))
+		}
+		names[name] = true
+	}
+	return v.GetErrors()
+}
@@ -0,0 +1,23 @@
+package logger
+
+import "context"
+
+type contextKey struct{}
+
+// WithContext сохраняет логгер запроса в ctx
+func WithContext(ctx context.Context, entry *Entry) context.Context {
+	return context.WithValue(ctx, contextKey{}, entry)
+}
+
+// FromContext возвращает логгер запроса, а вне запроса — общий логгер без полей
+func FromContext(ctx context.Context) *Entry {
+	if entry, ok := ctx.Value(contextKey{}).(*Entry); ok {
+		return entry
+	}
+	return Log.WithFields(nil)
+}
+
+// WithFields добавляет поля к логгеру запроса, например user_id после аутентификации
+func WithFields(ctx context.Context, fields Fields) context.Context {
+	return WithContext(ctx, FromContext(ctx).WithFields(fields))
+}
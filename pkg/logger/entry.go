@@ -0,0 +1,30 @@
+package logger
+
+import "github.com/sirupsen/logrus"
+
+// Entry — логгер с набором полей, например логгер запроса с request_id и route
+type Entry struct {
+	entry *logrus.Entry
+}
+
+func (e *Entry) Info(args ...interface{}) {
+	e.entry.Infoln(args...)
+}
+
+func (e *Entry) Warn(args ...interface{}) {
+	e.entry.Warnln(args...)
+}
+
+func (e *Entry) Error(args ...interface{}) {
+	e.entry.Errorln(args...)
+}
+
+// WithError возвращает логгер с ошибкой в поле error
+func (e *Entry) WithError(err error) *Entry {
+	return &Entry{entry: e.entry.WithError(err)}
+}
+
+// WithFields возвращает логгер с полями e и fields
+func (e *Entry) WithFields(fields Fields) *Entry {
+	return &Entry{entry: e.entry.WithFields(fields)}
+}
@@ -1,19 +1,28 @@
 package logger
 
 import (
+	"errors"
+	"fmt"
 	"github.com/sirupsen/logrus"
-	"log"
-	"os"
-	"path/filepath"
+	"io"
+	"time"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
 )
 
 var (
 	Log *Logger
 )
 
+// Fields — поля структурированной записи лога
+type Fields = logrus.Fields
+
 type Logger struct {
 	logger *logrus.Logger
-	file   *os.File
+	sinks  []*sink
 }
 
 func (l *Logger) Info(args ...interface{}) {
@@ -27,42 +36,143 @@ func (l *Logger) Error(args ...interface{}) {
 	l.logger.Errorln(args...)
 }
 
-func (l *Logger) Close() {
-	if l.file != nil {
-		err := l.file.Close()
-		log.Fatal(err)
-	}
+// WithFields возвращает логгер, который добавляет fields к каждой записи
+func (l *Logger) WithFields(fields Fields) *Entry {
+	return &Entry{entry: l.logger.WithFields(fields)}
 }
 
-func newLogger() *Logger {
-	return &Logger{
-		logger: logrus.New(),
+// Levels возвращает текущие уровни приёмников по имени
+func (l *Logger) Levels() map[string]string {
+	levels := make(map[string]string, len(l.sinks))
+	for _, s := range l.sinks {
+		levels[s.name] = s.Level().String()
 	}
+	return levels
 }
 
-func Init(level string, logDir string) error {
-
+// SetLevel меняет уровень приёмника name, а при пустом name — всех приёмников
+func (l *Logger) SetLevel(name string, level string) error {
 	parsedLevel, err := logrus.ParseLevel(level)
 	if err != nil {
 		return err
 	}
 
-	Log = newLogger()
-	Log.logger.SetLevel(parsedLevel)
-	Log.logger.SetFormatter(&logrus.TextFormatter{})
+	found := false
+	for _, s := range l.sinks {
+		if name == "" || s.name == name {
+			s.SetLevel(parsedLevel)
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("unknown log sink %q", name)
+	}
 
-	err = os.MkdirAll(logDir, 0755)
-	if err != nil {
-		return err
+	l.updateLevel()
+	return nil
+}
+
+// updateLevel выставляет logrus самый подробный уровень среди приёмников, остальное они отсекают сами
+func (l *Logger) updateLevel() {
+	level := logrus.PanicLevel
+	for _, s := range l.sinks {
+		level = max(level, s.Level())
 	}
+	l.logger.SetLevel(level)
+}
 
-	file, err := os.OpenFile(filepath.Join(logDir, "log.txt"), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
-	if err != nil {
-		return err
+// Close сбрасывает лог на диск и закрывает файлы и соединения приёмников
+func (l *Logger) Close() error {
+	var errs []error
+	for _, s := range l.sinks {
+		if err := s.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("log sink %s: %w", s.name, err))
+		}
 	}
+	return errors.Join(errs...)
+}
 
-	Log.file = file
-	Log.logger.SetOutput(file)
+func newLogger() *Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	logger.SetFormatter(discardFormatter{})
+	return &Logger{
+		logger: logger,
+	}
+}
+
+// ApplyLevels выставляет приёмникам уровни из config, например после перезагрузки конфигурации.
+// Приёмники, которых нет в config, не меняются. Уровни, заданные через /admin/log-level, перезаписываются
+func (l *Logger) ApplyLevels(config *Config) error {
+	for _, sinkConfig := range config.sinks() {
+		levelName := sinkConfig.Level
+		if levelName == "" {
+			levelName = config.Level
+		}
+		level, err := logrus.ParseLevel(levelName)
+		if err != nil {
+			return fmt.Errorf("log sink %s: %w", sinkName(sinkConfig), err)
+		}
+
+		for _, s := range l.sinks {
+			if s.name == sinkName(sinkConfig) {
+				s.SetLevel(level)
+			}
+		}
+	}
+
+	l.updateLevel()
+	return nil
+}
+
+// Init создаёт приёмники из config. Без приёмников в конфигурации лог пишется в файл в log_dir,
+// а если log_dir не задан — в stdout
+func Init(config *Config) error {
+	sinks := config.sinks()
+
+	log := newLogger()
+	names := make(map[string]bool, len(sinks))
+	for _, sinkConfig := range sinks {
+		s, err := newSink(sinkConfig, config)
+		if err == nil && names[s.name] {
+			s.Close()
+			err = errors.New("duplicate name, set name explicitly")
+		}
+		if err != nil {
+			log.Close()
+			return fmt.Errorf("log sink %s: %w", sinkName(sinkConfig), err)
+		}
+
+		names[s.name] = true
+		log.sinks = append(log.sinks, s)
+		log.logger.AddHook(s)
+	}
+	log.updateLevel()
 
+	Log = log
 	return nil
 }
+
+func sinkName(config SinkConfig) string {
+	if config.Name != "" {
+		return config.Name
+	}
+	return config.Type
+}
+
+// discardFormatter — формат основного вывода logrus, который не используется: записи форматируют приёмники
+type discardFormatter struct{}
+
+func (discardFormatter) Format(*logrus.Entry) ([]byte, error) {
+	return nil, nil
+}
+
+func newFormatter(format string) (logrus.Formatter, error) {
+	switch format {
+	case "", FormatText:
+		return &logrus.TextFormatter{DisableColors: true, FullTimestamp: true}, nil
+	case FormatJSON:
+		return &logrus.JSONFormatter{TimestampFormat: time.RFC3339Nano, DisableHTMLEscape: true}, nil
+	}
+	return nil, fmt.Errorf("unknown log format %q", format)
+}
@@ -0,0 +1,162 @@
+package logger
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/natefinch/lumberjack.v2"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// sink — приёмник лога, подключается к logrus как hook, чтобы у каждого были свой формат и уровень
+type sink struct {
+	name      string
+	level     atomic.Uint32
+	formatter logrus.Formatter
+	mu        sync.Mutex
+	writer    io.Writer
+	closer    io.Closer
+}
+
+// levelWriter — приёмник, которому нужен уровень записи, например syslog
+type levelWriter interface {
+	WriteLevel(level logrus.Level, p []byte) error
+}
+
+func (s *sink) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (s *sink) Fire(entry *logrus.Entry) error {
+	if entry.Level > s.Level() {
+		return nil
+	}
+
+	line, err := s.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if writer, ok := s.writer.(levelWriter); ok {
+		return writer.WriteLevel(entry.Level, line)
+	}
+	_, err = s.writer.Write(line)
+	return err
+}
+
+func (s *sink) Level() logrus.Level {
+	return logrus.Level(s.level.Load())
+}
+
+func (s *sink) SetLevel(level logrus.Level) {
+	s.level.Store(uint32(level))
+}
+
+func (s *sink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closer.Close()
+}
+
+func newSink(config SinkConfig, defaults *Config) (*sink, error) {
+	levelName := config.Level
+	if levelName == "" {
+		levelName = defaults.Level
+	}
+	level, err := logrus.ParseLevel(levelName)
+	if err != nil {
+		return nil, err
+	}
+
+	format := config.Format
+	if format == "" {
+		format = defaults.Format
+	}
+	formatter, err := newFormatter(format)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &sink{name: sinkName(config), formatter: formatter}
+	s.SetLevel(level)
+
+	switch config.Type {
+	case SinkStdout:
+		s.writer = os.Stdout
+	case SinkStderr:
+		s.writer = os.Stderr
+	case SinkFile:
+		writer, err := newFileWriter(config, defaults.Dir)
+		if err != nil {
+			return nil, err
+		}
+		s.writer, s.closer = writer, writer
+	case SinkSyslog:
+		writer, err := newSyslogWriter(config)
+		if err != nil {
+			return nil, err
+		}
+		s.writer, s.closer = writer, writer
+	default:
+		return nil, fmt.Errorf("unknown log sink type %q", config.Type)
+	}
+
+	return s, nil
+}
+
+// rotatingFile — файл с ротацией по размеру (lumberjack) и, если задан RotateEvery, по времени
+type rotatingFile struct {
+	*lumberjack.Logger
+	rotateEvery time.Duration
+	rotatedAt   time.Time
+}
+
+func newFileWriter(config SinkConfig, dir string) (*rotatingFile, error) {
+	path := config.Path
+	if path == "" {
+		path = filepath.Join(dir, defaultFileName)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	// lumberjack открывает файл при первой записи, поэтому права на запись проверяются сразу
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	file.Close()
+
+	return &rotatingFile{
+		Logger: &lumberjack.Logger{
+			Filename:   path,
+			MaxSize:    config.MaxSizeMB,
+			MaxAge:     config.MaxAgeDays,
+			MaxBackups: config.MaxBackups,
+			Compress:   config.Compress,
+			LocalTime:  true,
+		},
+		rotateEvery: config.RotateEvery,
+		rotatedAt:   time.Now(),
+	}, nil
+}
+
+// Write вызывается под мьютексом приёмника
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	if f.rotateEvery > 0 && time.Since(f.rotatedAt) >= f.rotateEvery {
+		f.rotatedAt = time.Now()
+		if err := f.Rotate(); err != nil {
+			return 0, err
+		}
+	}
+	return f.Logger.Write(p)
+}
@@ -0,0 +1,42 @@
+//go:build !windows && !plan9
+
+package logger
+
+import (
+	"github.com/sirupsen/logrus"
+	"io"
+	"log/syslog"
+)
+
+// syslogWriter отправляет записи с приоритетом по уровню записи
+type syslogWriter struct {
+	*syslog.Writer
+}
+
+func newSyslogWriter(config SinkConfig) (io.WriteCloser, error) {
+	tag := config.Tag
+	if tag == "" {
+		tag = "subscriptions"
+	}
+	writer, err := syslog.Dial(config.Network, config.Address, syslog.LOG_INFO|syslog.LOG_DAEMON, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &syslogWriter{Writer: writer}, nil
+}
+
+func (w *syslogWriter) WriteLevel(level logrus.Level, p []byte) error {
+	message := string(p)
+	switch level {
+	case logrus.PanicLevel, logrus.FatalLevel:
+		return w.Crit(message)
+	case logrus.ErrorLevel:
+		return w.Err(message)
+	case logrus.WarnLevel:
+		return w.Warning(message)
+	case logrus.InfoLevel:
+		return w.Info(message)
+	default:
+		return w.Debug(message)
+	}
+}
@@ -0,0 +1,12 @@
+//go:build windows || plan9
+
+package logger
+
+import (
+	"errors"
+	"io"
+)
+
+func newSyslogWriter(SinkConfig) (io.WriteCloser, error) {
+	return nil, errors.New("syslog log sink is not supported on this platform")
+}
@@ -7,10 +7,13 @@ import (
 )
 
 type QueryBuilder struct {
-	setParts     []string
-	values       []interface{}
-	paramNum     int
-	withNilCheck bool
+	setParts       []string
+	incrementParts []string
+	values         []interface{}
+	whereFields    []string
+	whereValues    []interface{}
+	paramNum       int
+	withNilCheck   bool
 }
 
 func NewQueryBuilder(withNilCheck bool) *QueryBuilder {
@@ -30,17 +33,43 @@ func (qb *QueryBuilder) Set(field string, value any) *QueryBuilder {
 	return qb
 }
 
+// Increment добавляет в SET увеличение числового поля на единицу.
+// Само по себе не делает запрос непустым
+func (qb *QueryBuilder) Increment(field string) *QueryBuilder {
+	qb.incrementParts = append(qb.incrementParts, fmt.Sprintf("%s = %s + 1", field, field))
+	return qb
+}
+
+// Where добавляет дополнительное условие равенства в WHERE, например проверку версии записи
+func (qb *QueryBuilder) Where(field string, value any) *QueryBuilder {
+	if qb.withNilCheck && isNil(value) {
+		return qb
+	}
+	qb.whereFields = append(qb.whereFields, field)
+	qb.whereValues = append(qb.whereValues, value)
+	return qb
+}
+
 func (qb *QueryBuilder) BuildUpdateQuery(table string, whereField string, whereValue interface{}) (string, []interface{}) {
 	if len(qb.setParts) == 0 {
 		return "", nil
 	}
 
-	setClause := strings.Join(qb.setParts, ", ") + ", updated_at = NOW()"
+	setParts := append(append([]string{}, qb.setParts...), qb.incrementParts...)
+	setClause := strings.Join(setParts, ", ") + ", updated_at = NOW()"
 
 	// первый параметр всегда where
 	allValues := append([]interface{}{whereValue}, qb.values...)
 
-	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s = $1", table, setClause, whereField)
+	whereParts := []string{fmt.Sprintf("%s = $1", whereField)}
+	paramNum := qb.paramNum
+	for i, field := range qb.whereFields {
+		paramNum++
+		whereParts = append(whereParts, fmt.Sprintf("%s = $%d", field, paramNum))
+		allValues = append(allValues, qb.whereValues[i])
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s", table, setClause, strings.Join(whereParts, " AND "))
 
 	return query, allValues
 }
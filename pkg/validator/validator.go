@@ -6,8 +6,32 @@ import (
 	"unicode/utf8"
 )
 
+// Коды ошибок валидации
+const (
+	CodeRequired        = "required"
+	CodeTooShort        = "too_short"
+	CodeTooLong         = "too_long"
+	CodeTooSmall        = "too_small"
+	CodeTooLarge        = "too_large"
+	CodeInvalidUuid     = "invalid_uuid"
+	CodeInvalidFormat   = "invalid_format"
+	CodeInvalidRange    = "invalid_range"
+	CodeUnsupportedType = "unsupported_type"
+)
+
+// FieldError — ошибка валидации конкретного поля
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	return e.Message
+}
+
 type Validator struct {
-	errors []string
+	errors []FieldError
 	count  int
 }
 
@@ -21,11 +45,12 @@ func (v *Validator) ValidatedFieldsCount() int {
 	return v.count
 }
 
-func (v *Validator) AddError(msg string) {
-	v.errors = append(v.errors, msg)
+// Добавляет ошибку для поля с машиночитаемым кодом
+func (v *Validator) AddError(field, code, msg string) {
+	v.errors = append(v.errors, FieldError{Field: field, Code: code, Message: msg})
 }
 
-func (v *Validator) GetErrors() []string {
+func (v *Validator) GetErrors() []FieldError {
 	return v.errors
 }
 
@@ -43,7 +68,7 @@ type NumberValidator struct {
 
 func New() *Validator {
 	return &Validator{
-		errors: make([]string, 0),
+		errors: make([]FieldError, 0),
 		count:  0,
 	}
 }
@@ -72,7 +97,7 @@ func (v *Validator) CheckNumber(value any, name string) *NumberValidator {
 func (v *StringValidator) IsMax(max int) *StringValidator {
 	length := utf8.RuneCountInString(v.value)
 	if length > max {
-		v.validator.AddError(fmt.Sprintf("[%s] - Max aviable length is %d, Provided: %d", v.name, max, length))
+		v.validator.AddError(v.name, CodeTooLong, fmt.Sprintf("[%s] - Max aviable length is %d, Provided: %d", v.name, max, length))
 	}
 	return v
 }
@@ -81,7 +106,7 @@ func (v *StringValidator) IsMax(max int) *StringValidator {
 func (v *StringValidator) IsUuid() *StringValidator {
 	_, err := uuid.Parse(v.value)
 	if err != nil {
-		v.validator.AddError(fmt.Sprintf("[%s] - Invalid uuid", v.name))
+		v.validator.AddError(v.name, CodeInvalidUuid, fmt.Sprintf("[%s] - Invalid uuid", v.name))
 	}
 	return v
 }
@@ -90,7 +115,7 @@ func (v *StringValidator) IsUuid() *StringValidator {
 func (v *StringValidator) IsMin(min int) *StringValidator {
 	length := utf8.RuneCountInString(v.value)
 	if length < min {
-		v.validator.AddError(fmt.Sprintf("[%s] - Min required length is %d, Provided: %d", v.name, min, length))
+		v.validator.AddError(v.name, CodeTooShort, fmt.Sprintf("[%s] - Min required length is %d, Provided: %d", v.name, min, length))
 	}
 	return v
 }
@@ -100,11 +125,11 @@ func (v *NumberValidator) IsMin(min float64) *NumberValidator {
 	value, ok := v.toInt64()
 
 	if !ok {
-		v.validator.AddError(fmt.Sprintf("[%s] - Unsupported type: %T", v.name, v.value))
+		v.validator.AddError(v.name, CodeUnsupportedType, fmt.Sprintf("[%s] - Unsupported type: %T", v.name, v.value))
 		return v
 	}
 	if value < min {
-		v.validator.AddError(fmt.Sprintf("[%s] - Min required: %g, Provided: %g", v.name, min, value))
+		v.validator.AddError(v.name, CodeTooSmall, fmt.Sprintf("[%s] - Min required: %g, Provided: %g", v.name, min, value))
 
 	}
 	return v
@@ -115,12 +140,12 @@ func (v *NumberValidator) IsMax(max float64) *NumberValidator {
 	value, ok := v.toInt64()
 
 	if !ok {
-		v.validator.AddError(fmt.Sprintf("[%s] - Unsupported type: %T", v.name, v.value))
+		v.validator.AddError(v.name, CodeUnsupportedType, fmt.Sprintf("[%s] - Unsupported type: %T", v.name, v.value))
 		return v
 	}
 
 	if value > max {
-		v.validator.AddError(fmt.Sprintf("[%s] - Max aviable: %g, Provided: %g", v.name, max, value))
+		v.validator.AddError(v.name, CodeTooLarge, fmt.Sprintf("[%s] - Max aviable: %g, Provided: %g", v.name, max, value))
 	}
 	return v
 }